@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+// API is the part of the CloudWatch Logs api used by the viewer
+type API interface {
+	cloudwatchlogs.DescribeLogGroupsAPIClient
+	cloudwatchlogs.DescribeLogStreamsAPIClient
+	cloudwatchlogs.GetLogEventsAPIClient
+	cloudwatchlogs.FilterLogEventsAPIClient
+	FilterAPI
+	ManageAPI
+}
+
+// FilterAPI is the part of the api used to browse and test the metric and
+// subscription filters of log groups
+type FilterAPI interface {
+	cloudwatchlogs.DescribeMetricFiltersAPIClient
+	cloudwatchlogs.DescribeSubscriptionFiltersAPIClient
+	TestMetricFilter(
+		ctx context.Context,
+		in *cloudwatchlogs.TestMetricFilterInput,
+		optFns ...func(*cloudwatchlogs.Options),
+	) (*cloudwatchlogs.TestMetricFilterOutput, error)
+}
+
+// ManageAPI is the part of the api used to manage log groups and streams,
+// every call but ListTagsForResource changes them
+type ManageAPI interface {
+	PutRetentionPolicy(
+		ctx context.Context,
+		in *cloudwatchlogs.PutRetentionPolicyInput,
+		optFns ...func(*cloudwatchlogs.Options),
+	) (*cloudwatchlogs.PutRetentionPolicyOutput, error)
+	DeleteRetentionPolicy(
+		ctx context.Context,
+		in *cloudwatchlogs.DeleteRetentionPolicyInput,
+		optFns ...func(*cloudwatchlogs.Options),
+	) (*cloudwatchlogs.DeleteRetentionPolicyOutput, error)
+	ListTagsForResource(
+		ctx context.Context,
+		in *cloudwatchlogs.ListTagsForResourceInput,
+		optFns ...func(*cloudwatchlogs.Options),
+	) (*cloudwatchlogs.ListTagsForResourceOutput, error)
+	TagResource(
+		ctx context.Context,
+		in *cloudwatchlogs.TagResourceInput,
+		optFns ...func(*cloudwatchlogs.Options),
+	) (*cloudwatchlogs.TagResourceOutput, error)
+	UntagResource(
+		ctx context.Context,
+		in *cloudwatchlogs.UntagResourceInput,
+		optFns ...func(*cloudwatchlogs.Options),
+	) (*cloudwatchlogs.UntagResourceOutput, error)
+	DeleteLogGroup(
+		ctx context.Context,
+		in *cloudwatchlogs.DeleteLogGroupInput,
+		optFns ...func(*cloudwatchlogs.Options),
+	) (*cloudwatchlogs.DeleteLogGroupOutput, error)
+	DeleteLogStream(
+		ctx context.Context,
+		in *cloudwatchlogs.DeleteLogStreamInput,
+		optFns ...func(*cloudwatchlogs.Options),
+	) (*cloudwatchlogs.DeleteLogStreamOutput, error)
+}
+
+// Client is a CloudWatch Logs client along with the profile and region it
+// was created for
+type Client struct {
+	API
+	Profile string
+	Region  string
+
+	recorder  *Recorder // records the calls of clients switched to
+	limiter   *Limiter  // limits the calls of clients switched to
+	replaying bool
+	readOnly  bool
+}
+
+// New creates a client from the shared aws config, an empty profile or
+// region uses the default from the environment and config files. The client
+// doesn't retry failed calls itself, that's left to Limit so retries can be
+// shown.
+func New(ctx context.Context, profile, region string) (Client, error) {
+	opts := []func(*config.LoadOptions) error{
+		config.WithRetryer(func() aws.Retryer { return aws.NopRetryer{} }),
+	}
+	if profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(profile))
+	}
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return Client{}, err
+	}
+
+	return Client{
+		API:     cloudwatchlogs.NewFromConfig(cfg),
+		Profile: profile,
+		Region:  cfg.Region,
+	}, nil
+}
+
+// Switch creates a client for another profile or region, recording and
+// limiting its calls and refusing changes if c does. A client replaying a
+// recording can't switch as nothing was recorded for other profiles or
+// regions.
+func (c Client) Switch(ctx context.Context, profile, region string) (Client, error) {
+	if c.replaying {
+		return Client{}, errors.New("can't switch profile or region while replaying a recording")
+	}
+
+	cw, err := New(ctx, profile, region)
+	if err != nil {
+		return cw, err
+	}
+	if c.recorder != nil {
+		cw = cw.Record(c.recorder)
+	}
+	if c.limiter != nil {
+		cw = cw.Limit(c.limiter)
+	}
+	if c.readOnly {
+		cw = cw.ReadOnly()
+	}
+	return cw, nil
+}
@@ -0,0 +1,510 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+// Operations are the names of the calls made by the viewer
+var Operations = []string{
+	"DescribeLogGroups",
+	"DescribeLogStreams",
+	"GetLogEvents",
+	"FilterLogEvents",
+	"DescribeMetricFilters",
+	"DescribeSubscriptionFilters",
+	"TestMetricFilter",
+	"PutRetentionPolicy",
+	"DeleteRetentionPolicy",
+	"ListTagsForResource",
+	"TagResource",
+	"UntagResource",
+	"DeleteLogGroup",
+	"DeleteLogStream",
+}
+
+// mutations are the operations that change a log group. A mutation that
+// failed with a transient error may still have been applied, so they're only
+// retried when throttled, which CloudWatch rejects before applying.
+var mutations = map[string]bool{
+	"PutRetentionPolicy":    true,
+	"DeleteRetentionPolicy": true,
+	"TagResource":           true,
+	"UntagResource":         true,
+	"DeleteLogGroup":        true,
+	"DeleteLogStream":       true,
+}
+
+// Rates is the number of requests per second allowed for each operation. It's
+// a flag.Value set from a comma separated list of operation=rate pairs.
+type Rates map[string]float64
+
+// DefaultRates are the default CloudWatch Logs quotas per account and region.
+// The calls that manage log groups or their filters are made one at a time so
+// have no rate.
+func DefaultRates() Rates {
+	return Rates{
+		"DescribeLogGroups":  10,
+		"DescribeLogStreams": 25,
+		"GetLogEvents":       25,
+		"FilterLogEvents":    5,
+	}
+}
+
+func (r Rates) String() string {
+	var pairs []string
+	for _, op := range Operations {
+		if rate, ok := r[op]; ok {
+			pairs = append(pairs, op+"="+strconv.FormatFloat(rate, 'g', -1, 64))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set changes the rates of the operations in s, e.g. GetLogEvents=50
+func (r Rates) Set(s string) error {
+	for _, pair := range strings.Split(s, ",") {
+		op, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return fmt.Errorf("rate %q isn't operation=rate", pair)
+		}
+		if !isOperation(op) {
+			return fmt.Errorf("unknown operation %q, expected one of %s", op, strings.Join(Operations, ", "))
+		}
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate <= 0 {
+			return fmt.Errorf("rate of %s must be a positive number of requests per second", op)
+		}
+		r[op] = rate
+	}
+	return nil
+}
+
+func isOperation(op string) bool {
+	for _, o := range Operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Limits configures how fast calls are made and how they're retried
+type Limits struct {
+	Rates       Rates
+	MaxAttempts int           // attempts made at each call, including the first
+	BaseDelay   time.Duration // the delay before the first retry, doubled for each one after
+	MaxDelay    time.Duration
+}
+
+// DefaultLimits are the limits for an account with the default quotas
+func DefaultLimits() Limits {
+	return Limits{
+		Rates:       DefaultRates(),
+		MaxAttempts: 5,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    20 * time.Second,
+	}
+}
+
+// Retry is a call that failed and is waiting to be tried again
+type Retry struct {
+	Operation string
+	Attempt   int // the attempt that failed, from 1
+	Err       error
+	At        time.Time // when the next attempt is made
+}
+
+var throttles = retry.IsErrorThrottles(retry.DefaultThrottles)
+
+// Throttled returns true if the call failed because it was throttled
+func (r Retry) Throttled() bool {
+	return throttles.IsErrorThrottle(r.Err) == aws.TrueTernary
+}
+
+// Limiter spaces out the calls of clients so they stay within the rate of
+// each operation, and retries the calls that are throttled or, unless they're
+// mutations, fail with transient errors after a jittered backoff
+type Limiter struct {
+	limits Limits
+
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	retrying map[int]Retry // by call
+	nextCall int
+	changed  chan struct{}
+}
+
+// NewLimiter limits calls to limits, operations without a rate aren't spaced
+// out but are still retried
+func NewLimiter(limits Limits) *Limiter {
+	l := &Limiter{
+		limits:   limits,
+		buckets:  map[string]*bucket{},
+		retrying: map[int]Retry{},
+		changed:  make(chan struct{}, 1),
+	}
+	for op, rate := range limits.Rates {
+		l.buckets[op] = newBucket(rate)
+	}
+	return l
+}
+
+// Limit returns c with its calls limited by l
+func (c Client) Limit(l *Limiter) Client {
+	c.API = limited{api: c.API, limiter: l}
+	c.limiter = l
+	return c
+}
+
+// Limiter returns the limiter of c, nil if its calls aren't limited
+func (c Client) Limiter() *Limiter {
+	return c.limiter
+}
+
+// Changed receives when a call starts or stops waiting to be retried
+func (l *Limiter) Changed() <-chan struct{} {
+	return l.changed
+}
+
+// Retrying returns the calls waiting to be retried, oldest first
+func (l *Limiter) Retrying() []Retry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	calls := make([]int, 0, len(l.retrying))
+	for call := range l.retrying {
+		calls = append(calls, call)
+	}
+	sort.Ints(calls)
+
+	retries := make([]Retry, len(calls))
+	for i, call := range calls {
+		retries[i] = l.retrying[call]
+	}
+	return retries
+}
+
+// retryables are the errors the sdk retries, along with CloudWatch Logs' own
+// code for being unavailable
+var retryables = retry.IsErrorRetryables(append(
+	[]retry.IsErrorRetryable{retry.RetryableErrorCode{
+		Codes: map[string]struct{}{"ServiceUnavailableException": {}},
+	}},
+	retry.DefaultRetryables...,
+))
+
+// do calls fn until it succeeds, fails with an error that isn't worth
+// retrying, or runs out of attempts
+func (l *Limiter) do(ctx context.Context, operation string, fn func() error) error {
+	l.mu.Lock()
+	call := l.nextCall
+	l.nextCall++
+	l.mu.Unlock()
+	defer l.setRetry(call, nil)
+
+	for attempt := 1; ; attempt++ {
+		if b := l.buckets[operation]; b != nil {
+			if err := b.wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		err := fn()
+		if err == nil || attempt >= l.limits.MaxAttempts || ctx.Err() != nil || !retryable(operation, err) {
+			return err
+		}
+
+		delay := l.backoff(attempt)
+		l.setRetry(call, &Retry{
+			Operation: operation,
+			Attempt:   attempt,
+			Err:       err,
+			At:        time.Now().Add(delay),
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable returns true if operation is worth retrying after failing with err
+func retryable(operation string, err error) bool {
+	if mutations[operation] {
+		return throttles.IsErrorThrottle(err) == aws.TrueTernary
+	}
+	return retryables.IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// backoff returns the base delay doubled for each attempt that has failed,
+// half of which is random so clients throttled together don't all retry
+// together
+func (l *Limiter) backoff(attempt int) time.Duration {
+	delay := l.limits.BaseDelay << (attempt - 1)
+	if delay > l.limits.MaxDelay || delay <= 0 {
+		delay = l.limits.MaxDelay
+	}
+	if delay <= 1 {
+		return delay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func (l *Limiter) setRetry(call int, r *Retry) {
+	l.mu.Lock()
+	_, wasRetrying := l.retrying[call]
+	if r != nil {
+		l.retrying[call] = *r
+	} else {
+		delete(l.retrying, call)
+	}
+	l.mu.Unlock()
+
+	if r == nil && !wasRetrying {
+		return
+	}
+	// whoever is watching reads every retry when it gets round to it
+	select {
+	case l.changed <- struct{}{}:
+	default:
+	}
+}
+
+// bucket is a token bucket refilled at rate tokens a second, holding at most
+// a second's worth of tokens
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate float64) *bucket {
+	return &bucket{rate: rate, tokens: burst(rate), last: time.Now()}
+}
+
+func burst(rate float64) float64 {
+	if rate < 1 {
+		return 1
+	}
+	return rate
+}
+
+// wait takes a token, waiting for it if the bucket is empty
+func (b *bucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if max := burst(b.rate); b.tokens > max {
+		b.tokens = max
+	}
+	b.last = now
+	// taking a token the bucket doesn't have yet reserves the next one
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// limited is an API with its calls limited
+type limited struct {
+	api     API
+	limiter *Limiter
+}
+
+func (l limited) DescribeLogGroups(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeLogGroupsInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.DescribeLogGroupsOutput, err error) {
+	err = l.limiter.do(ctx, "DescribeLogGroups", func() error {
+		out, err = l.api.DescribeLogGroups(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) DescribeLogStreams(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeLogStreamsInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.DescribeLogStreamsOutput, err error) {
+	err = l.limiter.do(ctx, "DescribeLogStreams", func() error {
+		out, err = l.api.DescribeLogStreams(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) GetLogEvents(
+	ctx context.Context,
+	in *cloudwatchlogs.GetLogEventsInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.GetLogEventsOutput, err error) {
+	err = l.limiter.do(ctx, "GetLogEvents", func() error {
+		out, err = l.api.GetLogEvents(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) FilterLogEvents(
+	ctx context.Context,
+	in *cloudwatchlogs.FilterLogEventsInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.FilterLogEventsOutput, err error) {
+	err = l.limiter.do(ctx, "FilterLogEvents", func() error {
+		out, err = l.api.FilterLogEvents(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) DescribeMetricFilters(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeMetricFiltersInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.DescribeMetricFiltersOutput, err error) {
+	err = l.limiter.do(ctx, "DescribeMetricFilters", func() error {
+		out, err = l.api.DescribeMetricFilters(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) DescribeSubscriptionFilters(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeSubscriptionFiltersInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.DescribeSubscriptionFiltersOutput, err error) {
+	err = l.limiter.do(ctx, "DescribeSubscriptionFilters", func() error {
+		out, err = l.api.DescribeSubscriptionFilters(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) TestMetricFilter(
+	ctx context.Context,
+	in *cloudwatchlogs.TestMetricFilterInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.TestMetricFilterOutput, err error) {
+	err = l.limiter.do(ctx, "TestMetricFilter", func() error {
+		out, err = l.api.TestMetricFilter(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) PutRetentionPolicy(
+	ctx context.Context,
+	in *cloudwatchlogs.PutRetentionPolicyInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.PutRetentionPolicyOutput, err error) {
+	err = l.limiter.do(ctx, "PutRetentionPolicy", func() error {
+		out, err = l.api.PutRetentionPolicy(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) DeleteRetentionPolicy(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteRetentionPolicyInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.DeleteRetentionPolicyOutput, err error) {
+	err = l.limiter.do(ctx, "DeleteRetentionPolicy", func() error {
+		out, err = l.api.DeleteRetentionPolicy(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) ListTagsForResource(
+	ctx context.Context,
+	in *cloudwatchlogs.ListTagsForResourceInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.ListTagsForResourceOutput, err error) {
+	err = l.limiter.do(ctx, "ListTagsForResource", func() error {
+		out, err = l.api.ListTagsForResource(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) TagResource(
+	ctx context.Context,
+	in *cloudwatchlogs.TagResourceInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.TagResourceOutput, err error) {
+	err = l.limiter.do(ctx, "TagResource", func() error {
+		out, err = l.api.TagResource(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) UntagResource(
+	ctx context.Context,
+	in *cloudwatchlogs.UntagResourceInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.UntagResourceOutput, err error) {
+	err = l.limiter.do(ctx, "UntagResource", func() error {
+		out, err = l.api.UntagResource(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) DeleteLogGroup(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteLogGroupInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.DeleteLogGroupOutput, err error) {
+	err = l.limiter.do(ctx, "DeleteLogGroup", func() error {
+		out, err = l.api.DeleteLogGroup(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
+
+func (l limited) DeleteLogStream(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteLogStreamInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (out *cloudwatchlogs.DeleteLogStreamOutput, err error) {
+	err = l.limiter.do(ctx, "DeleteLogStream", func() error {
+		out, err = l.api.DeleteLogStream(ctx, in, optFns...)
+		return err
+	})
+	return out, err
+}
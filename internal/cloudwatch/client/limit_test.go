@@ -0,0 +1,197 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/smithy-go"
+
+	"clviewer/internal/cloudwatch/client"
+	"clviewer/internal/cloudwatch/fake"
+)
+
+func limited(b *fake.Backend, limits client.Limits) client.Client {
+	return client.Client{API: b}.Limit(client.NewLimiter(limits))
+}
+
+func TestRetries(t *testing.T) {
+	notFound := &smithy.GenericAPIError{Code: "ResourceNotFoundException", Message: "no such group"}
+	unavailable := &smithy.GenericAPIError{Code: "ServiceUnavailableException"}
+
+	tests := []struct {
+		name    string
+		errs    []error
+		calls   int
+		wantErr string // error code, empty for none
+	}{
+		{"success", nil, 1, ""},
+		{"throttled", []error{fake.ThrottlingError(), fake.ThrottlingError()}, 3, ""},
+		{"gives up", []error{fake.ThrottlingError(), fake.ThrottlingError(), fake.ThrottlingError()}, 3, "ThrottlingException"},
+		{"not retryable", []error{notFound}, 1, "ResourceNotFoundException"},
+		{"transient", []error{unavailable}, 2, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := backend()
+			b.Fail(fake.DescribeLogGroups, tt.errs...)
+			cw := limited(b, client.Limits{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+
+			_, err := cw.API.DescribeLogGroups(context.Background(), &cloudwatchlogs.DescribeLogGroupsInput{})
+			var apiErr smithy.APIError
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case tt.wantErr != "" && (!errors.As(err, &apiErr) || apiErr.ErrorCode() != tt.wantErr):
+				t.Errorf("got error %v, want %s", err, tt.wantErr)
+			}
+			if got := b.Calls(fake.DescribeLogGroups); got != tt.calls {
+				t.Errorf("called %d times, want %d", got, tt.calls)
+			}
+			if retrying := cw.Limiter().Retrying(); len(retrying) != 0 {
+				t.Errorf("still retrying %v", retrying)
+			}
+		})
+	}
+}
+
+func TestRetryMutations(t *testing.T) {
+	unavailable := &smithy.GenericAPIError{Code: "ServiceUnavailableException"}
+
+	tests := []struct {
+		name    string
+		errs    []error
+		calls   int
+		wantErr bool
+	}{
+		{"throttled", []error{fake.ThrottlingError()}, 2, false},
+		{"transient", []error{unavailable}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := backend()
+			b.Fail(fake.PutRetentionPolicy, tt.errs...)
+			cw := limited(b, client.Limits{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+
+			_, err := cw.API.PutRetentionPolicy(context.Background(), &cloudwatchlogs.PutRetentionPolicyInput{
+				LogGroupName:    aws.String("g"),
+				RetentionInDays: aws.Int32(7),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got := b.Calls(fake.PutRetentionPolicy); got != tt.calls {
+				t.Errorf("called %d times, want %d", got, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRetrying(t *testing.T) {
+	b := backend()
+	b.Throttle(fake.GetLogEvents, 1)
+	cw := limited(b, client.Limits{MaxAttempts: 2, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+	l := cw.Limiter()
+
+	done := make(chan error)
+	go func() {
+		_, err := cw.API.GetLogEvents(context.Background(), &cloudwatchlogs.GetLogEventsInput{
+			LogGroupName:  aws.String("g"),
+			LogStreamName: aws.String("s"),
+		})
+		done <- err
+	}()
+
+	<-l.Changed()
+	retrying := l.Retrying()
+	if len(retrying) != 1 || retrying[0].Operation != "GetLogEvents" || retrying[0].Attempt != 1 || !retrying[0].Throttled() {
+		t.Fatalf("retrying %+v, want the first attempt at GetLogEvents throttled", retrying)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	<-l.Changed()
+	if retrying := l.Retrying(); len(retrying) != 0 {
+		t.Errorf("still retrying %+v", retrying)
+	}
+}
+
+func TestRetryCancelled(t *testing.T) {
+	b := backend()
+	b.Throttle(fake.DescribeLogGroups, 1)
+	cw := limited(b, client.Limits{MaxAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-cw.Limiter().Changed()
+		cancel()
+	}()
+
+	_, err := cw.API.DescribeLogGroups(ctx, &cloudwatchlogs.DescribeLogGroupsInput{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want the call cancelled while it waited to retry", err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	b := backend()
+	cw := limited(b, client.Limits{Rates: client.Rates{"DescribeLogGroups": 50}, MaxAttempts: 1})
+
+	// a second's worth of calls are made at once, the rest are spaced out
+	start := time.Now()
+	for i := 0; i < 60; i++ {
+		if _, err := cw.API.DescribeLogGroups(context.Background(), &cloudwatchlogs.DescribeLogGroupsInput{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("60 calls took %s at 50 a second, want at least 200ms", elapsed)
+	}
+
+	// other operations aren't limited
+	start = time.Now()
+	for i := 0; i < 60; i++ {
+		if _, err := cw.API.DescribeLogStreams(context.Background(), &cloudwatchlogs.DescribeLogStreamsInput{
+			LogGroupName: aws.String("g"),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("60 unlimited calls took %s", elapsed)
+	}
+}
+
+func TestRatesSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"GetLogEvents=50", "DescribeLogGroups=10,DescribeLogStreams=25,GetLogEvents=50,FilterLogEvents=5", false},
+		{"FilterLogEvents=0.5, DescribeLogGroups=20", "DescribeLogGroups=20,DescribeLogStreams=25,GetLogEvents=25,FilterLogEvents=0.5", false},
+		{"GetLogEvents", "", true},
+		{"PutLogEvents=5", "", true},
+		{"GetLogEvents=0", "", true},
+		{"GetLogEvents=fast", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			rates := client.DefaultRates()
+			err := rates.Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set(%q) error %v, want error %t", tt.value, err, tt.wantErr)
+			}
+			if err == nil && rates.String() != tt.want {
+				t.Errorf("Set(%q) = %s, want %s", tt.value, rates, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+// ErrReadOnly is returned by the calls of a read-only client that would
+// change a log group or stream
+var ErrReadOnly = errors.New("read-only")
+
+// ReadOnly returns c with the calls that change or delete log groups and
+// streams refused, so a production account can be browsed safely
+func (c Client) ReadOnly() Client {
+	c.API = readOnly{API: c.API}
+	c.readOnly = true
+	return c
+}
+
+// IsReadOnly returns true if api refuses the calls that change log groups
+// and streams
+func IsReadOnly(api API) bool {
+	switch api := api.(type) {
+	case Client:
+		return api.readOnly
+	case readOnly:
+		return true
+	}
+	return false
+}
+
+// readOnly is an API that refuses every call that makes a change, the rest
+// are passed through
+type readOnly struct {
+	API
+}
+
+func refuse(operation string) error {
+	return fmt.Errorf("%s: %w", operation, ErrReadOnly)
+}
+
+func (readOnly) PutRetentionPolicy(
+	context.Context,
+	*cloudwatchlogs.PutRetentionPolicyInput,
+	...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.PutRetentionPolicyOutput, error) {
+	return nil, refuse("PutRetentionPolicy")
+}
+
+func (readOnly) DeleteRetentionPolicy(
+	context.Context,
+	*cloudwatchlogs.DeleteRetentionPolicyInput,
+	...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteRetentionPolicyOutput, error) {
+	return nil, refuse("DeleteRetentionPolicy")
+}
+
+func (readOnly) TagResource(
+	context.Context,
+	*cloudwatchlogs.TagResourceInput,
+	...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.TagResourceOutput, error) {
+	return nil, refuse("TagResource")
+}
+
+func (readOnly) UntagResource(
+	context.Context,
+	*cloudwatchlogs.UntagResourceInput,
+	...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.UntagResourceOutput, error) {
+	return nil, refuse("UntagResource")
+}
+
+func (readOnly) DeleteLogGroup(
+	context.Context,
+	*cloudwatchlogs.DeleteLogGroupInput,
+	...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteLogGroupOutput, error) {
+	return nil, refuse("DeleteLogGroup")
+}
+
+func (readOnly) DeleteLogStream(
+	context.Context,
+	*cloudwatchlogs.DeleteLogStreamInput,
+	...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteLogStreamOutput, error) {
+	return nil, refuse("DeleteLogStream")
+}
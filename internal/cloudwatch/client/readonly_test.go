@@ -0,0 +1,52 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+
+	"clviewer/internal/cloudwatch/client"
+	"clviewer/internal/cloudwatch/fake"
+)
+
+func TestReadOnly(t *testing.T) {
+	ctx := context.Background()
+	b := backend()
+	cw := client.Client{API: b}.ReadOnly()
+	if !client.IsReadOnly(cw) || client.IsReadOnly(client.Client{API: b}) {
+		t.Fatal("IsReadOnly doesn't report whether the client is read-only")
+	}
+
+	_, err := cw.DeleteLogGroup(ctx, &cloudwatchlogs.DeleteLogGroupInput{LogGroupName: aws.String("g")})
+	if !errors.Is(err, client.ErrReadOnly) {
+		t.Fatalf("got %v deleting a group, want it refused", err)
+	}
+	_, err = cw.PutRetentionPolicy(ctx, &cloudwatchlogs.PutRetentionPolicyInput{
+		LogGroupName:    aws.String("g"),
+		RetentionInDays: aws.Int32(30),
+	})
+	if !errors.Is(err, client.ErrReadOnly) {
+		t.Fatalf("got %v setting retention, want it refused", err)
+	}
+	if b.Calls(fake.DeleteLogGroup) != 0 || b.Calls(fake.PutRetentionPolicy) != 0 {
+		t.Error("refused calls reached the backend")
+	}
+
+	// reading is still allowed
+	if got := events(t, cw, &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String("g"),
+		LogStreamName: aws.String("s"),
+		StartFromHead: aws.Bool(true),
+	}); len(got) != 2 {
+		t.Errorf("got events %v, want both", got)
+	}
+	_, err = cw.ListTagsForResource(ctx, &cloudwatchlogs.ListTagsForResourceInput{
+		ResourceArn: aws.String(fake.ARN("g")),
+	})
+	if err != nil {
+		t.Errorf("got %v listing tags", err)
+	}
+}
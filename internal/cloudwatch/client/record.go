@@ -0,0 +1,272 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/smithy-go"
+)
+
+// entry is a request and its response, recordings are a file of entries one
+// json object per line
+type entry struct {
+	Operation string          `json:"operation"`
+	Input     json.RawMessage `json:"input"`
+	Output    json.RawMessage `json:"output,omitempty"`
+	Error     *recordedError  `json:"error,omitempty"`
+}
+
+type recordedError struct {
+	Code    string `json:"code,omitempty"` // aws error code, e.g. ThrottlingException
+	Message string `json:"message"`
+}
+
+// Recorder writes the requests and responses of clients to a recording that
+// can be replayed with NewReplay
+type Recorder struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	redact bool
+}
+
+// NewRecorder records to w, when redact is set the contents of log messages
+// are masked before they're written
+func NewRecorder(w io.Writer, redact bool) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w), redact: redact}
+}
+
+// Record returns c with its calls recorded by r
+func (c Client) Record(r *Recorder) Client {
+	c.API = recording{api: c.API, recorder: r}
+	c.recorder = r
+	return c
+}
+
+func (r *Recorder) write(operation string, in, out interface{}, err error) {
+	e := entry{Operation: operation}
+
+	var marshalErr error
+	if e.Input, marshalErr = json.Marshal(in); marshalErr == nil && err == nil {
+		e.Output, marshalErr = json.Marshal(out)
+	}
+	if marshalErr != nil {
+		err = fmt.Errorf("recording %s: %w", operation, marshalErr)
+	}
+	if err != nil {
+		e.Error = &recordedError{Message: err.Error()}
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			e.Error.Code = apiErr.ErrorCode()
+			e.Error.Message = apiErr.ErrorMessage()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// a recording that can't be written shouldn't stop the viewer
+	_ = r.enc.Encode(e)
+}
+
+// recording is an API that records every call made through it
+type recording struct {
+	api      API
+	recorder *Recorder
+}
+
+func (r recording) DescribeLogGroups(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeLogGroupsInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	out, err := r.api.DescribeLogGroups(ctx, in, optFns...)
+	r.recorder.write("DescribeLogGroups", in, out, err)
+	return out, err
+}
+
+func (r recording) DescribeLogStreams(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeLogStreamsInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeLogStreamsOutput, error) {
+	out, err := r.api.DescribeLogStreams(ctx, in, optFns...)
+	r.recorder.write("DescribeLogStreams", in, out, err)
+	return out, err
+}
+
+func (r recording) GetLogEvents(
+	ctx context.Context,
+	in *cloudwatchlogs.GetLogEventsInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	out, err := r.api.GetLogEvents(ctx, in, optFns...)
+
+	recorded := out
+	if r.recorder.redact && out != nil {
+		// the viewer is given the messages as they are
+		redacted := *out
+		redacted.Events = make([]types.OutputLogEvent, len(out.Events))
+		for i, e := range out.Events {
+			e.Message = redactMessage(e.Message)
+			redacted.Events[i] = e
+		}
+		recorded = &redacted
+	}
+	r.recorder.write("GetLogEvents", in, recorded, err)
+	return out, err
+}
+
+func (r recording) FilterLogEvents(
+	ctx context.Context,
+	in *cloudwatchlogs.FilterLogEventsInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	out, err := r.api.FilterLogEvents(ctx, in, optFns...)
+
+	recorded := out
+	if r.recorder.redact && out != nil {
+		redacted := *out
+		redacted.Events = make([]types.FilteredLogEvent, len(out.Events))
+		for i, e := range out.Events {
+			e.Message = redactMessage(e.Message)
+			redacted.Events[i] = e
+		}
+		recorded = &redacted
+	}
+	r.recorder.write("FilterLogEvents", in, recorded, err)
+	return out, err
+}
+
+func (r recording) DescribeMetricFilters(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeMetricFiltersInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeMetricFiltersOutput, error) {
+	out, err := r.api.DescribeMetricFilters(ctx, in, optFns...)
+	r.recorder.write("DescribeMetricFilters", in, out, err)
+	return out, err
+}
+
+func (r recording) DescribeSubscriptionFilters(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeSubscriptionFiltersInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeSubscriptionFiltersOutput, error) {
+	out, err := r.api.DescribeSubscriptionFilters(ctx, in, optFns...)
+	r.recorder.write("DescribeSubscriptionFilters", in, out, err)
+	return out, err
+}
+
+func (r recording) TestMetricFilter(
+	ctx context.Context,
+	in *cloudwatchlogs.TestMetricFilterInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.TestMetricFilterOutput, error) {
+	out, err := r.api.TestMetricFilter(ctx, in, optFns...)
+
+	recordedIn, recordedOut := in, out
+	if r.recorder.redact {
+		// the messages tested are those the viewer was given, which are
+		// redacted the same way when replayed so the requests still match
+		redactedIn := *in
+		redactedIn.LogEventMessages = make([]string, len(in.LogEventMessages))
+		for i, message := range in.LogEventMessages {
+			redactedIn.LogEventMessages[i] = Redact(message)
+		}
+		recordedIn = &redactedIn
+	}
+	if r.recorder.redact && out != nil {
+		redactedOut := *out
+		redactedOut.Matches = make([]types.MetricFilterMatchRecord, len(out.Matches))
+		for i, match := range out.Matches {
+			match.EventMessage = redactMessage(match.EventMessage)
+			// values are extracted from the message so are masked with it
+			if match.ExtractedValues != nil {
+				values := make(map[string]string, len(match.ExtractedValues))
+				for k, v := range match.ExtractedValues {
+					values[k] = Redact(v)
+				}
+				match.ExtractedValues = values
+			}
+			redactedOut.Matches[i] = match
+		}
+		recordedOut = &redactedOut
+	}
+	r.recorder.write("TestMetricFilter", recordedIn, recordedOut, err)
+	return out, err
+}
+
+func (r recording) PutRetentionPolicy(
+	ctx context.Context,
+	in *cloudwatchlogs.PutRetentionPolicyInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.PutRetentionPolicyOutput, error) {
+	out, err := r.api.PutRetentionPolicy(ctx, in, optFns...)
+	r.recorder.write("PutRetentionPolicy", in, out, err)
+	return out, err
+}
+
+func (r recording) DeleteRetentionPolicy(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteRetentionPolicyInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteRetentionPolicyOutput, error) {
+	out, err := r.api.DeleteRetentionPolicy(ctx, in, optFns...)
+	r.recorder.write("DeleteRetentionPolicy", in, out, err)
+	return out, err
+}
+
+func (r recording) ListTagsForResource(
+	ctx context.Context,
+	in *cloudwatchlogs.ListTagsForResourceInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.ListTagsForResourceOutput, error) {
+	out, err := r.api.ListTagsForResource(ctx, in, optFns...)
+	r.recorder.write("ListTagsForResource", in, out, err)
+	return out, err
+}
+
+func (r recording) TagResource(
+	ctx context.Context,
+	in *cloudwatchlogs.TagResourceInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.TagResourceOutput, error) {
+	out, err := r.api.TagResource(ctx, in, optFns...)
+	r.recorder.write("TagResource", in, out, err)
+	return out, err
+}
+
+func (r recording) UntagResource(
+	ctx context.Context,
+	in *cloudwatchlogs.UntagResourceInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.UntagResourceOutput, error) {
+	out, err := r.api.UntagResource(ctx, in, optFns...)
+	r.recorder.write("UntagResource", in, out, err)
+	return out, err
+}
+
+func (r recording) DeleteLogGroup(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteLogGroupInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteLogGroupOutput, error) {
+	out, err := r.api.DeleteLogGroup(ctx, in, optFns...)
+	r.recorder.write("DeleteLogGroup", in, out, err)
+	return out, err
+}
+
+func (r recording) DeleteLogStream(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteLogStreamInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteLogStreamOutput, error) {
+	out, err := r.api.DeleteLogStream(ctx, in, optFns...)
+	r.recorder.write("DeleteLogStream", in, out, err)
+	return out, err
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Redact masks the contents of a log message while keeping its shape, so a
+// redacted recording still reproduces how messages are laid out. Letters
+// become x or X and digits become 1, everything else such as punctuation,
+// spaces and line breaks is kept. The keys of json messages are kept too, so
+// they format the same.
+func Redact(message string) string {
+	if strings.HasPrefix(message, "{") && json.Valid([]byte(message)) {
+		return redactJSON(message)
+	}
+	return strings.Map(mask, message)
+}
+
+func redactMessage(message *string) *string {
+	if message == nil {
+		return nil
+	}
+	return aws.String(Redact(*message))
+}
+
+func mask(r rune) rune {
+	switch {
+	case unicode.IsUpper(r):
+		return 'X'
+	case unicode.IsLetter(r):
+		return 'x'
+	case unicode.IsDigit(r):
+		return '1'
+	default:
+		return r
+	}
+}
+
+// redactJSON masks the strings and numbers of a valid json document other
+// than the keys of objects. Escape sequences are kept so it stays valid.
+func redactJSON(doc string) string {
+	var b strings.Builder
+	b.Grow(len(doc))
+
+	runes := []rune(doc)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r != '"' {
+			// true, false and null are kept, they're not letters in a string
+			if unicode.IsDigit(r) {
+				r = '1'
+			}
+			b.WriteRune(r)
+			continue
+		}
+
+		end := stringEnd(runes, i)
+		key := isKey(runes, end+1)
+		b.WriteRune('"')
+		for j := i + 1; j < end; j++ {
+			switch {
+			case key:
+				b.WriteRune(runes[j])
+			case runes[j] == '\\' && j+1 < end && runes[j+1] == 'u':
+				// keep \uXXXX whole
+				last := j + 5
+				if last >= end {
+					last = end - 1
+				}
+				b.WriteString(string(runes[j : last+1]))
+				j = last
+			case runes[j] == '\\' && j+1 < end:
+				b.WriteRune(runes[j])
+				b.WriteRune(runes[j+1])
+				j++
+			default:
+				b.WriteRune(mask(runes[j]))
+			}
+		}
+		b.WriteRune('"')
+		i = end
+	}
+	return b.String()
+}
+
+// stringEnd returns the index of the quote closing the string that starts
+// at start
+func stringEnd(runes []rune, start int) int {
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(runes) - 1
+}
+
+// isKey returns true if the string ending before i is the key of an object
+func isKey(runes []rune, i int) bool {
+	for ; i < len(runes); i++ {
+		if !unicode.IsSpace(runes[i]) {
+			return runes[i] == ':'
+		}
+	}
+	return false
+}
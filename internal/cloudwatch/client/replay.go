@@ -0,0 +1,274 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/smithy-go"
+)
+
+// ReplayRegion is the region of clients replaying a recording
+const ReplayRegion = "replay"
+
+// NewReplay creates a client that serves the responses of a recording made
+// with a Recorder rather than calling aws
+func NewReplay(r io.Reader) (Client, error) {
+	var entries []entry
+	scanner := bufio.NewScanner(r)
+	// pages of events can be large
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return Client{}, fmt.Errorf("reading recording line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return Client{}, fmt.Errorf("reading recording: %w", err)
+	}
+
+	return Client{
+		API: &replay{
+			entries: entries,
+			served:  make([]bool, len(entries)),
+			last:    map[string]int{},
+		},
+		Region:    ReplayRegion,
+		replaying: true,
+	}, nil
+}
+
+// replay serves recorded responses. A request gets the next response recorded
+// for the same input, then the last one again once they've all been served,
+// so polling the end of a stream keeps getting the same empty page. Requests
+// that were never recorded fail rather than getting another request's
+// response.
+type replay struct {
+	mu      sync.Mutex
+	entries []entry
+	served  []bool
+	last    map[string]int // entry last served for each request
+}
+
+func (r *replay) serve(ctx context.Context, operation string, in, out interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	input, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request := operation + " " + string(input)
+	i, ok := r.next(func(e entry) bool {
+		return e.Operation == operation && string(e.Input) == string(input)
+	})
+	if !ok {
+		i, ok = r.last[request]
+	}
+	if !ok {
+		return fmt.Errorf("replay: no recorded %s response for %s", operation, input)
+	}
+	r.served[i] = true
+	r.last[request] = i
+
+	e := r.entries[i]
+	if e.Error != nil {
+		if e.Error.Code != "" {
+			return &smithy.GenericAPIError{Code: e.Error.Code, Message: e.Error.Message}
+		}
+		return errors.New(e.Error.Message)
+	}
+	return json.Unmarshal(e.Output, out)
+}
+
+// next returns the first entry matching that hasn't been served
+func (r *replay) next(matches func(entry) bool) (int, bool) {
+	for i, e := range r.entries {
+		if !r.served[i] && matches(e) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (r *replay) DescribeLogGroups(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeLogGroupsInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	var out cloudwatchlogs.DescribeLogGroupsOutput
+	if err := r.serve(ctx, "DescribeLogGroups", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) DescribeLogStreams(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeLogStreamsInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeLogStreamsOutput, error) {
+	var out cloudwatchlogs.DescribeLogStreamsOutput
+	if err := r.serve(ctx, "DescribeLogStreams", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) GetLogEvents(
+	ctx context.Context,
+	in *cloudwatchlogs.GetLogEventsInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	var out cloudwatchlogs.GetLogEventsOutput
+	if err := r.serve(ctx, "GetLogEvents", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) FilterLogEvents(
+	ctx context.Context,
+	in *cloudwatchlogs.FilterLogEventsInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	var out cloudwatchlogs.FilterLogEventsOutput
+	if err := r.serve(ctx, "FilterLogEvents", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) DescribeMetricFilters(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeMetricFiltersInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeMetricFiltersOutput, error) {
+	var out cloudwatchlogs.DescribeMetricFiltersOutput
+	if err := r.serve(ctx, "DescribeMetricFilters", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) DescribeSubscriptionFilters(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeSubscriptionFiltersInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeSubscriptionFiltersOutput, error) {
+	var out cloudwatchlogs.DescribeSubscriptionFiltersOutput
+	if err := r.serve(ctx, "DescribeSubscriptionFilters", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) TestMetricFilter(
+	ctx context.Context,
+	in *cloudwatchlogs.TestMetricFilterInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.TestMetricFilterOutput, error) {
+	var out cloudwatchlogs.TestMetricFilterOutput
+	if err := r.serve(ctx, "TestMetricFilter", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) PutRetentionPolicy(
+	ctx context.Context,
+	in *cloudwatchlogs.PutRetentionPolicyInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.PutRetentionPolicyOutput, error) {
+	var out cloudwatchlogs.PutRetentionPolicyOutput
+	if err := r.serve(ctx, "PutRetentionPolicy", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) DeleteRetentionPolicy(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteRetentionPolicyInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteRetentionPolicyOutput, error) {
+	var out cloudwatchlogs.DeleteRetentionPolicyOutput
+	if err := r.serve(ctx, "DeleteRetentionPolicy", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) ListTagsForResource(
+	ctx context.Context,
+	in *cloudwatchlogs.ListTagsForResourceInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.ListTagsForResourceOutput, error) {
+	var out cloudwatchlogs.ListTagsForResourceOutput
+	if err := r.serve(ctx, "ListTagsForResource", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) TagResource(
+	ctx context.Context,
+	in *cloudwatchlogs.TagResourceInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.TagResourceOutput, error) {
+	var out cloudwatchlogs.TagResourceOutput
+	if err := r.serve(ctx, "TagResource", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) UntagResource(
+	ctx context.Context,
+	in *cloudwatchlogs.UntagResourceInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.UntagResourceOutput, error) {
+	var out cloudwatchlogs.UntagResourceOutput
+	if err := r.serve(ctx, "UntagResource", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) DeleteLogGroup(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteLogGroupInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteLogGroupOutput, error) {
+	var out cloudwatchlogs.DeleteLogGroupOutput
+	if err := r.serve(ctx, "DeleteLogGroup", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *replay) DeleteLogStream(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteLogStreamInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteLogStreamOutput, error) {
+	var out cloudwatchlogs.DeleteLogStreamOutput
+	if err := r.serve(ctx, "DeleteLogStream", in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,186 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/smithy-go"
+
+	"clviewer/internal/cloudwatch/client"
+	"clviewer/internal/cloudwatch/fake"
+)
+
+func backend() *fake.Backend {
+	return fake.New(fake.Group{Name: "g", Streams: []fake.Stream{{
+		Name: "s",
+		Events: []fake.Event{
+			{Timestamp: 1000, Message: "Started request 42"},
+			{Timestamp: 2000, Message: `{"level":"info","id":7}`},
+		},
+	}}})
+}
+
+func events(t *testing.T, cw client.API, in *cloudwatchlogs.GetLogEventsInput) []string {
+	t.Helper()
+	out, err := cw.GetLogEvents(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, e := range out.Events {
+		messages = append(messages, *e.Message)
+	}
+	return messages
+}
+
+func TestRecordReplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		redact   bool
+		viewed   []string
+		replayed []string
+	}{
+		{
+			"plain", false,
+			[]string{"Started request 42", `{"level":"info","id":7}`},
+			[]string{"Started request 42", `{"level":"info","id":7}`},
+		},
+		{
+			"redacted", true,
+			[]string{"Started request 42", `{"level":"info","id":7}`},
+			[]string{"Xxxxxxx xxxxxxx 11", `{"level":"xxxx","id":1}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := &cloudwatchlogs.GetLogEventsInput{
+				LogGroupName:  aws.String("g"),
+				LogStreamName: aws.String("s"),
+				StartFromHead: aws.Bool(true),
+			}
+
+			var buf bytes.Buffer
+			recorded := client.Client{API: backend()}.Record(client.NewRecorder(&buf, tt.redact))
+			if got := events(t, recorded.API, in); strings.Join(got, "\n") != strings.Join(tt.viewed, "\n") {
+				t.Errorf("recording showed %q, want %q", got, tt.viewed)
+			}
+
+			replayed, err := client.NewReplay(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := events(t, replayed.API, in); strings.Join(got, "\n") != strings.Join(tt.replayed, "\n") {
+				t.Errorf("replay showed %q, want %q", got, tt.replayed)
+			}
+			// a request made again gets the same response
+			if got := events(t, replayed.API, in); strings.Join(got, "\n") != strings.Join(tt.replayed, "\n") {
+				t.Errorf("second replay showed %q, want %q", got, tt.replayed)
+			}
+		})
+	}
+}
+
+func TestRecordRedactedMetricFilter(t *testing.T) {
+	var buf bytes.Buffer
+	recorded := client.Client{API: backend()}.Record(client.NewRecorder(&buf, true))
+	out, err := recorded.API.TestMetricFilter(context.Background(), &cloudwatchlogs.TestMetricFilterInput{
+		FilterPattern:    aws.String("request"),
+		LogEventMessages: []string{"Started request 42", `{"level":"info","id":7}`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Matches) != 1 || aws.ToString(out.Matches[0].EventMessage) != "Started request 42" {
+		t.Fatalf("recording matched %+v, want the first message as it is", out.Matches)
+	}
+	for _, s := range []string{"Started", "42", "info"} {
+		if strings.Contains(buf.String(), s) {
+			t.Errorf("recording holds %q:\n%s", s, buf.String())
+		}
+	}
+
+	// the replayed viewer tests the redacted messages it was shown
+	replayed, err := client.NewReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = replayed.API.TestMetricFilter(context.Background(), &cloudwatchlogs.TestMetricFilterInput{
+		FilterPattern:    aws.String("request"),
+		LogEventMessages: []string{"Xxxxxxx xxxxxxx 11", `{"level":"xxxx","id":1}`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Matches) != 1 || aws.ToString(out.Matches[0].EventMessage) != "Xxxxxxx xxxxxxx 11" {
+		t.Errorf("replay matched %+v, want the first message redacted", out.Matches)
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	b := backend()
+	b.Throttle(fake.GetLogEvents, 1)
+
+	var buf bytes.Buffer
+	recorded := client.Client{API: b}.Record(client.NewRecorder(&buf, false))
+	in := &cloudwatchlogs.GetLogEventsInput{LogGroupName: aws.String("g"), LogStreamName: aws.String("s")}
+	if _, err := recorded.API.GetLogEvents(context.Background(), in); err == nil {
+		t.Fatal("expected the throttling error")
+	}
+	if _, err := recorded.API.GetLogEvents(context.Background(), in); err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, err := client.NewReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = replayed.API.GetLogEvents(context.Background(), in)
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "ThrottlingException" {
+		t.Errorf("replayed %v, want a ThrottlingException", err)
+	}
+	if got := events(t, replayed.API, in); len(got) != 2 {
+		t.Errorf("replayed %d events after the error, want 2", len(got))
+	}
+
+	if _, err := replayed.API.DescribeLogGroups(context.Background(), &cloudwatchlogs.DescribeLogGroupsInput{}); err == nil {
+		t.Error("expected an error for a request that wasn't recorded")
+	}
+	other := &cloudwatchlogs.GetLogEventsInput{LogGroupName: aws.String("g"), LogStreamName: aws.String("other")}
+	if _, err := replayed.API.GetLogEvents(context.Background(), other); err == nil || !strings.Contains(err.Error(), `"other"`) {
+		t.Errorf("replayed %v, want an error naming the input that wasn't recorded", err)
+	}
+	if _, err := replayed.Switch(context.Background(), "", "eu-west-1"); err == nil {
+		t.Error("expected switching regions to fail while replaying")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"text", "Hello World 2023", "Xxxxx Xxxxx 1111"},
+		{"punctuation", "a-b_c: [d]\n\te", "x-x_x: [x]\n\tx"},
+		{"json keys", `{"user":"Bob","age":31}`, `{"user":"Xxx","age":11}`},
+		{"json nested", `{"a":{"b":["cd",-1.5e3]}}`, `{"a":{"b":["xx",-1.1e1]}}`},
+		{"json escapes", `{"msg":"say \"hi\"\n"}`, `{"msg":"xxx \"xx\"\n"}`},
+		{"json literals", `{"ok":true,"v":null}`, `{"ok":true,"v":null}`},
+		{"not json", `{broken`, `{xxxxxx`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := client.Redact(tt.message); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package console
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// View is the location in the CloudWatch console to link to
+type View struct {
+	Region        string
+	Group         string
+	Stream        string // empty to search every stream in the group
+	FilterPattern string
+	Start         int64 // milliseconds since epoch, zero for no start time
+	End           int64 // milliseconds since epoch, zero for no end time
+}
+
+// URL returns the CloudWatch console URL for v
+func URL(v View) string {
+	fragment := "logsV2:log-groups"
+	if v.Group != "" {
+		fragment += "/log-group/" + escapePath(v.Group) + "/log-events"
+		if v.Stream != "" {
+			fragment += "/" + escapePath(v.Stream)
+		}
+		fragment += escapeQuery(v.query())
+	}
+
+	return fmt.Sprintf(
+		"https://%s/cloudwatch/home?region=%s#%s",
+		host(v.Region),
+		v.Region,
+		fragment,
+	)
+}
+
+// host returns the console's host for the partition region is in
+func host(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "console.amazonaws.cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "console.amazonaws-us-gov.com"
+	default:
+		return region + ".console.aws.amazon.com"
+	}
+}
+
+func (v View) query() string {
+	var params []string
+	if v.FilterPattern != "" {
+		params = append(params, "filterPattern="+escape(v.FilterPattern))
+	}
+	if v.Start != 0 {
+		params = append(params, fmt.Sprintf("start=%d", v.Start))
+	}
+	if v.End != 0 {
+		params = append(params, fmt.Sprintf("end=%d", v.End))
+	}
+
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + strings.Join(params, "&")
+}
+
+// Open opens url in the default browser
+func Open(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+// The console expects the fragment to be url encoded with '$' in place of
+// '%', and the names of groups and streams to be encoded twice
+func escapePath(s string) string {
+	return strings.ReplaceAll(escape(s), "%", "$25")
+}
+
+func escapeQuery(s string) string {
+	return strings.ReplaceAll(escape(s), "%", "$")
+}
+
+// escape percent encodes every byte that isn't an unreserved character
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
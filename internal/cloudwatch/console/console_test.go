@@ -0,0 +1,80 @@
+package console_test
+
+import (
+	"testing"
+
+	"clviewer/internal/cloudwatch/console"
+)
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		name string
+		view console.View
+		want string
+	}{
+		{
+			"every group",
+			console.View{Region: "us-east-1"},
+			"https://us-east-1.console.aws.amazon.com/cloudwatch/home?region=us-east-1#logsV2:log-groups",
+		},
+		{
+			"group",
+			console.View{Region: "eu-west-1", Group: "/aws/lambda/orders"},
+			"https://eu-west-1.console.aws.amazon.com/cloudwatch/home?region=eu-west-1" +
+				"#logsV2:log-groups/log-group/$252Faws$252Flambda$252Forders/log-events",
+		},
+		{
+			"stream",
+			console.View{Region: "us-east-1", Group: "/aws/lambda/orders", Stream: "2023/03/01/[$LATEST]abc123"},
+			"https://us-east-1.console.aws.amazon.com/cloudwatch/home?region=us-east-1" +
+				"#logsV2:log-groups/log-group/$252Faws$252Flambda$252Forders/log-events" +
+				"/2023$252F03$252F01$252F$255B$2524LATEST$255Dabc123",
+		},
+		{
+			"filter and time range",
+			console.View{
+				Region:        "us-east-1",
+				Group:         "app",
+				FilterPattern: `{ $.level = "error" }`,
+				Start:         1677672000000,
+				End:           1677675600000,
+			},
+			"https://us-east-1.console.aws.amazon.com/cloudwatch/home?region=us-east-1" +
+				"#logsV2:log-groups/log-group/app/log-events" +
+				"$3FfilterPattern$3D$257B$2520$2524.level$2520$253D$2520$2522error$2522$2520$257D" +
+				"$26start$3D1677672000000$26end$3D1677675600000",
+		},
+		{
+			"start only",
+			console.View{Region: "us-east-1", Group: "app", Start: 1677672000000},
+			"https://us-east-1.console.aws.amazon.com/cloudwatch/home?region=us-east-1" +
+				"#logsV2:log-groups/log-group/app/log-events$3Fstart$3D1677672000000",
+		},
+		{
+			"unicode group",
+			console.View{Region: "us-east-1", Group: "app/é"},
+			"https://us-east-1.console.aws.amazon.com/cloudwatch/home?region=us-east-1" +
+				"#logsV2:log-groups/log-group/app$252F$25C3$25A9/log-events",
+		},
+		{
+			"china",
+			console.View{Region: "cn-north-1", Group: "app"},
+			"https://console.amazonaws.cn/cloudwatch/home?region=cn-north-1" +
+				"#logsV2:log-groups/log-group/app/log-events",
+		},
+		{
+			"govcloud",
+			console.View{Region: "us-gov-west-1", Group: "app"},
+			"https://console.amazonaws-us-gov.com/cloudwatch/home?region=us-gov-west-1" +
+				"#logsV2:log-groups/log-group/app/log-events",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := console.URL(tt.view); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
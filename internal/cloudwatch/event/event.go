@@ -2,74 +2,255 @@ package event
 
 import (
 	"context"
-	"log"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+
+	"clviewer/internal/cloudwatch/client"
+)
+
+const (
+	// DefaultPageSize is the number of events fetched at a time by queries
+	// without a page size
+	DefaultPageSize = 200
+	// MaxPageSize is the most events CloudWatch returns at a time
+	MaxPageSize = 10000
 )
 
+// Query selects the events to page through
+type Query struct {
+	Group         string
+	Stream        string // empty to search every stream in the group
+	FilterPattern string
+	Start         int64 // milliseconds since epoch, zero for no start time
+	End           int64 // milliseconds since epoch, zero for no end time
+	// Tail starts at the newest events rather than the oldest, filtered
+	// queries always start at the oldest
+	Tail bool
+	// PageSize is the number of events fetched at a time, zero for the default
+	PageSize int
+}
+
+func (q Query) limit() *int32 {
+	if q.PageSize <= 0 {
+		return aws.Int32(DefaultPageSize)
+	}
+	return aws.Int32(int32(q.PageSize))
+}
+
+// filtered returns true if the query needs FilterLogEvents rather than
+// GetLogEvents, which can only read a single stream with no pattern
+func (q Query) filtered() bool {
+	return q.FilterPattern != "" || q.Stream == ""
+}
+
+// Paginator pages through the events of a query. A single stream is read in
+// both directions from where it started, newer pages with the forward token
+// of the newest page read so far and older pages with the backward token of
+// the oldest. A filtered query can only be read forwards.
 type Paginator struct {
-	logGroup        string
-	logStream       string
-	eventsPaginator *cloudwatchlogs.GetLogEventsPaginator
+	cw              client.API
+	query           Query
+	filterPaginator *cloudwatchlogs.FilterLogEventsPaginator
+
+	started       bool
+	forwardToken  *string
+	backwardToken *string
+	newest        bool // the newest events have been read
+	oldest        bool // the oldest events have been read
+
+	// where the last page ended, so following can carry on from there
+	lastTimestamp int64
 }
 
-func New(
-	ctx context.Context,
-	logGroupName, logStreamName, filterPattern string,
-) Paginator {
-	cfg, err := config.LoadDefaultConfig(ctx)
+func New(cw client.API, query Query) Paginator {
+	if !query.filtered() {
+		return Paginator{cw: cw, query: query}
+	}
+
+	query.Tail = false
+	in := &cloudwatchlogs.FilterLogEventsInput{
+		Limit:        query.limit(),
+		LogGroupName: aws.String(query.Group),
+	}
+	if query.Stream != "" {
+		in.LogStreamNames = []string{query.Stream}
+	}
+	if query.FilterPattern != "" {
+		in.FilterPattern = aws.String(query.FilterPattern)
+	}
+	if query.Start != 0 {
+		in.StartTime = aws.Int64(query.Start)
+	}
+	if query.End != 0 {
+		in.EndTime = aws.Int64(query.End)
+	}
+
+	return Paginator{
+		cw:              cw,
+		query:           query,
+		filterPaginator: cloudwatchlogs.NewFilterLogEventsPaginator(cw, in),
+	}
+}
+
+// CanTail returns true if the query can start at the newest events
+func (ep *Paginator) CanTail() bool {
+	return !ep.query.filtered()
+}
+
+// HasNewer returns true if Newer may return more events. Reading a single
+// stream only finds out it has reached the end by fetching an empty page.
+func (ep *Paginator) HasNewer() bool {
+	if ep.filterPaginator != nil {
+		return ep.filterPaginator.HasMorePages()
+	}
+	return !ep.newest
+}
+
+// HasOlder returns true if Older may return more events, which until the
+// first page is read is only when starting at the newest events
+func (ep *Paginator) HasOlder() bool {
+	if ep.filterPaginator != nil {
+		return false
+	}
+	if !ep.started {
+		return ep.query.Tail
+	}
+	return !ep.oldest
+}
+
+// Newer returns the page of events after the newest page read so far, or the
+// first page. It returns nil if no pages remain, the page that failed is
+// fetched again by the next call.
+func (ep *Paginator) Newer(ctx context.Context) ([]types.OutputLogEvent, error) {
+	if ep.filterPaginator != nil {
+		return ep.nextFilteredPage(ctx)
+	}
+	if ep.newest {
+		return nil, nil
+	}
+	return ep.newer(ctx)
+}
+
+// Older returns the page of events before the oldest page read so far, or the
+// first page. It returns nil if no pages remain.
+func (ep *Paginator) Older(ctx context.Context) ([]types.OutputLogEvent, error) {
+	if ep.filterPaginator != nil || ep.oldest {
+		return nil, nil
+	}
+	if !ep.started {
+		return ep.first(ctx)
+	}
+
+	out, err := ep.getEvents(ctx, ep.backwardToken, false)
+	if err != nil {
+		return nil, err
+	}
+	// CloudWatch signals the start of a stream by returning the token it was
+	// sent
+	ep.oldest = aws.ToString(out.NextBackwardToken) == aws.ToString(ep.backwardToken)
+	ep.backwardToken = out.NextBackwardToken
+	return out.Events, nil
+}
+
+// Poll returns the next page of events, once every page has been read it
+// returns the events written since the last page, so the end of the log can be
+// followed
+func (ep *Paginator) Poll(ctx context.Context) ([]types.OutputLogEvent, error) {
+	if ep.filterPaginator == nil {
+		return ep.newer(ctx)
+	}
+	if !ep.filterPaginator.HasMorePages() {
+		ep.restart()
+	}
+	return ep.nextFilteredPage(ctx)
+}
+
+// newer reads the page after the forward token, even once the newest events
+// have been read as more may have been written since
+func (ep *Paginator) newer(ctx context.Context) ([]types.OutputLogEvent, error) {
+	if !ep.started {
+		return ep.first(ctx)
+	}
+
+	out, err := ep.getEvents(ctx, ep.forwardToken, true)
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	cw := cloudwatchlogs.NewFromConfig(cfg)
-
-	cw.FilterLogEvents(ctx, &cloudwatchlogs.FilterLogEventsInput{
-		EndTime:             new(int64),
-		FilterPattern:       new(string),
-		Interleaved:         new(bool),
-		Limit:               new(int32),
-		LogGroupIdentifier:  new(string),
-		LogGroupName:        new(string),
-		LogStreamNamePrefix: new(string),
-		LogStreamNames:      []string{},
-		NextToken:           new(string),
-		StartTime:           new(int64),
-		Unmask:              false,
-	})
-	//cloudwatchlogs.NewFilterLogEventsPaginator()
-	// get log events paginator
-	eventsPaginator := cloudwatchlogs.NewGetLogEventsPaginator(
-		cw,
-		&cloudwatchlogs.GetLogEventsInput{
-			Limit:         aws.Int32(200),
-			LogStreamName: aws.String(logStreamName),
-			LogGroupName:  aws.String(logGroupName),
-			StartFromHead: aws.Bool(true),
-		},
-	)
+		return nil, err
+	}
+	// the end of a stream is signalled the same way as its start
+	ep.newest = aws.ToString(out.NextForwardToken) == aws.ToString(ep.forwardToken)
+	ep.forwardToken = out.NextForwardToken
+	return out.Events, nil
+}
+
+// first reads the page at the start or, when tailing, the end of the stream
+func (ep *Paginator) first(ctx context.Context) ([]types.OutputLogEvent, error) {
+	out, err := ep.getEvents(ctx, nil, !ep.query.Tail)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	ep.started = true
+	ep.forwardToken = out.NextForwardToken
+	ep.backwardToken = out.NextBackwardToken
+	// there's nothing before the start of the stream or after its end
+	ep.oldest = !ep.query.Tail
+	ep.newest = ep.query.Tail
+	return out.Events, nil
+}
 
-	return Paginator{
-		logGroup:        logGroupName,
-		logStream:       logStreamName,
-		eventsPaginator: eventsPaginator,
+func (ep *Paginator) getEvents(
+	ctx context.Context,
+	token *string,
+	fromHead bool,
+) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	in := &cloudwatchlogs.GetLogEventsInput{
+		Limit:         ep.query.limit(),
+		LogStreamName: aws.String(ep.query.Stream),
+		LogGroupName:  aws.String(ep.query.Group),
+		StartFromHead: aws.Bool(fromHead),
+		NextToken:     token,
 	}
+	if ep.query.Start != 0 {
+		in.StartTime = aws.Int64(ep.query.Start)
+	}
+	if ep.query.End != 0 {
+		in.EndTime = aws.Int64(ep.query.End)
+	}
+	return ep.cw.GetLogEvents(ctx, in)
+}
+
+// restart starts a new filter paginator after the last event. FilterLogEvents
+// has no token for the end of the results, so events written later with the
+// same timestamp as the last event are missed.
+func (ep *Paginator) restart() {
+	query := ep.query
+	if ep.lastTimestamp != 0 {
+		query.Start = ep.lastTimestamp + 1
+	}
+	restarted := New(ep.cw, query)
+	ep.filterPaginator = restarted.filterPaginator
 }
 
-// Get next page of events, return nil if no pages remain
-func (ep Paginator) NextPage(ctx context.Context) []types.OutputLogEvent {
-	if !ep.eventsPaginator.HasMorePages() {
-		return nil
+func (ep *Paginator) nextFilteredPage(ctx context.Context) ([]types.OutputLogEvent, error) {
+	if !ep.filterPaginator.HasMorePages() {
+		return nil, nil
 	}
-	eventsOutput, err := ep.eventsPaginator.NextPage(ctx)
+	filterOutput, err := ep.filterPaginator.NextPage(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+
+	events := make([]types.OutputLogEvent, 0, len(filterOutput.Events))
+	for _, e := range filterOutput.Events {
+		events = append(events, types.OutputLogEvent{
+			IngestionTime: e.IngestionTime,
+			Message:       e.Message,
+			Timestamp:     e.Timestamp,
+		})
+	}
+	if len(events) > 0 {
+		ep.lastTimestamp = aws.ToInt64(events[len(events)-1].Timestamp)
 	}
-	return eventsOutput.Events
+	return events, nil
 }
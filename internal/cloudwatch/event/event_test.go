@@ -0,0 +1,162 @@
+package event_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+
+	"clviewer/internal/cloudwatch/event"
+	"clviewer/internal/cloudwatch/fake"
+)
+
+func backend(n int) *fake.Backend {
+	var events []fake.Event
+	for i := 0; i < n; i++ {
+		events = append(events, fake.Event{Timestamp: int64(1000 * (i + 1)), Message: "event"})
+	}
+	b := fake.New(fake.Group{Name: "g", Streams: []fake.Stream{{Name: "s", Events: events}}})
+	b.PageSize = 2
+	return b
+}
+
+// seconds returns the timestamps of events in seconds, which is their index
+// in the stream plus one
+func seconds(events []types.OutputLogEvent) []int64 {
+	s := []int64{}
+	for _, e := range events {
+		s = append(s, aws.ToInt64(e.Timestamp)/1000)
+	}
+	return s
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPaginator(t *testing.T) {
+	type page struct {
+		older  bool
+		events []int64
+	}
+
+	tests := []struct {
+		name  string
+		tail  bool
+		pages []page
+		// after the last page
+		hasNewer, hasOlder bool
+	}{
+		{
+			name: "head",
+			pages: []page{
+				{false, []int64{1, 2}},
+				{false, []int64{3, 4}},
+				{false, []int64{5}},
+				{false, []int64{}},
+			},
+		},
+		{
+			name: "tail",
+			tail: true,
+			pages: []page{
+				{false, []int64{4, 5}},
+				{true, []int64{2, 3}},
+				{true, []int64{1}},
+				{true, []int64{}},
+			},
+		},
+		{
+			name: "tail read forwards",
+			tail: true,
+			pages: []page{
+				{false, []int64{4, 5}},
+				{false, []int64{}},
+			},
+			hasOlder: true,
+		},
+		{
+			name: "head read backwards",
+			pages: []page{
+				{false, []int64{1, 2}},
+				{true, []int64{}},
+			},
+			hasNewer: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			p := event.New(backend(5), event.Query{Group: "g", Stream: "s", Tail: tt.tail})
+			if !p.CanTail() {
+				t.Error("single stream can't tail")
+			}
+
+			for i, want := range tt.pages {
+				var (
+					events []types.OutputLogEvent
+					err    error
+				)
+				if want.older {
+					events, err = p.Older(ctx)
+				} else {
+					events, err = p.Newer(ctx)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := seconds(events); !equal(got, want.events) {
+					t.Errorf("page %d: got events %v, want %v", i, got, want.events)
+				}
+			}
+
+			if p.HasNewer() != tt.hasNewer {
+				t.Errorf("HasNewer() = %v, want %v", p.HasNewer(), tt.hasNewer)
+			}
+			if p.HasOlder() != tt.hasOlder {
+				t.Errorf("HasOlder() = %v, want %v", p.HasOlder(), tt.hasOlder)
+			}
+		})
+	}
+}
+
+func TestPollTail(t *testing.T) {
+	ctx := context.Background()
+	b := backend(5)
+	p := event.New(b, event.Query{Group: "g", Stream: "s", Tail: true})
+
+	if events, err := p.Poll(ctx); err != nil || !equal(seconds(events), []int64{4, 5}) {
+		t.Fatalf("got events %v, %v, want [4 5]", seconds(events), err)
+	}
+
+	b.Append("g", "s", fake.Event{Timestamp: 6000, Message: "event"})
+	if events, err := p.Poll(ctx); err != nil || !equal(seconds(events), []int64{6}) {
+		t.Fatalf("got events %v, %v, want [6]", seconds(events), err)
+	}
+}
+
+func TestFilteredCantTail(t *testing.T) {
+	ctx := context.Background()
+	p := event.New(backend(5), event.Query{Group: "g", Stream: "s", FilterPattern: "event", Tail: true})
+	if p.CanTail() || p.HasOlder() {
+		t.Fatal("filtered query can be read backwards")
+	}
+
+	events, err := p.Newer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := seconds(events); !equal(got, []int64{1, 2}) {
+		t.Errorf("got events %v, want [1 2]", got)
+	}
+}
@@ -0,0 +1,776 @@
+// Package fake is an in-memory CloudWatch Logs backend for tests. It pages
+// through scripted log groups, streams and events with tokens the way
+// CloudWatch does, and can be made slow or return errors such as throttling.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/smithy-go"
+
+	"clviewer/internal/cloudwatch/client"
+	"clviewer/internal/cloudwatch/pattern"
+)
+
+// Operation is the name of a CloudWatch Logs api call
+type Operation string
+
+const (
+	DescribeLogGroups  Operation = "DescribeLogGroups"
+	DescribeLogStreams Operation = "DescribeLogStreams"
+	GetLogEvents       Operation = "GetLogEvents"
+	FilterLogEvents    Operation = "FilterLogEvents"
+
+	DescribeMetricFilters       Operation = "DescribeMetricFilters"
+	DescribeSubscriptionFilters Operation = "DescribeSubscriptionFilters"
+	TestMetricFilter            Operation = "TestMetricFilter"
+
+	PutRetentionPolicy    Operation = "PutRetentionPolicy"
+	DeleteRetentionPolicy Operation = "DeleteRetentionPolicy"
+	ListTagsForResource   Operation = "ListTagsForResource"
+	TagResource           Operation = "TagResource"
+	UntagResource         Operation = "UntagResource"
+	DeleteLogGroup        Operation = "DeleteLogGroup"
+	DeleteLogStream       Operation = "DeleteLogStream"
+)
+
+// arnPrefix starts the arn of every log group, which is followed by its name
+const arnPrefix = "arn:aws:logs:fake:000000000000:log-group:"
+
+// default limits of each operation when the request has none
+const (
+	defaultGroupLimit  = 50
+	defaultStreamLimit = 50
+	defaultEventLimit  = 10000
+	defaultFilterLimit = 50
+)
+
+// maxTestedMessages is the most messages TestMetricFilter takes at once
+const maxTestedMessages = 50
+
+type Event struct {
+	Timestamp int64 // milliseconds since epoch
+	Message   string
+}
+
+type Stream struct {
+	Name   string
+	Events []Event // in timestamp order
+}
+
+type Group struct {
+	Name    string
+	Streams []Stream
+	// Retention is the days events are kept, zero to keep them forever
+	Retention int32
+	Tags      map[string]string
+	// the group of each filter is set when they're described
+	MetricFilters       []types.MetricFilter
+	SubscriptionFilters []types.SubscriptionFilter
+}
+
+// ARN returns the arn of the log group, as tags are listed and changed by
+func ARN(group string) string {
+	return arnPrefix + group
+}
+
+// Backend implements client.API
+type Backend struct {
+	// PageSize is the most items a call returns, when the request asks for
+	// more. Zero leaves it to the request's limit.
+	PageSize int
+	// Latency is how long every call takes
+	Latency time.Duration
+
+	mu     sync.Mutex
+	groups []Group
+	errs   map[Operation][]error
+	calls  map[Operation]int
+}
+
+var _ client.API = (*Backend)(nil)
+
+func New(groups ...Group) *Backend {
+	return &Backend{
+		groups: groups,
+		errs:   map[Operation][]error{},
+		calls:  map[Operation]int{},
+	}
+}
+
+// Append adds events to the end of a stream, creating the group and stream
+// if they don't exist, as if they had just been written
+func (b *Backend) Append(group, stream string, events ...Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g := b.group(group)
+	if g == nil {
+		b.groups = append(b.groups, Group{Name: group})
+		g = &b.groups[len(b.groups)-1]
+	}
+	s := findStream(g, stream)
+	if s == nil {
+		g.Streams = append(g.Streams, Stream{Name: stream})
+		s = &g.Streams[len(g.Streams)-1]
+	}
+	s.Events = append(s.Events, events...)
+}
+
+// Fail makes the next calls of op return errs, one per call, before it
+// succeeds again
+func (b *Backend) Fail(op Operation, errs ...error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.errs[op] = append(b.errs[op], errs...)
+}
+
+// Throttle makes the next n calls of op fail with a throttling error
+func (b *Backend) Throttle(op Operation, n int) {
+	for i := 0; i < n; i++ {
+		b.Fail(op, ThrottlingError())
+	}
+}
+
+// ThrottlingError is the error CloudWatch returns when calls exceed its rate
+// limits
+func ThrottlingError() error {
+	return &smithy.GenericAPIError{
+		Code:    "ThrottlingException",
+		Message: "Rate exceeded",
+		Fault:   smithy.FaultClient,
+	}
+}
+
+// Group returns a copy of the group called name, false if there's no such
+// group or it's been deleted
+func (b *Backend) Group(name string) (Group, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g := b.group(name)
+	if g == nil {
+		return Group{}, false
+	}
+	copied := *g
+	copied.Streams = append([]Stream(nil), g.Streams...)
+	copied.MetricFilters = append([]types.MetricFilter(nil), g.MetricFilters...)
+	copied.SubscriptionFilters = append([]types.SubscriptionFilter(nil), g.SubscriptionFilters...)
+	copied.Tags = map[string]string{}
+	for k, v := range g.Tags {
+		copied.Tags[k] = v
+	}
+	return copied, true
+}
+
+// Calls returns how many times op has been called, including failed calls
+func (b *Backend) Calls(op Operation) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls[op]
+}
+
+// call counts a call of op, waits out the latency and returns the next
+// scripted error. The lock is held on return if err is nil.
+func (b *Backend) call(ctx context.Context, op Operation) error {
+	b.mu.Lock()
+	b.calls[op]++
+	latency := b.Latency
+	b.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	b.mu.Lock()
+	if errs := b.errs[op]; len(errs) > 0 {
+		b.errs[op] = errs[1:]
+		b.mu.Unlock()
+		return errs[0]
+	}
+	return nil
+}
+
+func (b *Backend) DescribeLogGroups(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeLogGroupsInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	if err := b.call(ctx, DescribeLogGroups); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	var groups []types.LogGroup
+	for _, g := range b.groups {
+		if strings.HasPrefix(g.Name, aws.ToString(in.LogGroupNamePrefix)) {
+			group := types.LogGroup{
+				LogGroupName: aws.String(g.Name),
+				// like CloudWatch, the arn of a group ends with a wildcard
+				Arn: aws.String(ARN(g.Name) + ":*"),
+			}
+			if g.Retention > 0 {
+				group.RetentionInDays = aws.Int32(g.Retention)
+			}
+			groups = append(groups, group)
+		}
+	}
+
+	start, end, next, err := b.page(len(groups), in.NextToken, in.Limit, defaultGroupLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudwatchlogs.DescribeLogGroupsOutput{
+		LogGroups: groups[start:end],
+		NextToken: next,
+	}, nil
+}
+
+func (b *Backend) DescribeLogStreams(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeLogStreamsInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeLogStreamsOutput, error) {
+	if err := b.call(ctx, DescribeLogStreams); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	g := b.group(aws.ToString(in.LogGroupName))
+	if g == nil {
+		return nil, notFound("log group", aws.ToString(in.LogGroupName))
+	}
+
+	var streams []types.LogStream
+	for _, s := range g.Streams {
+		if !strings.HasPrefix(s.Name, aws.ToString(in.LogStreamNamePrefix)) {
+			continue
+		}
+		stream := types.LogStream{LogStreamName: aws.String(s.Name)}
+		if len(s.Events) > 0 {
+			stream.FirstEventTimestamp = aws.Int64(s.Events[0].Timestamp)
+			stream.LastEventTimestamp = aws.Int64(s.Events[len(s.Events)-1].Timestamp)
+		}
+		streams = append(streams, stream)
+	}
+
+	less := func(i, j int) bool {
+		return aws.ToString(streams[i].LogStreamName) < aws.ToString(streams[j].LogStreamName)
+	}
+	if in.OrderBy == types.OrderByLastEventTime {
+		less = func(i, j int) bool {
+			return aws.ToInt64(streams[i].LastEventTimestamp) < aws.ToInt64(streams[j].LastEventTimestamp)
+		}
+	}
+	if aws.ToBool(in.Descending) {
+		ascending := less
+		less = func(i, j int) bool { return ascending(j, i) }
+	}
+	sort.SliceStable(streams, less)
+
+	start, end, next, err := b.page(len(streams), in.NextToken, in.Limit, defaultStreamLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudwatchlogs.DescribeLogStreamsOutput{
+		LogStreams: streams[start:end],
+		NextToken:  next,
+	}, nil
+}
+
+// GetLogEvents pages forwards from the head of the stream, or backwards from
+// its tail. Like CloudWatch, the end of the stream is reached when the token
+// returned is the one that was sent.
+func (b *Backend) GetLogEvents(
+	ctx context.Context,
+	in *cloudwatchlogs.GetLogEventsInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	if err := b.call(ctx, GetLogEvents); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	g := b.group(aws.ToString(in.LogGroupName))
+	if g == nil {
+		return nil, notFound("log group", aws.ToString(in.LogGroupName))
+	}
+	s := findStream(g, aws.ToString(in.LogStreamName))
+	if s == nil {
+		return nil, notFound("log stream", aws.ToString(in.LogStreamName))
+	}
+	events := between(s.Events, aws.ToInt64(in.StartTime), aws.ToInt64(in.EndTime))
+	limit := b.limit(in.Limit, defaultEventLimit)
+
+	// forward tokens are "f/<index of the first event>", backward tokens are
+	// "b/<index after the last event>"
+	forward, index := aws.ToBool(in.StartFromHead), 0
+	if !forward {
+		index = len(events)
+	}
+	if in.NextToken != nil {
+		var err error
+		if forward, index, err = parseEventToken(*in.NextToken, len(events)); err != nil {
+			return nil, err
+		}
+	}
+
+	start, end := index, index+limit
+	if !forward {
+		start, end = index-limit, index
+	}
+	start, end = clampIndex(start, len(events)), clampIndex(end, len(events))
+
+	out := &cloudwatchlogs.GetLogEventsOutput{
+		NextForwardToken:  aws.String(fmt.Sprintf("f/%d", end)),
+		NextBackwardToken: aws.String(fmt.Sprintf("b/%d", start)),
+	}
+	for _, e := range events[start:end] {
+		out.Events = append(out.Events, types.OutputLogEvent{
+			Timestamp:     aws.Int64(e.Timestamp),
+			IngestionTime: aws.Int64(e.Timestamp),
+			Message:       aws.String(e.Message),
+		})
+	}
+	return out, nil
+}
+
+// FilterLogEvents searches the streams of a group in timestamp order. Filter
+// patterns are matched the way CloudWatch matches them.
+func (b *Backend) FilterLogEvents(
+	ctx context.Context,
+	in *cloudwatchlogs.FilterLogEventsInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	if err := b.call(ctx, FilterLogEvents); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	g := b.group(aws.ToString(in.LogGroupName))
+	if g == nil {
+		return nil, notFound("log group", aws.ToString(in.LogGroupName))
+	}
+
+	names := map[string]bool{}
+	for _, name := range in.LogStreamNames {
+		names[name] = true
+	}
+	p, err := filterPattern(in.FilterPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []types.FilteredLogEvent
+	for _, s := range g.Streams {
+		if len(names) > 0 && !names[s.Name] {
+			continue
+		}
+		if !strings.HasPrefix(s.Name, aws.ToString(in.LogStreamNamePrefix)) {
+			continue
+		}
+		for i, e := range between(s.Events, aws.ToInt64(in.StartTime), aws.ToInt64(in.EndTime)) {
+			if !p.Match(e.Message) {
+				continue
+			}
+			events = append(events, types.FilteredLogEvent{
+				EventId:       aws.String(fmt.Sprintf("%s/%d", s.Name, i)),
+				LogStreamName: aws.String(s.Name),
+				Timestamp:     aws.Int64(e.Timestamp),
+				IngestionTime: aws.Int64(e.Timestamp),
+				Message:       aws.String(e.Message),
+			})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return aws.ToInt64(events[i].Timestamp) < aws.ToInt64(events[j].Timestamp)
+	})
+
+	start, end, next, err := b.page(len(events), in.NextToken, in.Limit, defaultEventLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudwatchlogs.FilterLogEventsOutput{
+		Events:    events[start:end],
+		NextToken: next,
+	}, nil
+}
+
+// DescribeMetricFilters pages through the metric filters of a group whose
+// names start with the prefix
+func (b *Backend) DescribeMetricFilters(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeMetricFiltersInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeMetricFiltersOutput, error) {
+	if err := b.call(ctx, DescribeMetricFilters); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	g := b.group(aws.ToString(in.LogGroupName))
+	if g == nil {
+		return nil, notFound("log group", aws.ToString(in.LogGroupName))
+	}
+	var filters []types.MetricFilter
+	for _, f := range g.MetricFilters {
+		if strings.HasPrefix(aws.ToString(f.FilterName), aws.ToString(in.FilterNamePrefix)) {
+			f.LogGroupName = aws.String(g.Name)
+			filters = append(filters, f)
+		}
+	}
+
+	start, end, next, err := b.page(len(filters), in.NextToken, in.Limit, defaultFilterLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudwatchlogs.DescribeMetricFiltersOutput{
+		MetricFilters: filters[start:end],
+		NextToken:     next,
+	}, nil
+}
+
+// DescribeSubscriptionFilters pages through the subscription filters of a
+// group whose names start with the prefix
+func (b *Backend) DescribeSubscriptionFilters(
+	ctx context.Context,
+	in *cloudwatchlogs.DescribeSubscriptionFiltersInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DescribeSubscriptionFiltersOutput, error) {
+	if err := b.call(ctx, DescribeSubscriptionFilters); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	g := b.group(aws.ToString(in.LogGroupName))
+	if g == nil {
+		return nil, notFound("log group", aws.ToString(in.LogGroupName))
+	}
+	var filters []types.SubscriptionFilter
+	for _, f := range g.SubscriptionFilters {
+		if strings.HasPrefix(aws.ToString(f.FilterName), aws.ToString(in.FilterNamePrefix)) {
+			f.LogGroupName = aws.String(g.Name)
+			filters = append(filters, f)
+		}
+	}
+
+	start, end, next, err := b.page(len(filters), in.NextToken, in.Limit, defaultFilterLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudwatchlogs.DescribeSubscriptionFiltersOutput{
+		SubscriptionFilters: filters[start:end],
+		NextToken:           next,
+	}, nil
+}
+
+// TestMetricFilter returns the messages that match the pattern, numbered
+// from 1 in the order they were sent. Patterns are matched like
+// FilterLogEvents matches them.
+func (b *Backend) TestMetricFilter(
+	ctx context.Context,
+	in *cloudwatchlogs.TestMetricFilterInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.TestMetricFilterOutput, error) {
+	if err := b.call(ctx, TestMetricFilter); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	if n := len(in.LogEventMessages); n == 0 || n > maxTestedMessages {
+		return nil, invalidParameter(fmt.Sprintf(
+			"logEventMessages must have between 1 and %d messages", maxTestedMessages,
+		))
+	}
+	p, err := filterPattern(in.FilterPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &cloudwatchlogs.TestMetricFilterOutput{}
+	for i, message := range in.LogEventMessages {
+		if p.Match(message) {
+			out.Matches = append(out.Matches, types.MetricFilterMatchRecord{
+				EventNumber:  int64(i + 1),
+				EventMessage: aws.String(message),
+			})
+		}
+	}
+	return out, nil
+}
+
+func (b *Backend) PutRetentionPolicy(
+	ctx context.Context,
+	in *cloudwatchlogs.PutRetentionPolicyInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.PutRetentionPolicyOutput, error) {
+	if err := b.call(ctx, PutRetentionPolicy); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	g := b.group(aws.ToString(in.LogGroupName))
+	if g == nil {
+		return nil, notFound("log group", aws.ToString(in.LogGroupName))
+	}
+	if aws.ToInt32(in.RetentionInDays) <= 0 {
+		return nil, invalidParameter("retentionInDays must be a positive number of days")
+	}
+	g.Retention = aws.ToInt32(in.RetentionInDays)
+	return &cloudwatchlogs.PutRetentionPolicyOutput{}, nil
+}
+
+func (b *Backend) DeleteRetentionPolicy(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteRetentionPolicyInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteRetentionPolicyOutput, error) {
+	if err := b.call(ctx, DeleteRetentionPolicy); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	g := b.group(aws.ToString(in.LogGroupName))
+	if g == nil {
+		return nil, notFound("log group", aws.ToString(in.LogGroupName))
+	}
+	g.Retention = 0
+	return &cloudwatchlogs.DeleteRetentionPolicyOutput{}, nil
+}
+
+func (b *Backend) ListTagsForResource(
+	ctx context.Context,
+	in *cloudwatchlogs.ListTagsForResourceInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.ListTagsForResourceOutput, error) {
+	if err := b.call(ctx, ListTagsForResource); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	g, err := b.groupByARN(aws.ToString(in.ResourceArn))
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	for k, v := range g.Tags {
+		tags[k] = v
+	}
+	return &cloudwatchlogs.ListTagsForResourceOutput{Tags: tags}, nil
+}
+
+func (b *Backend) TagResource(
+	ctx context.Context,
+	in *cloudwatchlogs.TagResourceInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.TagResourceOutput, error) {
+	if err := b.call(ctx, TagResource); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	g, err := b.groupByARN(aws.ToString(in.ResourceArn))
+	if err != nil {
+		return nil, err
+	}
+	if g.Tags == nil {
+		g.Tags = map[string]string{}
+	}
+	for k, v := range in.Tags {
+		g.Tags[k] = v
+	}
+	return &cloudwatchlogs.TagResourceOutput{}, nil
+}
+
+func (b *Backend) UntagResource(
+	ctx context.Context,
+	in *cloudwatchlogs.UntagResourceInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.UntagResourceOutput, error) {
+	if err := b.call(ctx, UntagResource); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	g, err := b.groupByARN(aws.ToString(in.ResourceArn))
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range in.TagKeys {
+		delete(g.Tags, k)
+	}
+	return &cloudwatchlogs.UntagResourceOutput{}, nil
+}
+
+func (b *Backend) DeleteLogGroup(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteLogGroupInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteLogGroupOutput, error) {
+	if err := b.call(ctx, DeleteLogGroup); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	for i := range b.groups {
+		if b.groups[i].Name == aws.ToString(in.LogGroupName) {
+			b.groups = append(b.groups[:i], b.groups[i+1:]...)
+			return &cloudwatchlogs.DeleteLogGroupOutput{}, nil
+		}
+	}
+	return nil, notFound("log group", aws.ToString(in.LogGroupName))
+}
+
+func (b *Backend) DeleteLogStream(
+	ctx context.Context,
+	in *cloudwatchlogs.DeleteLogStreamInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.DeleteLogStreamOutput, error) {
+	if err := b.call(ctx, DeleteLogStream); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	g := b.group(aws.ToString(in.LogGroupName))
+	if g == nil {
+		return nil, notFound("log group", aws.ToString(in.LogGroupName))
+	}
+	for i := range g.Streams {
+		if g.Streams[i].Name == aws.ToString(in.LogStreamName) {
+			g.Streams = append(g.Streams[:i], g.Streams[i+1:]...)
+			return &cloudwatchlogs.DeleteLogStreamOutput{}, nil
+		}
+	}
+	return nil, notFound("log stream", aws.ToString(in.LogStreamName))
+}
+
+// page returns the range of the page of n items starting at token, and the
+// token of the next page, nil on the last page
+func (b *Backend) page(n int, token *string, limit *int32, defaultLimit int) (int, int, *string, error) {
+	start := 0
+	if token != nil {
+		var err error
+		if start, err = strconv.Atoi(*token); err != nil || start < 0 || start > n {
+			return 0, 0, nil, invalidToken(*token)
+		}
+	}
+
+	end := clampIndex(start+b.limit(limit, defaultLimit), n)
+	if end == n {
+		return start, end, nil, nil
+	}
+	return start, end, aws.String(strconv.Itoa(end)), nil
+}
+
+// limit returns the number of items to return for a request's limit
+func (b *Backend) limit(limit *int32, defaultLimit int) int {
+	n := defaultLimit
+	if limit != nil && *limit > 0 {
+		n = int(*limit)
+	}
+	if b.PageSize > 0 && b.PageSize < n {
+		n = b.PageSize
+	}
+	return n
+}
+
+func (b *Backend) group(name string) *Group {
+	for i := range b.groups {
+		if b.groups[i].Name == name {
+			return &b.groups[i]
+		}
+	}
+	return nil
+}
+
+// groupByARN returns the group of a resource arn, which unlike the arn
+// described doesn't end with a wildcard
+func (b *Backend) groupByARN(arn string) (*Group, error) {
+	name := strings.TrimPrefix(arn, arnPrefix)
+	if !strings.HasPrefix(arn, arnPrefix) || strings.HasSuffix(name, ":*") {
+		return nil, invalidParameter(fmt.Sprintf("invalid resource arn: %s", arn))
+	}
+	g := b.group(name)
+	if g == nil {
+		return nil, notFound("log group", name)
+	}
+	return g, nil
+}
+
+func findStream(g *Group, name string) *Stream {
+	for i := range g.Streams {
+		if g.Streams[i].Name == name {
+			return &g.Streams[i]
+		}
+	}
+	return nil
+}
+
+// between returns the events from start up to but not including end, zero
+// for either leaves that side open
+func between(events []Event, start, end int64) []Event {
+	var in []Event
+	for _, e := range events {
+		if (start == 0 || e.Timestamp >= start) && (end == 0 || e.Timestamp < end) {
+			in = append(in, e)
+		}
+	}
+	return in
+}
+
+// filterPattern parses the filter pattern of a request, rejecting it as
+// CloudWatch would if it isn't valid
+func filterPattern(s *string) (pattern.Pattern, error) {
+	p, err := pattern.Parse(aws.ToString(s))
+	if err != nil {
+		return pattern.Pattern{}, invalidParameter("Invalid filter pattern: " + err.Error())
+	}
+	return p, nil
+}
+
+func parseEventToken(token string, n int) (forward bool, index int, err error) {
+	direction, number, ok := strings.Cut(token, "/")
+	index, err = strconv.Atoi(number)
+	if !ok || err != nil || (direction != "f" && direction != "b") || index < 0 || index > n {
+		return false, 0, invalidToken(token)
+	}
+	return direction == "f", index, nil
+}
+
+func clampIndex(i, n int) int {
+	switch {
+	case i < 0:
+		return 0
+	case i > n:
+		return n
+	default:
+		return i
+	}
+}
+
+func notFound(kind, name string) error {
+	return &types.ResourceNotFoundException{
+		Message: aws.String(fmt.Sprintf("The specified %s does not exist: %s", kind, name)),
+	}
+}
+
+func invalidToken(token string) error {
+	return invalidParameter(fmt.Sprintf("The specified nextToken is invalid: %s", token))
+}
+
+func invalidParameter(message string) error {
+	return &types.InvalidParameterException{Message: aws.String(message)}
+}
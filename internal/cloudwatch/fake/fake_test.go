@@ -0,0 +1,357 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/smithy-go"
+)
+
+func events(n int) []Event {
+	var events []Event
+	for i := 0; i < n; i++ {
+		events = append(events, Event{Timestamp: int64(1000 * (i + 1)), Message: "event"})
+	}
+	return events
+}
+
+func TestGetLogEventsPages(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageSize int
+		head     bool
+		pages    []int // events on each page before the token repeats
+	}{
+		{"one page", 0, true, []int{5}},
+		{"forwards", 2, true, []int{2, 2, 1}},
+		{"backwards", 2, false, []int{2, 2, 1}},
+		{"empty", 2, true, []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := 0
+			for _, size := range tt.pages {
+				n += size
+			}
+			b := New(Group{Name: "g", Streams: []Stream{{Name: "s", Events: events(n)}}})
+			b.PageSize = tt.pageSize
+
+			in := &cloudwatchlogs.GetLogEventsInput{
+				LogGroupName:  aws.String("g"),
+				LogStreamName: aws.String("s"),
+				StartFromHead: aws.Bool(tt.head),
+			}
+			var pages []int
+			for {
+				out, err := b.GetLogEvents(context.Background(), in)
+				if err != nil {
+					t.Fatal(err)
+				}
+				token := out.NextForwardToken
+				if !tt.head {
+					token = out.NextBackwardToken
+				}
+				if in.NextToken != nil && *in.NextToken == *token {
+					if len(out.Events) != 0 {
+						t.Errorf("last page has %d events", len(out.Events))
+					}
+					break
+				}
+				pages = append(pages, len(out.Events))
+				in.NextToken = token
+			}
+
+			if len(pages) != len(tt.pages) {
+				t.Fatalf("got pages %v, want %v", pages, tt.pages)
+			}
+			for i := range pages {
+				if pages[i] != tt.pages[i] {
+					t.Fatalf("got pages %v, want %v", pages, tt.pages)
+				}
+			}
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	b := New()
+	b.Append("g", "s", events(2)...)
+
+	in := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String("g"),
+		LogStreamName: aws.String("s"),
+		StartFromHead: aws.Bool(true),
+	}
+	out, err := b.GetLogEvents(context.Background(), in)
+	if err != nil || len(out.Events) != 2 {
+		t.Fatalf("got %d events, %v", len(out.Events), err)
+	}
+
+	// events written later are read from the last token
+	b.Append("g", "s", Event{Timestamp: 5000, Message: "new"})
+	in.NextToken = out.NextForwardToken
+	out, err = b.GetLogEvents(context.Background(), in)
+	if err != nil || len(out.Events) != 1 || aws.ToString(out.Events[0].Message) != "new" {
+		t.Fatalf("got %v, %v", out.Events, err)
+	}
+}
+
+func TestFilterLogEvents(t *testing.T) {
+	b := New(Group{Name: "g", Streams: []Stream{
+		{Name: "a", Events: []Event{{1000, "ERROR one"}, {3000, "INFO two"}}},
+		{Name: "b", Events: []Event{{2000, "ERROR three"}, {4000, "ERROR four"}}},
+	}})
+	b.PageSize = 2
+
+	tests := []struct {
+		name  string
+		in    cloudwatchlogs.FilterLogEventsInput
+		want  []string
+		pages int
+	}{
+		{"every stream", cloudwatchlogs.FilterLogEventsInput{}, []string{"ERROR one", "ERROR three", "INFO two", "ERROR four"}, 2},
+		{"pattern", cloudwatchlogs.FilterLogEventsInput{FilterPattern: aws.String("ERROR")}, []string{"ERROR one", "ERROR three", "ERROR four"}, 2},
+		{"exclusion", cloudwatchlogs.FilterLogEventsInput{FilterPattern: aws.String("ERROR -three")}, []string{"ERROR one", "ERROR four"}, 1},
+		{"stream", cloudwatchlogs.FilterLogEventsInput{LogStreamNames: []string{"a"}}, []string{"ERROR one", "INFO two"}, 1},
+		{"time range", cloudwatchlogs.FilterLogEventsInput{StartTime: aws.Int64(2000), EndTime: aws.Int64(4000)}, []string{"ERROR three", "INFO two"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.in
+			in.LogGroupName = aws.String("g")
+			p := cloudwatchlogs.NewFilterLogEventsPaginator(b, &in)
+
+			var got []string
+			pages := 0
+			for p.HasMorePages() {
+				out, err := p.NextPage(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				pages++
+				for _, e := range out.Events {
+					got = append(got, aws.ToString(e.Message))
+				}
+			}
+
+			if len(got) != len(tt.want) || pages != tt.pages {
+				t.Fatalf("got %q in %d pages, want %q in %d", got, pages, tt.want, tt.pages)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDescribeLogStreamsOrder(t *testing.T) {
+	b := New(Group{Name: "g", Streams: []Stream{
+		{Name: "old", Events: []Event{{1000, ""}}},
+		{Name: "new", Events: []Event{{3000, ""}}},
+		{Name: "middle", Events: []Event{{2000, ""}}},
+	}})
+
+	out, err := b.DescribeLogStreams(context.Background(), &cloudwatchlogs.DescribeLogStreamsInput{
+		LogGroupName: aws.String("g"),
+		OrderBy:      types.OrderByLastEventTime,
+		Descending:   aws.Bool(true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"new", "middle", "old"}
+	for i, s := range out.LogStreams {
+		if aws.ToString(s.LogStreamName) != want[i] {
+			t.Fatalf("stream %d is %s, want %s", i, aws.ToString(s.LogStreamName), want[i])
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	b := New(Group{Name: "g"})
+	b.Throttle(DescribeLogGroups, 2)
+	broken := errors.New("broken")
+	b.Fail(DescribeLogGroups, broken)
+
+	var apiErr smithy.APIError
+	for i := 0; i < 2; i++ {
+		_, err := b.DescribeLogGroups(context.Background(), &cloudwatchlogs.DescribeLogGroupsInput{})
+		if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "ThrottlingException" {
+			t.Fatalf("call %d returned %v, want throttling", i, err)
+		}
+	}
+	if _, err := b.DescribeLogGroups(context.Background(), &cloudwatchlogs.DescribeLogGroupsInput{}); err != broken {
+		t.Fatalf("got %v, want %v", err, broken)
+	}
+	if _, err := b.DescribeLogGroups(context.Background(), &cloudwatchlogs.DescribeLogGroupsInput{}); err != nil {
+		t.Fatalf("got %v after the scripted errors", err)
+	}
+	if got := b.Calls(DescribeLogGroups); got != 4 {
+		t.Errorf("got %d calls, want 4", got)
+	}
+
+	var notFound *types.ResourceNotFoundException
+	_, err := b.DescribeLogStreams(context.Background(), &cloudwatchlogs.DescribeLogStreamsInput{
+		LogGroupName: aws.String("missing"),
+	})
+	if !errors.As(err, &notFound) {
+		t.Errorf("got %v for a missing group", err)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	b := New(Group{Name: "g"})
+	b.Latency = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := b.DescribeLogGroups(ctx, &cloudwatchlogs.DescribeLogGroupsInput{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the call to be cancelled", err)
+	}
+}
+
+func TestManage(t *testing.T) {
+	ctx := context.Background()
+	b := New(Group{Name: "g", Streams: []Stream{{Name: "s"}, {Name: "t"}}})
+
+	out, err := b.DescribeLogGroups(ctx, &cloudwatchlogs.DescribeLogGroupsInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arn := aws.ToString(out.LogGroups[0].Arn); arn != ARN("g")+":*" {
+		t.Errorf("got arn %s, want the resource arn with a wildcard", arn)
+	}
+
+	if _, err := b.PutRetentionPolicy(ctx, &cloudwatchlogs.PutRetentionPolicyInput{
+		LogGroupName:    aws.String("g"),
+		RetentionInDays: aws.Int32(14),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.TagResource(ctx, &cloudwatchlogs.TagResourceInput{
+		ResourceArn: aws.String(ARN("g")),
+		Tags:        map[string]string{"env": "prod", "team": "orders"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.UntagResource(ctx, &cloudwatchlogs.UntagResourceInput{
+		ResourceArn: aws.String(ARN("g")),
+		TagKeys:     []string{"team"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.DeleteLogStream(ctx, &cloudwatchlogs.DeleteLogStreamInput{
+		LogGroupName:  aws.String("g"),
+		LogStreamName: aws.String("s"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	g, _ := b.Group("g")
+	if g.Retention != 14 || len(g.Tags) != 1 || g.Tags["env"] != "prod" {
+		t.Errorf("got retention %d and tags %v, want 14 days and env=prod", g.Retention, g.Tags)
+	}
+	if len(g.Streams) != 1 || g.Streams[0].Name != "t" {
+		t.Errorf("got streams %v, want only t", g.Streams)
+	}
+
+	var invalid *types.InvalidParameterException
+	_, err = b.ListTagsForResource(ctx, &cloudwatchlogs.ListTagsForResourceInput{
+		ResourceArn: aws.String(ARN("g") + ":*"),
+	})
+	if !errors.As(err, &invalid) {
+		t.Errorf("got %v listing tags by the described arn, want it rejected", err)
+	}
+
+	if _, err := b.DeleteLogGroup(ctx, &cloudwatchlogs.DeleteLogGroupInput{LogGroupName: aws.String("g")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := b.Group("g"); ok {
+		t.Error("deleted group still exists")
+	}
+}
+
+func TestFilters(t *testing.T) {
+	ctx := context.Background()
+	b := New(Group{
+		Name: "g",
+		MetricFilters: []types.MetricFilter{
+			{FilterName: aws.String("errors"), FilterPattern: aws.String("ERROR")},
+			{FilterName: aws.String("latency"), FilterPattern: aws.String("latency")},
+		},
+		SubscriptionFilters: []types.SubscriptionFilter{
+			{FilterName: aws.String("archive"), DestinationArn: aws.String("arn:aws:firehose:archive")},
+		},
+	})
+	b.PageSize = 1
+
+	metric := cloudwatchlogs.NewDescribeMetricFiltersPaginator(b, &cloudwatchlogs.DescribeMetricFiltersInput{
+		LogGroupName: aws.String("g"),
+	})
+	var names []string
+	for metric.HasMorePages() {
+		out, err := metric.NextPage(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range out.MetricFilters {
+			if aws.ToString(f.LogGroupName) != "g" {
+				t.Errorf("got group %q of %s, want g", aws.ToString(f.LogGroupName), aws.ToString(f.FilterName))
+			}
+			names = append(names, aws.ToString(f.FilterName))
+		}
+	}
+	if strings.Join(names, ",") != "errors,latency" {
+		t.Errorf("got metric filters %v, want errors and latency", names)
+	}
+
+	subscription, err := b.DescribeSubscriptionFilters(ctx, &cloudwatchlogs.DescribeSubscriptionFiltersInput{
+		LogGroupName:     aws.String("g"),
+		FilterNamePrefix: aws.String("arch"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subscription.SubscriptionFilters) != 1 {
+		t.Errorf("got %d subscription filters, want archive", len(subscription.SubscriptionFilters))
+	}
+
+	tested, err := b.TestMetricFilter(ctx, &cloudwatchlogs.TestMetricFilterInput{
+		FilterPattern:    aws.String("ERROR db"),
+		LogEventMessages: []string{"INFO db", "ERROR db timeout", "ERROR cache"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tested.Matches) != 1 || tested.Matches[0].EventNumber != 2 {
+		t.Errorf("got matches %+v, want the second message", tested.Matches)
+	}
+
+	var invalid *types.InvalidParameterException
+	_, err = b.TestMetricFilter(ctx, &cloudwatchlogs.TestMetricFilterInput{
+		FilterPattern:    aws.String("ERROR"),
+		LogEventMessages: make([]string, 51),
+	})
+	if !errors.As(err, &invalid) {
+		t.Errorf("got %v testing 51 messages, want it rejected", err)
+	}
+	_, err = b.TestMetricFilter(ctx, &cloudwatchlogs.TestMetricFilterInput{
+		FilterPattern:    aws.String(`{ $.level = }`),
+		LogEventMessages: []string{`{"level":"ERROR"}`},
+	})
+	if !errors.As(err, &invalid) {
+		t.Errorf("got %v testing an invalid pattern, want it rejected", err)
+	}
+}
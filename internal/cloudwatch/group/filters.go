@@ -0,0 +1,76 @@
+package cloudwatch
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+
+	"clviewer/internal/cloudwatch/client"
+)
+
+// maxTestedMessages is the most messages TestMetricFilter takes in a call
+const maxTestedMessages = 50
+
+// MetricFilters returns the metric filters of a log group
+func MetricFilters(ctx context.Context, cw client.API, name string) ([]types.MetricFilter, error) {
+	paginator := cloudwatchlogs.NewDescribeMetricFiltersPaginator(cw, &cloudwatchlogs.DescribeMetricFiltersInput{
+		LogGroupName: aws.String(name),
+	})
+
+	var filters []types.MetricFilter
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return filters, err
+		}
+		filters = append(filters, out.MetricFilters...)
+	}
+	return filters, nil
+}
+
+// SubscriptionFilters returns the subscription filters of a log group
+func SubscriptionFilters(ctx context.Context, cw client.API, name string) ([]types.SubscriptionFilter, error) {
+	paginator := cloudwatchlogs.NewDescribeSubscriptionFiltersPaginator(cw, &cloudwatchlogs.DescribeSubscriptionFiltersInput{
+		LogGroupName: aws.String(name),
+	})
+
+	var filters []types.SubscriptionFilter
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return filters, err
+		}
+		filters = append(filters, out.SubscriptionFilters...)
+	}
+	return filters, nil
+}
+
+// TestPattern returns the messages that match a filter pattern, testing them
+// with CloudWatch as many at a time as it takes
+func TestPattern(ctx context.Context, cw client.API, pattern string, messages []string) (map[string]bool, error) {
+	matched := map[string]bool{}
+	for start := 0; start < len(messages); start += maxTestedMessages {
+		end := start + maxTestedMessages
+		if end > len(messages) {
+			end = len(messages)
+		}
+		batch := messages[start:end]
+
+		out, err := cw.TestMetricFilter(ctx, &cloudwatchlogs.TestMetricFilterInput{
+			FilterPattern:    aws.String(pattern),
+			LogEventMessages: batch,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range out.Matches {
+			// the messages are numbered from 1 in the order they were sent
+			if i := int(match.EventNumber) - 1; i >= 0 && i < len(batch) {
+				matched[batch[i]] = true
+			}
+		}
+	}
+	return matched, nil
+}
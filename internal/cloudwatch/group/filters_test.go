@@ -0,0 +1,32 @@
+package cloudwatch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"clviewer/internal/cloudwatch/fake"
+)
+
+func TestTestPattern(t *testing.T) {
+	var messages []string
+	for i := 0; i < 120; i++ {
+		level := "INFO"
+		if i%10 == 0 {
+			level = "ERROR"
+		}
+		messages = append(messages, fmt.Sprintf("%s request %d", level, i))
+	}
+	b := fake.New()
+
+	matched, err := TestPattern(context.Background(), b, "ERROR", messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matched) != 12 || !matched["ERROR request 110"] {
+		t.Errorf("got %d matches, want the 12 errors", len(matched))
+	}
+	if calls := b.Calls(fake.TestMetricFilter); calls != 3 {
+		t.Errorf("got %d calls, want the messages tested 50 at a time", calls)
+	}
+}
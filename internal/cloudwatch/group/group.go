@@ -2,33 +2,173 @@ package cloudwatch
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"strconv"
+	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
-)
 
-func GetLogGroups(ctx context.Context, in cloudwatchlogs.DescribeLogGroupsInput) []types.LogGroup {
-	// Load the Shared AWS Configuration(~/.aws/config)
-	cfg, err := config.LoadDefaultConfig(ctx)
-	if err != nil {
-		log.Fatal(err)
-	}
+	"clviewer/internal/cloudwatch/client"
+)
 
-	// get cloudwatch client
-	cwClient := cloudwatchlogs.NewFromConfig(cfg)
-	cwPaginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(cwClient, &in)
+func GetLogGroups(
+	ctx context.Context,
+	cw client.API,
+	in cloudwatchlogs.DescribeLogGroupsInput,
+) ([]types.LogGroup, error) {
+	cwPaginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(cw, &in)
 
 	// get all the log groups via paginator
 	var logGroups []types.LogGroup
 	for cwPaginator.HasMorePages() {
 		output, err := cwPaginator.NextPage(ctx)
 		if err != nil {
-			log.Fatal(err)
+			return logGroups, err
 		}
 		logGroups = append(logGroups, output.LogGroups...)
 	}
 
-	return logGroups
+	return logGroups, nil
+}
+
+// Describe returns the log group called name
+func Describe(ctx context.Context, cw client.API, name string) (types.LogGroup, error) {
+	groups, err := GetLogGroups(ctx, cw, cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(name),
+	})
+	if err != nil {
+		return types.LogGroup{}, err
+	}
+	for _, g := range groups {
+		if aws.ToString(g.LogGroupName) == name {
+			return g, nil
+		}
+	}
+	return types.LogGroup{}, fmt.Errorf("log group %s doesn't exist", name)
+}
+
+// RetentionDays are the retention policies CloudWatch accepts, in days
+var RetentionDays = []int32{
+	1, 3, 5, 7, 14, 30, 60, 90, 120, 150, 180, 365, 400, 545, 731, 1096, 1827,
+	2192, 2557, 2922, 3288, 3653,
+}
+
+// ParseRetention parses a retention policy in days, never or 0 keep events
+// forever and are returned as 0
+func ParseRetention(s string) (int32, error) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "days"))
+	if s == "never" || s == "0" {
+		return 0, nil
+	}
+	days, err := strconv.ParseInt(s, 10, 32)
+	if err == nil {
+		for _, d := range RetentionDays {
+			if int32(days) == d {
+				return d, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("retention must be never or one of %s days", retentionList())
+}
+
+func retentionList() string {
+	days := make([]string, len(RetentionDays))
+	for i, d := range RetentionDays {
+		days[i] = strconv.Itoa(int(d))
+	}
+	return strings.Join(days, ", ")
+}
+
+// SetRetention sets how many days the events of a group are kept, 0 keeps
+// them forever
+func SetRetention(ctx context.Context, cw client.API, name string, days int32) error {
+	if days == 0 {
+		_, err := cw.DeleteRetentionPolicy(ctx, &cloudwatchlogs.DeleteRetentionPolicyInput{
+			LogGroupName: aws.String(name),
+		})
+		return err
+	}
+	_, err := cw.PutRetentionPolicy(ctx, &cloudwatchlogs.PutRetentionPolicyInput{
+		LogGroupName:    aws.String(name),
+		RetentionInDays: aws.Int32(days),
+	})
+	return err
+}
+
+// ResourceARN returns the arn tags of g are listed and changed by, which is
+// the arn it's described with without the trailing wildcard
+func ResourceARN(g types.LogGroup) string {
+	return strings.TrimSuffix(aws.ToString(g.Arn), ":*")
+}
+
+// Tags returns the tags of the group with the resource arn
+func Tags(ctx context.Context, cw client.API, arn string) (map[string]string, error) {
+	out, err := cw.ListTagsForResource(ctx, &cloudwatchlogs.ListTagsForResourceInput{
+		ResourceArn: aws.String(arn),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Tags, nil
+}
+
+// TagEdit is a change to the tags of a group
+type TagEdit struct {
+	Set    map[string]string
+	Remove []string
+}
+
+// ParseTagEdit parses space separated key=value pairs to set and -key to
+// remove a tag
+func ParseTagEdit(s string) (TagEdit, error) {
+	edit := TagEdit{Set: map[string]string{}}
+	for _, field := range strings.Fields(s) {
+		if strings.HasPrefix(field, "-") {
+			if field == "-" {
+				return edit, fmt.Errorf("missing key of tag to remove")
+			}
+			edit.Remove = append(edit.Remove, field[1:])
+			continue
+		}
+		k, v, ok := strings.Cut(field, "=")
+		if !ok || k == "" {
+			return edit, fmt.Errorf("tag %q isn't key=value or -key", field)
+		}
+		edit.Set[k] = v
+	}
+	if len(edit.Set) == 0 && len(edit.Remove) == 0 {
+		return edit, fmt.Errorf("no tags to change")
+	}
+	return edit, nil
+}
+
+// EditTags sets and removes the tags of the group with the resource arn
+func EditTags(ctx context.Context, cw client.API, arn string, edit TagEdit) error {
+	if len(edit.Set) > 0 {
+		_, err := cw.TagResource(ctx, &cloudwatchlogs.TagResourceInput{
+			ResourceArn: aws.String(arn),
+			Tags:        edit.Set,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(edit.Remove) > 0 {
+		_, err := cw.UntagResource(ctx, &cloudwatchlogs.UntagResourceInput{
+			ResourceArn: aws.String(arn),
+			TagKeys:     edit.Remove,
+		})
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a log group along with its streams and events
+func Delete(ctx context.Context, cw client.API, name string) error {
+	_, err := cw.DeleteLogGroup(ctx, &cloudwatchlogs.DeleteLogGroupInput{
+		LogGroupName: aws.String(name),
+	})
+	return err
 }
@@ -0,0 +1,61 @@
+package cloudwatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int32
+		err  bool
+	}{
+		{in: "30", want: 30},
+		{in: "30 days", want: 30},
+		{in: " 3653 ", want: 3653},
+		{in: "never", want: 0},
+		{in: "0", want: 0},
+		{in: "31", err: true},
+		{in: "-1", err: true},
+		{in: "", err: true},
+		{in: "a month", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRetention(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseRetention(%q) = %d, %v, want %d, error %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseTagEdit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want TagEdit
+		err  bool
+	}{
+		{
+			in:   "env=prod team=orders",
+			want: TagEdit{Set: map[string]string{"env": "prod", "team": "orders"}},
+		},
+		{
+			in:   "-owner env=",
+			want: TagEdit{Set: map[string]string{"env": ""}, Remove: []string{"owner"}},
+		},
+		{in: "env", err: true},
+		{in: "=prod", err: true},
+		{in: "-", err: true},
+		{in: "  ", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTagEdit(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseTagEdit(%q) returned %v", tt.in, err)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTagEdit(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
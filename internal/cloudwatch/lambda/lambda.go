@@ -0,0 +1,164 @@
+package lambda
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+var (
+	// lambda's own lines (START/END/REPORT and runtime failures) mark the
+	// request id with "RequestId:", the
+	// runtimes' own log format writes it after the timestamp, e.g.
+	// "2023-03-01T12:00:00.000Z\t<id>\tINFO\t..." or with a "[INFO]\t"
+	// prefix on python
+	requestIDRegex = regexp.MustCompile(
+		`^(?:(?:START|END|REPORT) )?RequestId: (` + uuid + `)|` +
+			`^(?:\[[A-Z]+\]\t)?\d{4}-\d{2}-\d{2}T[0-9:.]+Z[\t ](` + uuid + `)[\t ]`,
+	)
+	reportFieldRegex = regexp.MustCompile(
+		`(Duration|Billed Duration|Memory Size|Max Memory Used|Init Duration|Status): ([^\t]+)`,
+	)
+	errorRegex = regexp.MustCompile(
+		`(?i)(\[error\]|\terror\t|"level"\s*:\s*"(error|fatal)"|"errorType"|Runtime\.[A-Za-z]+Error|Traceback \(most recent call last\))`,
+	)
+)
+
+const uuid = `[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`
+
+const timeoutMarker = "Task timed out after"
+
+// Report holds the metrics lambda writes on the REPORT line at the end of
+// every invocation. Durations are in milliseconds, memory in megabytes.
+type Report struct {
+	Duration       float64
+	BilledDuration float64
+	MemorySize     int
+	MaxMemoryUsed  int
+	InitDuration   float64 // zero unless the invocation was a cold start
+	Status         string  // only set by newer runtimes, e.g. "timeout"
+}
+
+// Invocation is every event logged by a single lambda request
+type Invocation struct {
+	RequestID string
+	Events    []types.OutputLogEvent
+	Report    *Report
+	Errors    int
+	TimedOut  bool
+}
+
+// Failed returns true if the invocation logged an error or did not finish
+func (i Invocation) Failed() bool {
+	if i.Errors > 0 || i.TimedOut {
+		return true
+	}
+	return i.Report != nil && i.Report.Status != "" && i.Report.Status != "success"
+}
+
+// Timestamp returns the timestamp of the first event of the invocation
+func (i Invocation) Timestamp() int64 {
+	if len(i.Events) == 0 {
+		return 0
+	}
+	return aws.ToInt64(i.Events[0].Timestamp)
+}
+
+// Group splits log events into invocations keyed on their request ID.
+//
+// Lines that carry a request ID (START/END/REPORT and the default runtime
+// log format) are matched on it, anything else is attached to the
+// invocation of the most recent line that did. Events logged before the first request ID
+// (e.g. during init) are grouped into an invocation with no request ID.
+func Group(events []types.OutputLogEvent) []Invocation {
+	var (
+		invocations []Invocation
+		index       = map[string]int{}
+		current     = -1
+	)
+
+	for _, e := range events {
+		msg := aws.ToString(e.Message)
+		id := requestID(msg)
+
+		i, ok := index[id]
+		switch {
+		case id == "" && current >= 0:
+			i = current
+		case ok:
+			current = i
+		default:
+			invocations = append(invocations, Invocation{RequestID: id})
+			i = len(invocations) - 1
+			index[id] = i
+			current = i
+		}
+
+		inv := &invocations[i]
+		inv.Events = append(inv.Events, e)
+
+		switch {
+		case strings.HasPrefix(msg, "REPORT RequestId:"):
+			r := ParseReport(msg)
+			inv.Report = &r
+			if r.Status == "timeout" {
+				inv.TimedOut = true
+			}
+		case strings.Contains(msg, timeoutMarker):
+			inv.TimedOut = true
+		case IsError(msg):
+			inv.Errors++
+		}
+	}
+
+	return invocations
+}
+
+// ParseReport parses the fields of a lambda REPORT line, fields that are not
+// present are left as their zero value
+func ParseReport(line string) Report {
+	var r Report
+	for _, match := range reportFieldRegex.FindAllStringSubmatch(line, -1) {
+		value := strings.TrimSpace(match[2])
+		switch match[1] {
+		case "Duration":
+			r.Duration = parseNumber(value)
+		case "Billed Duration":
+			r.BilledDuration = parseNumber(value)
+		case "Memory Size":
+			r.MemorySize = int(parseNumber(value))
+		case "Max Memory Used":
+			r.MaxMemoryUsed = int(parseNumber(value))
+		case "Init Duration":
+			r.InitDuration = parseNumber(value)
+		case "Status":
+			r.Status = value
+		}
+	}
+	return r
+}
+
+// IsError returns true if the log line looks like it was logged at error level
+func IsError(line string) bool {
+	return errorRegex.MatchString(line)
+}
+
+// requestID returns the request id lambda or the runtime marked the line
+// with, ids the application logs itself are ignored
+func requestID(line string) string {
+	match := requestIDRegex.FindStringSubmatch(line)
+	if match == nil {
+		return ""
+	}
+	return match[1] + match[2]
+}
+
+// parseNumber parses the leading number of values such as "2.16 ms"
+func parseNumber(value string) float64 {
+	value, _, _ = strings.Cut(value, " ")
+	n, _ := strconv.ParseFloat(value, 64)
+	return n
+}
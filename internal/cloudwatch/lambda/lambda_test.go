@@ -0,0 +1,263 @@
+package lambda
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+const (
+	id1 = "6f1d7e2a-3b4c-4d5e-8f90-a1b2c3d4e5f6"
+	id2 = "0a9b8c7d-6e5f-4a3b-9c2d-1e0f9a8b7c6d"
+	id3 = "11111111-2222-4333-8444-555555555555"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"start", "START RequestId: " + id1 + " Version: $LATEST", id1},
+		{"end", "END RequestId: " + id1, id1},
+		{"report", "REPORT RequestId: " + id1 + "\tDuration: 2.16 ms", id1},
+		{"runtime error", "RequestId: " + id1 + " Error: Runtime exited with error: signal: killed", id1},
+		{"node", "2023-03-01T12:00:00.000Z\t" + id1 + "\tINFO\thandled order", id1},
+		{"python", "[ERROR]\t2023-03-01T12:00:00.000Z\t" + id1 + "\tsomething broke", id1},
+		{"timeout", "2023-03-01T12:00:03.000Z " + id1 + " Task timed out after 3.00 seconds", id1},
+		{"plain", "handled order", ""},
+		{"logged by the application", "created order " + id2, ""},
+		{"logged by the application after the prefix", "2023-03-01T12:00:00.000Z\t" + id1 + "\tINFO\tcreated order " + id2, id1},
+		{"json", `{"orderId":"` + id2 + `","level":"info"}`, ""},
+		{"upper case", "START RequestId: " + "6F1D7E2A-3B4C-4D5E-8F90-A1B2C3D4E5F6", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestID(tt.line); got != tt.want {
+				t.Errorf("requestID(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReport(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want Report
+	}{
+		{
+			"warm",
+			"REPORT RequestId: " + id1 + "\tDuration: 2.16 ms\tBilled Duration: 3 ms\tMemory Size: 128 MB\tMax Memory Used: 64 MB\t",
+			Report{Duration: 2.16, BilledDuration: 3, MemorySize: 128, MaxMemoryUsed: 64},
+		},
+		{
+			"cold start",
+			"REPORT RequestId: " + id1 + "\tDuration: 100.50 ms\tBilled Duration: 101 ms\tMemory Size: 512 MB\tMax Memory Used: 80 MB\tInit Duration: 250.75 ms\t",
+			Report{Duration: 100.5, BilledDuration: 101, MemorySize: 512, MaxMemoryUsed: 80, InitDuration: 250.75},
+		},
+		{
+			"timed out",
+			"REPORT RequestId: " + id1 + "\tDuration: 3000.00 ms\tBilled Duration: 3000 ms\tMemory Size: 128 MB\tMax Memory Used: 70 MB\tStatus: timeout",
+			Report{Duration: 3000, BilledDuration: 3000, MemorySize: 128, MaxMemoryUsed: 70, Status: "timeout"},
+		},
+		{"no fields", "REPORT RequestId: " + id1, Report{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseReport(tt.line); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	report := func(id, status string) string {
+		line := "REPORT RequestId: " + id + "\tDuration: 1.00 ms\tBilled Duration: 1 ms\tMemory Size: 128 MB\tMax Memory Used: 64 MB\t"
+		if status != "" {
+			line += "Status: " + status
+		}
+		return line
+	}
+
+	// want describes an invocation as its request id followed by the
+	// messages grouped into it
+	type want struct {
+		id       string
+		messages []string
+		errors   int
+		timedOut bool
+		failed   bool
+	}
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  []want
+	}{
+		{
+			"sequential",
+			[]string{
+				"START RequestId: " + id1,
+				"2023-03-01T12:00:00.000Z\t" + id1 + "\tINFO\tone",
+				"END RequestId: " + id1,
+				report(id1, ""),
+				"START RequestId: " + id2,
+				"END RequestId: " + id2,
+				report(id2, ""),
+			},
+			[]want{
+				{id: id1, messages: []string{
+					"START RequestId: " + id1,
+					"2023-03-01T12:00:00.000Z\t" + id1 + "\tINFO\tone",
+					"END RequestId: " + id1,
+					report(id1, ""),
+				}},
+				{id: id2, messages: []string{
+					"START RequestId: " + id2,
+					"END RequestId: " + id2,
+					report(id2, ""),
+				}},
+			},
+		},
+		{
+			"init lines before the first request",
+			[]string{
+				"INIT_START Runtime Version: nodejs:18.v5",
+				"loading config",
+				"START RequestId: " + id1,
+				"plain line",
+			},
+			[]want{
+				{id: "", messages: []string{"INIT_START Runtime Version: nodejs:18.v5", "loading config"}},
+				{id: id1, messages: []string{"START RequestId: " + id1, "plain line"}},
+			},
+		},
+		{
+			"interleaved",
+			[]string{
+				"START RequestId: " + id1,
+				"START RequestId: " + id2,
+				"2023-03-01T12:00:00.000Z\t" + id1 + "\tINFO\tfor one",
+				"untagged after one",
+				"2023-03-01T12:00:00.000Z\t" + id2 + "\tINFO\tfor two",
+				"untagged after two",
+			},
+			[]want{
+				{id: id1, messages: []string{
+					"START RequestId: " + id1,
+					"2023-03-01T12:00:00.000Z\t" + id1 + "\tINFO\tfor one",
+					"untagged after one",
+				}},
+				{id: id2, messages: []string{
+					"START RequestId: " + id2,
+					"2023-03-01T12:00:00.000Z\t" + id2 + "\tINFO\tfor two",
+					"untagged after two",
+				}},
+			},
+		},
+		{
+			"untagged lines after init go to the current invocation",
+			[]string{
+				"loading config",
+				"START RequestId: " + id1,
+				"2023-03-01T12:00:00.000Z\t" + id1 + "\tINFO\ttagged",
+				"untagged",
+			},
+			[]want{
+				{id: "", messages: []string{"loading config"}},
+				{id: id1, messages: []string{
+					"START RequestId: " + id1,
+					"2023-03-01T12:00:00.000Z\t" + id1 + "\tINFO\ttagged",
+					"untagged",
+				}},
+			},
+		},
+		{
+			"ids logged by the application",
+			[]string{
+				"START RequestId: " + id1,
+				"2023-03-01T12:00:00.000Z\t" + id1 + "\tINFO\tcreated order " + id3,
+				"order " + id3 + " shipped",
+			},
+			[]want{
+				{id: id1, messages: []string{
+					"START RequestId: " + id1,
+					"2023-03-01T12:00:00.000Z\t" + id1 + "\tINFO\tcreated order " + id3,
+					"order " + id3 + " shipped",
+				}},
+			},
+		},
+		{
+			"error level only",
+			[]string{
+				"START RequestId: " + id1,
+				"2023-03-01T12:00:00.000Z\t" + id1 + "\tINFO\tretried after ERROR from upstream",
+				report(id1, ""),
+			},
+			[]want{{id: id1}},
+		},
+		{
+			"failures",
+			[]string{
+				"START RequestId: " + id1,
+				"[ERROR]\t2023-03-01T12:00:00.000Z\t" + id1 + "\tsomething broke",
+				`{"errorType":"Error","errorMessage":"boom"}`,
+				report(id1, ""),
+				"START RequestId: " + id2,
+				"2023-03-01T12:00:03.000Z " + id2 + " Task timed out after 3.00 seconds",
+				report(id2, "timeout"),
+				"START RequestId: " + id3,
+				report(id3, "error"),
+			},
+			[]want{
+				{id: id1, errors: 2, failed: true},
+				{id: id2, timedOut: true, failed: true},
+				{id: id3, failed: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []types.OutputLogEvent
+			for i, line := range tt.lines {
+				events = append(events, types.OutputLogEvent{
+					Timestamp: aws.Int64(int64(i)),
+					Message:   aws.String(line),
+				})
+			}
+
+			invocations := Group(events)
+			if len(invocations) != len(tt.want) {
+				t.Fatalf("got %d invocations, want %d", len(invocations), len(tt.want))
+			}
+			for i, inv := range invocations {
+				w := tt.want[i]
+				if inv.RequestID != w.id {
+					t.Errorf("invocation %d has request id %q, want %q", i, inv.RequestID, w.id)
+				}
+				if w.messages != nil {
+					var messages []string
+					for _, e := range inv.Events {
+						messages = append(messages, aws.ToString(e.Message))
+					}
+					if !reflect.DeepEqual(messages, w.messages) {
+						t.Errorf("invocation %d has messages %q, want %q", i, messages, w.messages)
+					}
+				}
+				if inv.Errors != w.errors || inv.TimedOut != w.timedOut || inv.Failed() != w.failed {
+					t.Errorf(
+						"invocation %d has %d errors, timed out %t, failed %t, want %d, %t, %t",
+						i, inv.Errors, inv.TimedOut, inv.Failed(), w.errors, w.timedOut, w.failed,
+					)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package pattern
+
+import "strings"
+
+// A space-delimited pattern names the fields of a message in brackets, each
+// optionally with a condition on its value, and ... for any number of
+// fields. Fields are separated by spaces, text in quotes or brackets is a
+// single field.
+
+// slot is a field of the pattern, or an ellipsis
+type slot struct {
+	ellipsis bool
+	cond     condition
+}
+
+func parseDelimited(src string) (func(string) bool, error) {
+	s := &scanner{src: src}
+	var names []string
+	p := &exprParser{scanner: s, bare: true}
+	p.selector = func() (string, error) {
+		name, err := s.fieldName()
+		names = append(names, name)
+		return name, err
+	}
+
+	s.consume("[")
+	var slots []slot
+	for {
+		if s.consume("...") {
+			slots = append(slots, slot{ellipsis: true})
+		} else {
+			s.skipSpace()
+			start := s.pos
+			names = nil
+			cond, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range names[1:] {
+				if name != names[0] {
+					return nil, s.errorAt(start, "the condition of field %s can't compare field %s", names[0], name)
+				}
+			}
+			slots = append(slots, slot{cond: cond})
+		}
+
+		if s.consume(",") {
+			continue
+		}
+		if s.consume("]") {
+			break
+		}
+		s.skipSpace()
+		return nil, s.errorAt(s.pos, "expected , or ] instead of %s", s.describe())
+	}
+	if s.skipSpace(); !s.done() {
+		return nil, s.errorAt(s.pos, "unexpected %s after ]", s.describe())
+	}
+
+	return func(message string) bool {
+		return matchFields(slots, splitFields(message))
+	}, nil
+}
+
+// fieldName reads the name of a field
+func (s *scanner) fieldName() (string, error) {
+	start := s.pos
+	for !s.done() && isNameByte(s.peek()) {
+		s.pos++
+	}
+	if s.pos == start {
+		return "", s.errorAt(start, "expected a field name instead of %s", s.describe())
+	}
+	return s.src[start:s.pos], nil
+}
+
+// matchFields returns true if the fields match the slots one for one, with
+// an ellipsis matching any number of fields
+func matchFields(slots []slot, fields []string) bool {
+	if len(slots) == 0 {
+		return len(fields) == 0
+	}
+	if slots[0].ellipsis {
+		for i := 0; i <= len(fields); i++ {
+			if matchFields(slots[1:], fields[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(fields) == 0 {
+		return false
+	}
+	value := field(fields[0])
+	get := func(string) (interface{}, bool) { return value, true }
+	return slots[0].cond(get) && matchFields(slots[1:], fields[1:])
+}
+
+// splitFields splits a message at spaces, keeping text in quotes or brackets
+// together without them
+func splitFields(message string) []string {
+	var fields []string
+	s := strings.TrimSpace(message)
+	for s != "" {
+		end := strings.IndexAny(s, " \t\n")
+		if end < 0 {
+			end = len(s)
+		}
+		value := s[:end]
+
+		var closing string
+		switch s[0] {
+		case '"':
+			closing = `"`
+		case '[':
+			closing = "]"
+		}
+		if i := strings.Index(s[1:], closing); closing != "" && i >= 0 {
+			value, end = s[1:1+i], 2+i
+		}
+		fields = append(fields, value)
+		s = strings.TrimLeft(s[end:], " \t\n")
+	}
+	return fields
+}
@@ -0,0 +1,296 @@
+package pattern
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSON and space-delimited patterns share their conditions. A condition
+// compares the value of a selector with a literal, and conditions are joined
+// with && and ||, which binds looser, or grouped in parentheses.
+
+// condition returns true if the values get returns satisfy it
+type condition func(get getter) bool
+
+// getter returns the value of a selector, false if there's no such value
+type getter func(selector string) (interface{}, bool)
+
+// field is the value of a space-delimited field, compared as a number when
+// it is one
+type field string
+
+// literal is the value a selector is compared with
+type literal struct {
+	text     string
+	number   float64
+	isNumber bool
+	re       *regexp.Regexp
+}
+
+type exprParser struct {
+	*scanner
+	// selector reads a selector, a JSON path or the name of a field
+	selector func() (string, error)
+	// bare allows a selector without a comparison, which is always true
+	bare bool
+}
+
+func (p *exprParser) or() (condition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(get getter) bool { return l(get) || right(get) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) and() (condition, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(get getter) bool { return l(get) && right(get) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) unary() (condition, error) {
+	if !p.consume("(") {
+		return p.comparison()
+	}
+	c, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.consume(")") {
+		return nil, p.errorAt(p.pos, "expected ) instead of %s", p.describe())
+	}
+	return c, nil
+}
+
+func (p *exprParser) comparison() (condition, error) {
+	p.skipSpace()
+	sel, err := p.selector()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.keyword("IS"):
+		var want interface{}
+		switch {
+		case p.keyword("NULL"):
+			want = nil
+		case p.keyword("TRUE"):
+			want = true
+		case p.keyword("FALSE"):
+			want = false
+		default:
+			p.skipSpace()
+			return nil, p.errorAt(p.pos, "expected NULL, TRUE or FALSE after IS instead of %s", p.describe())
+		}
+		return func(get getter) bool {
+			v, ok := get(sel)
+			return ok && v == want
+		}, nil
+	case p.keyword("NOT"):
+		if !p.keyword("EXISTS") {
+			p.skipSpace()
+			return nil, p.errorAt(p.pos, "expected EXISTS after NOT instead of %s", p.describe())
+		}
+		return func(get getter) bool {
+			_, ok := get(sel)
+			return !ok
+		}, nil
+	}
+
+	op := p.operator()
+	if op == "" {
+		if p.bare {
+			return func(getter) bool { return true }, nil
+		}
+		return nil, p.errorAt(p.pos, "expected a comparison after %s instead of %s", sel, p.describe())
+	}
+	p.skipSpace()
+	start := p.pos
+	lit, err := p.literal()
+	if err != nil {
+		return nil, err
+	}
+	if op != "=" && op != "!=" && !lit.isNumber {
+		return nil, p.errorAt(start, "%s needs a number, not %q", op, lit.text)
+	}
+	return compare(sel, op, lit), nil
+}
+
+// keyword skips the spaces before word and returns true if it's next, in
+// any case, moving past it
+func (p *exprParser) keyword(word string) bool {
+	p.skipSpace()
+	end := p.pos + len(word)
+	if end > len(p.src) || !strings.EqualFold(p.src[p.pos:end], word) {
+		return false
+	}
+	if end < len(p.src) && isNameByte(p.src[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+// operator reads a comparison operator, empty if there isn't one
+func (p *exprParser) operator() string {
+	for _, op := range []string{"!=", ">=", "<=", "=", ">", "<"} {
+		if p.consume(op) {
+			return op
+		}
+	}
+	p.skipSpace()
+	return ""
+}
+
+// literal reads a quoted string, %regular expression%, number or unquoted
+// string. Strings can contain * wildcards.
+func (p *exprParser) literal() (literal, error) {
+	p.skipSpace()
+	start := p.pos
+	switch p.peek() {
+	case '"':
+		text, err := p.quoted()
+		return literal{text: text}, err
+	case '%':
+		expr, err := p.regex()
+		if err != nil {
+			return literal{}, err
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return literal{}, p.errorAt(start, "invalid regular expression: %s", err)
+		}
+		return literal{text: expr, re: re}, nil
+	}
+
+	for !p.done() && !isSpace(p.peek()) && !strings.ContainsRune("&|(){}[],", rune(p.peek())) {
+		p.pos++
+	}
+	text := p.src[start:p.pos]
+	if text == "" {
+		return literal{}, p.errorAt(start, "expected a value instead of %s", p.describe())
+	}
+
+	lit := literal{text: text}
+	if strings.ContainsAny(text[:1], "0123456789+-.") {
+		if n, err := strconv.ParseFloat(text, 64); err == nil {
+			lit.number, lit.isNumber = n, true
+		}
+	}
+	return lit, nil
+}
+
+func compare(sel, op string, lit literal) condition {
+	return func(get getter) bool {
+		v, ok := get(sel)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "=":
+			return equal(v, lit)
+		case "!=":
+			return !equal(v, lit)
+		}
+
+		n, ok := number(v)
+		if !ok {
+			return false
+		}
+		switch op {
+		case ">":
+			return n > lit.number
+		case ">=":
+			return n >= lit.number
+		case "<":
+			return n < lit.number
+		default:
+			return n <= lit.number
+		}
+	}
+}
+
+func equal(v interface{}, lit literal) bool {
+	if lit.re != nil {
+		s, ok := text(v)
+		return ok && lit.re.MatchString(s)
+	}
+	if n, ok := number(v); ok && lit.isNumber {
+		return n == lit.number
+	}
+	if b, ok := v.(bool); ok {
+		return strconv.FormatBool(b) == lit.text
+	}
+	s, ok := text(v)
+	return ok && glob(lit.text, s)
+}
+
+// number returns v as a number, if it's a JSON number or a field that's a
+// number
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case field:
+		n, err := strconv.ParseFloat(string(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// text returns v as a string, if it's a JSON string or a field
+func text(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case field:
+		return string(v), true
+	}
+	return "", false
+}
+
+// glob returns true if s matches pattern, where * matches any text
+func glob(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return s == pattern
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// isNameByte returns true if c can be part of a selector or keyword
+func isNameByte(c byte) bool {
+	return !isSpace(c) && !strings.ContainsRune(`=!<>&|(){}[],."%$`, rune(c))
+}
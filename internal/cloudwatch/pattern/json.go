@@ -0,0 +1,116 @@
+package pattern
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// A JSON pattern is a condition in braces on the fields of messages that are
+// JSON objects, selected like $.request.headers[0]. Other messages never
+// match.
+
+// step is a step of a selector, into a field of an object or an element of
+// an array
+type step struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+func parseJSON(src string) (func(string) bool, error) {
+	s := &scanner{src: src}
+	steps := map[string][]step{}
+	p := &exprParser{scanner: s}
+	p.selector = func() (string, error) {
+		sel, path, err := s.jsonSelector()
+		steps[sel] = path
+		return sel, err
+	}
+
+	s.consume("{")
+	cond, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !s.consume("}") {
+		return nil, s.errorAt(s.pos, "expected &&, || or } instead of %s", s.describe())
+	}
+	if s.skipSpace(); !s.done() {
+		return nil, s.errorAt(s.pos, "unexpected %s after }", s.describe())
+	}
+
+	return func(message string) bool {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(message), &doc); err != nil {
+			return false
+		}
+		return cond(func(sel string) (interface{}, bool) {
+			return resolve(doc, steps[sel])
+		})
+	}, nil
+}
+
+// jsonSelector reads a selector, such as $.level or $.items[0].id
+func (s *scanner) jsonSelector() (string, []step, error) {
+	start := s.pos
+	if s.peek() != '$' {
+		return "", nil, s.errorAt(start, "expected a selector like $.field instead of %s", s.describe())
+	}
+	s.pos++
+
+	var path []step
+	for {
+		switch s.peek() {
+		case '.':
+			s.pos++
+			nameStart := s.pos
+			for !s.done() && isNameByte(s.peek()) {
+				s.pos++
+			}
+			if s.pos == nameStart {
+				return "", nil, s.errorAt(s.pos, "expected a field name after . instead of %s", s.describe())
+			}
+			path = append(path, step{name: s.src[nameStart:s.pos]})
+		case '[':
+			s.pos++
+			indexStart := s.pos
+			for !s.done() && s.peek() >= '0' && s.peek() <= '9' {
+				s.pos++
+			}
+			index, err := strconv.Atoi(s.src[indexStart:s.pos])
+			if err != nil || s.peek() != ']' {
+				return "", nil, s.errorAt(indexStart, "expected an array index like [0]")
+			}
+			s.pos++
+			path = append(path, step{index: index, isIndex: true})
+		default:
+			if len(path) == 0 {
+				return "", nil, s.errorAt(s.pos, "expected a field after $ instead of %s", s.describe())
+			}
+			return s.src[start:s.pos], path, nil
+		}
+	}
+}
+
+// resolve returns the value at path in doc, false if there's none
+func resolve(doc interface{}, path []step) (interface{}, bool) {
+	v := doc
+	for _, st := range path {
+		if st.isIndex {
+			array, ok := v.([]interface{})
+			if !ok || st.index >= len(array) {
+				return nil, false
+			}
+			v = array[st.index]
+			continue
+		}
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = object[st.name]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
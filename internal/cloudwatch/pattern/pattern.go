@@ -0,0 +1,150 @@
+// Package pattern matches messages against CloudWatch Logs filter patterns
+// without calling CloudWatch, so loaded events can be filtered instantly and
+// patterns checked before they're sent. It understands the three kinds of
+// pattern CloudWatch does:
+//
+//   - terms, e.g. ERROR "timed out" ?WARN -healthcheck %[0-9]{3}%
+//   - JSON selectors, e.g. { $.level = "ERROR" && $.latency > 100 }
+//   - space-delimited fields, e.g. [ip, user, ..., status = 4*, bytes > 1000]
+package pattern
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pattern is a parsed filter pattern
+type Pattern struct {
+	source string
+	match  func(message string) bool
+}
+
+// Parse parses a filter pattern, an empty pattern matches every message
+func Parse(s string) (Pattern, error) {
+	p := Pattern{source: s}
+	var err error
+	switch trimmed := strings.TrimSpace(s); {
+	case trimmed == "":
+		p.match = func(string) bool { return true }
+	case trimmed[0] == '{':
+		p.match, err = parseJSON(s)
+	case trimmed[0] == '[':
+		p.match, err = parseDelimited(s)
+	default:
+		p.match, err = parseTerms(s)
+	}
+	if err != nil {
+		return Pattern{}, err
+	}
+	return p, nil
+}
+
+// Match returns true if message matches the pattern, the zero Pattern
+// matches every message
+func (p Pattern) Match(message string) bool {
+	if p.match == nil {
+		return true
+	}
+	return p.match(message)
+}
+
+func (p Pattern) String() string {
+	return p.source
+}
+
+// SyntaxError is why a pattern couldn't be parsed, and where
+type SyntaxError struct {
+	Offset int // in bytes from the start of the pattern
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Msg, e.Offset+1)
+}
+
+// scanner reads a pattern a byte at a time
+type scanner struct {
+	src string
+	pos int
+}
+
+func (s *scanner) done() bool {
+	return s.pos >= len(s.src)
+}
+
+// peek returns the next byte, 0 at the end of the pattern
+func (s *scanner) peek() byte {
+	if s.done() {
+		return 0
+	}
+	return s.src[s.pos]
+}
+
+func (s *scanner) skipSpace() {
+	for !s.done() && isSpace(s.src[s.pos]) {
+		s.pos++
+	}
+}
+
+// consume skips the spaces before token and returns true if it's next,
+// moving past it
+func (s *scanner) consume(token string) bool {
+	s.skipSpace()
+	if strings.HasPrefix(s.src[s.pos:], token) {
+		s.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// quoted reads a double quoted string, the scanner is at its opening quote
+func (s *scanner) quoted() (string, error) {
+	start := s.pos
+	s.pos++
+	var b strings.Builder
+	for !s.done() {
+		c := s.src[s.pos]
+		s.pos++
+		switch {
+		case c == '"':
+			return b.String(), nil
+		case c == '\\' && !s.done():
+			b.WriteByte(s.src[s.pos])
+			s.pos++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", s.errorAt(start, "unterminated quote")
+}
+
+// regex reads a %regex%, the scanner is at its opening %
+func (s *scanner) regex() (string, error) {
+	start := s.pos
+	end := strings.IndexByte(s.src[start+1:], '%')
+	if end < 0 {
+		return "", s.errorAt(start, "unterminated regular expression")
+	}
+	s.pos = start + 1 + end + 1
+	return s.src[start+1 : start+1+end], nil
+}
+
+func (s *scanner) errorAt(offset int, format string, args ...interface{}) error {
+	return &SyntaxError{Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+// describe returns what's next in the pattern for an error message
+func (s *scanner) describe() string {
+	if s.done() {
+		return "end of pattern"
+	}
+	rest := s.src[s.pos:]
+	if i := strings.IndexFunc(rest, func(r rune) bool { return r < 128 && isSpace(byte(r)) }); i > 0 {
+		rest = rest[:i]
+	}
+	return fmt.Sprintf("%q", rest)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,141 @@
+package pattern_test
+
+import (
+	"errors"
+	"testing"
+
+	"clviewer/internal/cloudwatch/pattern"
+)
+
+const (
+	apacheLog = `127.0.0.1 - frank [10/Oct/2000:13:25:15 -0700] "GET /apache_pb.gif HTTP/1.0" 404 1534`
+	jsonLog   = `{"level":"ERROR","latency":250,"user":{"id":"u-1","admin":false},"tags":["api","v2"],"trace":null}`
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		message string
+		want    bool
+	}{
+		// terms
+		{"", "anything", true},
+		{"ERROR", "ERROR db timeout", true},
+		{"ERROR", "error db timeout", false},
+		{"ERROR timeout", "ERROR db timeout", true},
+		{"ERROR timeout", "ERROR db refused", false},
+		{`"db timeout"`, "ERROR db timeout", true},
+		{`"db timeout"`, "ERROR timeout db", false},
+		{`"say \"hi\""`, `they say "hi"`, true},
+		{"?ERROR ?WARN", "WARN disk", true},
+		{"?ERROR ?WARN", "INFO disk", false},
+		{"disk ?ERROR ?WARN", "WARN disk", true},
+		{"disk ?ERROR ?WARN", "WARN cpu", false},
+		{"ERROR -healthcheck", "ERROR in healthcheck", false},
+		{"ERROR -healthcheck", "ERROR in orders", true},
+		{"-healthcheck", "GET /orders", true},
+		{`?"timed out" ?refused`, "request timed out", true},
+		{"%[0-9]{3}ms%", "took 250ms", true},
+		{"%[0-9]{3}ms%", "took 25ms", false},
+		{"ERROR %user [0-9]+%", "ERROR for user 12", true},
+
+		// JSON
+		{`{ $.level = "ERROR" }`, jsonLog, true},
+		{`{ $.level = ERROR }`, jsonLog, true},
+		{`{ $.level = "INFO" }`, jsonLog, false},
+		{`{ $.level = "ERR*" }`, jsonLog, true},
+		{`{ $.level = *RO* }`, jsonLog, true},
+		{`{ $.level != "INFO" }`, jsonLog, true},
+		{`{ $.level = "ERROR" && $.latency > 100 }`, jsonLog, true},
+		{`{ $.level = "ERROR" && $.latency > 300 }`, jsonLog, false},
+		{`{ $.level = "INFO" || $.latency >= 250 }`, jsonLog, true},
+		{`{ $.latency < 250 }`, jsonLog, false},
+		{`{ $.latency <= 250 }`, jsonLog, true},
+		{`{ $.latency = 250 }`, jsonLog, true},
+		{`{ $.latency = 2.5e2 }`, jsonLog, true},
+		{`{ ($.level = "INFO" || $.level = "ERROR") && $.user.id = "u-1" }`, jsonLog, true},
+		{`{ $.level = "INFO" || $.level = "ERROR" && $.latency > 300 }`, jsonLog, false},
+		{`{ $.user.admin IS FALSE }`, jsonLog, true},
+		{`{ $.user.admin IS TRUE }`, jsonLog, false},
+		{`{ $.trace IS NULL }`, jsonLog, true},
+		{`{ $.level IS NULL }`, jsonLog, false},
+		{`{ $.span NOT EXISTS }`, jsonLog, true},
+		{`{ $.trace NOT EXISTS }`, jsonLog, false},
+		{`{ $.tags[1] = "v2" }`, jsonLog, true},
+		{`{ $.tags[2] = "v2" }`, jsonLog, false},
+		{`{ $.missing = "x" }`, jsonLog, false},
+		{`{ $.missing != "x" }`, jsonLog, false},
+		{`{ $.latency > 100 }`, `{"latency":"slow"}`, false},
+		{`{ $.user.id = %^u-[0-9]+$% }`, jsonLog, true},
+		{`{ $.level = "ERROR" }`, "ERROR not json", false},
+		{`{ $.level = "ERROR" }`, `["ERROR"]`, false},
+
+		// space-delimited
+		{"[ip, user, username, timestamp, request, status_code, bytes]", apacheLog, true},
+		{"[ip, user, username, timestamp, request, status_code]", apacheLog, false},
+		{"[ip, user, username, timestamp, request, status_code = 404, bytes]", apacheLog, true},
+		{"[ip, user, username, timestamp, request, status_code = 200, bytes]", apacheLog, false},
+		{"[ip, user, username, timestamp, request, status_code = 4*, bytes]", apacheLog, true},
+		{"[ip, user, username, timestamp, request = *.gif*, status_code, bytes]", apacheLog, true},
+		{"[ip, user, username, timestamp, request = \"GET *\", ...]", apacheLog, true},
+		{"[ip = 127.0.0.*, ...]", apacheLog, true},
+		{"[ip = 10.*, ...]", apacheLog, false},
+		{"[..., status_code >= 400 && status_code < 500, bytes > 1000]", apacheLog, true},
+		{"[..., status_code = 200 || status_code = 404, bytes]", apacheLog, true},
+		{"[..., timestamp = 10/Oct/2000*, ...]", apacheLog, true},
+		{"[...]", apacheLog, true},
+		{"[..., bytes < 1000]", apacheLog, false},
+		{"[a, b]", "one", false},
+		{"[a, b]", "  one   two ", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.message, func(t *testing.T) {
+			p, err := pattern.Parse(tt.pattern)
+			if err != nil {
+				t.Fatalf("Parse(%q) error %v", tt.pattern, err)
+			}
+			if got := p.Match(tt.message); got != tt.want {
+				t.Errorf("%q matching %q = %t, want %t", tt.pattern, tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		column  int
+	}{
+		{`"unterminated`, 1},
+		{`ERROR ?`, 7},
+		{`ERR"OR`, 4},
+		{`%[0-9%`, 1},
+		{`{ $.level = "ERROR" `, 21},
+		{`{ level = "ERROR" }`, 3},
+		{`{ $.level "ERROR" }`, 11},
+		{`{ $.latency > fast }`, 15},
+		{`{ $.level = }`, 13},
+		{`{ $.level IS MAYBE }`, 14},
+		{`{ $.level NOT THERE }`, 15},
+		{`{ ($.level = "ERROR" }`, 22},
+		{`{ $.tags[x] = "api" }`, 10},
+		{`{ $.level = "ERROR" } extra`, 23},
+		{`[ip, user`, 10},
+		{`[ip, user = a && ip = b]`, 6},
+		{`[ip, , user]`, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			_, err := pattern.Parse(tt.pattern)
+			var syntax *pattern.SyntaxError
+			if !errors.As(err, &syntax) {
+				t.Fatalf("Parse(%q) error %v, want a syntax error", tt.pattern, err)
+			}
+			if syntax.Offset+1 != tt.column {
+				t.Errorf("Parse(%q) error %q, want it at column %d", tt.pattern, err, tt.column)
+			}
+		})
+	}
+}
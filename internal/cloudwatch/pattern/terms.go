@@ -0,0 +1,91 @@
+package pattern
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A terms pattern matches messages that contain every term, none of the
+// terms excluded with -, and at least one of the terms marked with ? if
+// there are any. Terms are case sensitive and can be quoted to include
+// spaces or written as %regular expressions%.
+
+func parseTerms(src string) (func(string) bool, error) {
+	s := &scanner{src: src}
+	var all, any, none []func(string) bool
+	for s.skipSpace(); !s.done(); s.skipSpace() {
+		list := &all
+		switch s.peek() {
+		case '?':
+			list = &any
+			s.pos++
+		case '-':
+			list = &none
+			s.pos++
+		}
+
+		term, err := s.term()
+		if err != nil {
+			return nil, err
+		}
+		*list = append(*list, term)
+	}
+
+	return func(message string) bool {
+		for _, term := range all {
+			if !term(message) {
+				return false
+			}
+		}
+		for _, term := range none {
+			if term(message) {
+				return false
+			}
+		}
+		for _, term := range any {
+			if term(message) {
+				return true
+			}
+		}
+		return len(any) == 0
+	}, nil
+}
+
+// term reads a word, quoted phrase or regular expression
+func (s *scanner) term() (func(string) bool, error) {
+	start := s.pos
+	switch c := s.peek(); {
+	case c == 0 || isSpace(c):
+		return nil, s.errorAt(start-1, "expected a term after %q", s.src[start-1:start])
+	case c == '"':
+		phrase, err := s.quoted()
+		if err != nil {
+			return nil, err
+		}
+		return contains(phrase), nil
+	case c == '%':
+		expr, err := s.regex()
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, s.errorAt(start, "invalid regular expression: %s", err)
+		}
+		return re.MatchString, nil
+	}
+
+	for !s.done() && !isSpace(s.peek()) {
+		if s.peek() == '"' {
+			return nil, s.errorAt(s.pos, "quote inside a term, quote the whole term instead")
+		}
+		s.pos++
+	}
+	return contains(s.src[start:s.pos]), nil
+}
+
+func contains(term string) func(string) bool {
+	return func(message string) bool {
+		return strings.Contains(message, term)
+	}
+}
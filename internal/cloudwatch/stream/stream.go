@@ -2,12 +2,20 @@ package stream
 
 import (
 	"context"
-	"log"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+
+	"clviewer/internal/cloudwatch/client"
+)
+
+const (
+	// DefaultPageSize is the number of streams fetched at a time when no page
+	// size is given
+	DefaultPageSize = 50
+	// MaxPageSize is the most streams CloudWatch returns at a time
+	MaxPageSize = 50
 )
 
 type Paginator struct {
@@ -15,27 +23,23 @@ type Paginator struct {
 	streamsPaginator *cloudwatchlogs.DescribeLogStreamsPaginator
 }
 
-func New(ctx context.Context, logGroupName string) Paginator {
-	cfg, err := config.LoadDefaultConfig(ctx)
-	if err != nil {
-		log.Fatal(err)
+// New pages through the streams of a group, most recently written first,
+// pageSize at a time or the default page size if it's zero
+func New(ctx context.Context, cw client.API, logGroupName string, pageSize int) Paginator {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
 	}
 
-	cw := cloudwatchlogs.NewFromConfig(cfg)
-
 	// get log events paginator
 	streamsPaginator := cloudwatchlogs.NewDescribeLogStreamsPaginator(
 		cw,
 		&cloudwatchlogs.DescribeLogStreamsInput{
 			LogGroupName: aws.String(logGroupName),
-			Limit:        aws.Int32(50),
+			Limit:        aws.Int32(int32(pageSize)),
 			Descending:   aws.Bool(true),
 			OrderBy:      types.OrderByLastEventTime,
 		},
 	)
-	if err != nil {
-		log.Fatal(err)
-	}
 
 	return Paginator{
 		logGroup:         logGroupName,
@@ -43,14 +47,29 @@ func New(ctx context.Context, logGroupName string) Paginator {
 	}
 }
 
-// Get next page of events, return nil if no pages remain
-func (ep Paginator) NextPage(ctx context.Context) []types.LogStream {
+// HasMorePages returns true if NextPage may return more streams
+func (ep Paginator) HasMorePages() bool {
+	return ep.streamsPaginator.HasMorePages()
+}
+
+// Get next page of events, return nil if no pages remain. The page that
+// failed is fetched again by the next call.
+func (ep Paginator) NextPage(ctx context.Context) ([]types.LogStream, error) {
 	if !ep.streamsPaginator.HasMorePages() {
-		return nil
+		return nil, nil
 	}
 	streamsOutput, err := ep.streamsPaginator.NextPage(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	return streamsOutput.LogStreams
+	return streamsOutput.LogStreams, nil
+}
+
+// Delete deletes a log stream along with its events
+func Delete(ctx context.Context, cw client.API, group, stream string) error {
+	_, err := cw.DeleteLogStream(ctx, &cloudwatchlogs.DeleteLogStreamInput{
+		LogGroupName:  aws.String(group),
+		LogStreamName: aws.String(stream),
+	})
+	return err
 }
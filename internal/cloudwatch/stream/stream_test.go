@@ -0,0 +1,43 @@
+package stream_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"clviewer/internal/cloudwatch/fake"
+	"clviewer/internal/cloudwatch/stream"
+)
+
+func TestPageSize(t *testing.T) {
+	var streams []fake.Stream
+	for i := 0; i < 60; i++ {
+		streams = append(streams, fake.Stream{Name: fmt.Sprintf("s%d", i)})
+	}
+	b := fake.New(fake.Group{Name: "g", Streams: streams})
+
+	tests := []struct {
+		pageSize int
+		want     []int
+	}{
+		{0, []int{stream.DefaultPageSize, 10}},
+		{25, []int{25, 25, 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.pageSize), func(t *testing.T) {
+			p := stream.New(context.Background(), b, "g", tt.pageSize)
+			var got []int
+			for p.HasMorePages() {
+				page, err := p.NextPage(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, len(page))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got pages of %v streams, want %v", got, tt.want)
+			}
+		})
+	}
+}
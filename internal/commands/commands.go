@@ -2,6 +2,9 @@ package commands
 
 import (
 	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/layout"
+	"clviewer/internal/ui/timeformat"
 )
 
 type UpdateViewPortContentMsg struct {
@@ -21,6 +24,12 @@ func UpdateViewPort(content []string, yOffset int) tea.Cmd {
 type UpdateEventListItemsMsg struct {
 	Group  string
 	Stream string
+
+	// optional, used when opening the viewer at a locator
+	FilterPattern string
+	Start         int64
+	End           int64
+	Timestamp     int64
 }
 
 func UpdateEventListItems(group string, stream string) tea.Cmd {
@@ -51,3 +60,79 @@ func RedrawWindows() tea.Cmd {
 		return RedrawWindowsMsg{}
 	}
 }
+
+type SetTimeFormatMsg struct {
+	Format timeformat.Format
+}
+
+func SetTimeFormat(format timeformat.Format) tea.Cmd {
+	return func() tea.Msg {
+		return SetTimeFormatMsg{
+			Format: format,
+		}
+	}
+}
+
+// ErrorMsg reports an error to the user, the last error is shown in the
+// status bar
+type ErrorMsg struct {
+	Err error
+}
+
+func Error(err error) tea.Cmd {
+	return func() tea.Msg {
+		return ErrorMsg{
+			Err: err,
+		}
+	}
+}
+
+// CancelMsg cancels the fetches in progress, along with following
+type CancelMsg struct{}
+
+func Cancel() tea.Cmd {
+	return func() tea.Msg {
+		return CancelMsg{}
+	}
+}
+
+// SetLayoutMsg changes how the panes of every tab are arranged
+type SetLayoutMsg struct {
+	Layout layout.Layout
+}
+
+func SetLayout(l layout.Layout) tea.Cmd {
+	return func() tea.Msg {
+		return SetLayoutMsg{
+			Layout: l,
+		}
+	}
+}
+
+// NoticeMsg tells the user that something has been done, it's shown in the
+// status bar until the next notice or error
+type NoticeMsg struct {
+	Text string
+}
+
+func Notice(text string) tea.Cmd {
+	return func() tea.Msg {
+		return NoticeMsg{
+			Text: text,
+		}
+	}
+}
+
+// TestPatternMsg tests a filter pattern against the loaded events of a tab,
+// highlighting the events it matches
+type TestPatternMsg struct {
+	Pattern string
+}
+
+func TestPattern(pattern string) tea.Cmd {
+	return func() tea.Msg {
+		return TestPatternMsg{
+			Pattern: pattern,
+		}
+	}
+}
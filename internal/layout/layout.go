@@ -0,0 +1,94 @@
+package layout
+
+// NarrowWidth is the width below which the panes of the event page are
+// stacked rather than side by side
+const NarrowWidth = 100
+
+const (
+	// Step is how much a key press resizes a pane by
+	Step = 0.05
+
+	minRatio = 0.1
+	maxRatio = 0.9
+)
+
+// Layout is how the panes of the event page are arranged, it's shared by
+// every tab and saved with the session
+type Layout struct {
+	// Streams is the fraction of the page taken by the stream list, of its
+	// width or of its height when stacked
+	Streams float64 `json:"streams,omitempty"`
+	// Timestamps is the fraction of the event pane's width taken by the
+	// timestamp column
+	Timestamps float64 `json:"timestamps,omitempty"`
+
+	HideStreams    bool `json:"hideStreams,omitempty"`
+	HideTimestamps bool `json:"hideTimestamps,omitempty"`
+	// Zoom shows only the focused pane
+	Zoom bool `json:"zoom,omitempty"`
+}
+
+func Default() Layout {
+	return Layout{
+		Streams:    1.0 / 3.0,
+		Timestamps: 1.0 / 3.0,
+	}
+}
+
+// Normalize keeps the ratios within bounds, ratios missing from older
+// session files get their default
+func (l Layout) Normalize() Layout {
+	d := Default()
+	if l.Streams == 0 {
+		l.Streams = d.Streams
+	}
+	if l.Timestamps == 0 {
+		l.Timestamps = d.Timestamps
+	}
+	l.Streams = clamp(l.Streams)
+	l.Timestamps = clamp(l.Timestamps)
+	return l
+}
+
+// ResizeStreams grows the stream list by steps, or shrinks it if negative
+func (l Layout) ResizeStreams(steps int) Layout {
+	l.Streams = clamp(l.Streams + float64(steps)*Step)
+	return l
+}
+
+// ResizeTimestamps grows the timestamp column by steps, or shrinks it if
+// negative
+func (l Layout) ResizeTimestamps(steps int) Layout {
+	l.Timestamps = clamp(l.Timestamps + float64(steps)*Step)
+	return l
+}
+
+// Ratio returns the fraction of size that pos is at, for dragging a split
+func Ratio(pos, size int) float64 {
+	if size <= 0 {
+		return minRatio
+	}
+	return clamp(float64(pos) / float64(size))
+}
+
+// Split divides size into the part before ratio and the rest
+func Split(size int, ratio float64) (int, int) {
+	first := int(float64(size) * ratio)
+	return first, size - first
+}
+
+// Stacked returns true if the panes of a page width columns wide are stacked
+func Stacked(width int) bool {
+	return width < NarrowWidth
+}
+
+func clamp(ratio float64) float64 {
+	switch {
+	case ratio < minRatio:
+		return minRatio
+	case ratio > maxRatio:
+		return maxRatio
+	default:
+		return ratio
+	}
+}
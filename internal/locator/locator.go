@@ -0,0 +1,175 @@
+package locator
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scheme is the url scheme of a locator
+const Scheme = "clviewer"
+
+// Locator is a location in CloudWatch Logs to open the viewer at, written as
+//
+//	clviewer://<group>?profile=&region=&stream=&at=&filter=&start=&end=&tail=
+//
+// Log groups starting with a slash are written with three slashes, e.g.
+// clviewer:///aws/lambda/my-function. Times are milliseconds since epoch,
+// RFC 3339 or a duration before now such as "-15m".
+type Locator struct {
+	Profile       string
+	Region        string
+	Group         string
+	Stream        string
+	FilterPattern string
+	Timestamp     int64 // event to select, milliseconds since epoch
+	Start         int64 // milliseconds since epoch, zero for no start time
+	End           int64 // milliseconds since epoch, zero for no end time
+	Tail          bool  // start at the newest events of the stream
+}
+
+// Parse parses a clviewer:// locator
+func Parse(s string) (Locator, error) {
+	if !strings.HasPrefix(s, Scheme+"://") {
+		return Locator{}, fmt.Errorf("locator must start with %s://", Scheme)
+	}
+
+	path, rawQuery, _ := strings.Cut(strings.TrimPrefix(s, Scheme+"://"), "?")
+	group, err := url.PathUnescape(path)
+	if err != nil {
+		return Locator{}, fmt.Errorf("invalid log group: %w", err)
+	}
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Locator{}, err
+	}
+
+	l := Locator{
+		Profile:       q.Get("profile"),
+		Region:        q.Get("region"),
+		Group:         group,
+		Stream:        q.Get("stream"),
+		FilterPattern: q.Get("filter"),
+	}
+
+	times := []struct {
+		name  string
+		value *int64
+	}{
+		{"at", &l.Timestamp},
+		{"start", &l.Start},
+		{"end", &l.End},
+	}
+	for _, t := range times {
+		if q.Get(t.name) == "" {
+			continue
+		}
+		if *t.value, err = ParseTime(q.Get(t.name), time.Now()); err != nil {
+			return Locator{}, fmt.Errorf("invalid %s: %w", t.name, err)
+		}
+	}
+
+	if q.Get("tail") != "" {
+		if l.Tail, err = strconv.ParseBool(q.Get("tail")); err != nil {
+			return Locator{}, fmt.Errorf("invalid tail: %w", err)
+		}
+	}
+
+	return l, nil
+}
+
+// String formats the locator as a clviewer:// url
+func (l Locator) String() string {
+	q := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	setTime := func(key string, value int64) {
+		if value != 0 {
+			q.Set(key, strconv.FormatInt(value, 10))
+		}
+	}
+
+	set("profile", l.Profile)
+	set("region", l.Region)
+	set("stream", l.Stream)
+	set("filter", l.FilterPattern)
+	setTime("at", l.Timestamp)
+	setTime("start", l.Start)
+	setTime("end", l.End)
+	if l.Tail {
+		q.Set("tail", "true")
+	}
+
+	segments := strings.Split(l.Group, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+
+	s := Scheme + "://" + strings.Join(segments, "/")
+	if len(q) > 0 {
+		s += "?" + q.Encode()
+	}
+	return s
+}
+
+// MarshalText formats the locator as a clviewer:// url
+func (l Locator) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText parses a clviewer:// url
+func (l *Locator) UnmarshalText(text []byte) error {
+	var err error
+	*l, err = Parse(string(text))
+	return err
+}
+
+// Merge returns l with every field that is set in override replaced
+func (l Locator) Merge(override Locator) Locator {
+	mergeString := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	mergeTime := func(dst *int64, src int64) {
+		if src != 0 {
+			*dst = src
+		}
+	}
+
+	mergeString(&l.Profile, override.Profile)
+	mergeString(&l.Region, override.Region)
+	mergeString(&l.Group, override.Group)
+	mergeString(&l.Stream, override.Stream)
+	mergeString(&l.FilterPattern, override.FilterPattern)
+	mergeTime(&l.Timestamp, override.Timestamp)
+	mergeTime(&l.Start, override.Start)
+	mergeTime(&l.End, override.End)
+	if override.Tail {
+		l.Tail = true
+	}
+	return l
+}
+
+// ParseTime parses milliseconds since epoch, an RFC 3339 time or a negative
+// duration relative to now such as "-1h30m"
+func ParseTime(s string, now time.Time) (int64, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ms, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UnixMilli(), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d > 0 {
+			return 0, fmt.Errorf("%q is in the future, durations count back from now, e.g. -%s", s, strings.TrimPrefix(s, "+"))
+		}
+		return now.Add(d).UnixMilli(), nil
+	}
+	return 0, fmt.Errorf("%q is not a timestamp, RFC 3339 time or duration", s)
+}
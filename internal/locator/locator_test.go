@@ -0,0 +1,198 @@
+package locator_test
+
+import (
+	"testing"
+	"time"
+
+	"clviewer/internal/locator"
+)
+
+var now = time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    locator.Locator
+		wantErr bool
+	}{
+		{name: "empty", s: "clviewer://", want: locator.Locator{}},
+		{name: "group", s: "clviewer://app", want: locator.Locator{Group: "app"}},
+		{name: "leading slash", s: "clviewer:///aws/lambda/orders", want: locator.Locator{Group: "/aws/lambda/orders"}},
+		{name: "escaped segment", s: "clviewer://app/my%20group%3F", want: locator.Locator{Group: "app/my group?"}},
+		{
+			name: "every field",
+			s: "clviewer:///aws/lambda/orders?profile=prod&region=eu-west-1&stream=2023%2F03%2F01%2F%5B%24LATEST%5Dabc" +
+				"&filter=%7B+%24.level+%3D+%22error%22+%7D&at=1677672000000&start=1677668400000&end=1677675600000&tail=true",
+			want: locator.Locator{
+				Profile:       "prod",
+				Region:        "eu-west-1",
+				Group:         "/aws/lambda/orders",
+				Stream:        "2023/03/01/[$LATEST]abc",
+				FilterPattern: `{ $.level = "error" }`,
+				Timestamp:     1677672000000,
+				Start:         1677668400000,
+				End:           1677675600000,
+				Tail:          true,
+			},
+		},
+		{
+			name: "rfc 3339",
+			s:    "clviewer://app?at=2023-03-01T12:00:00Z",
+			want: locator.Locator{Group: "app", Timestamp: now.UnixMilli()},
+		},
+		{name: "wrong scheme", s: "https://app", wantErr: true},
+		{name: "bad escape", s: "clviewer://app%zz", wantErr: true},
+		{name: "bad time", s: "clviewer://app?start=yesterday", wantErr: true},
+		{name: "future duration", s: "clviewer://app?start=15m", wantErr: true},
+		{name: "bad tail", s: "clviewer://app?tail=maybe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := locator.Parse(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRelative(t *testing.T) {
+	l, err := locator.Parse("clviewer://app?start=-15m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ago := time.Since(time.UnixMilli(l.Start)); ago < 15*time.Minute || ago > 16*time.Minute {
+		t.Errorf("start is %s ago, want 15m", ago)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, l := range []locator.Locator{
+		{},
+		{Group: "app"},
+		{Group: "/aws/lambda/orders"},
+		{Group: "/aws/lambda/orders", Stream: "2023/03/01/[$LATEST]abc"},
+		{Group: "my group/with?odd#chars%", Stream: "a&b=c"},
+		{Group: "/ünïcode/グループ"},
+		{Group: "trailing/", FilterPattern: `"ERROR" -"health check"`},
+		{
+			Profile:       "prod",
+			Region:        "us-gov-west-1",
+			Group:         "/ecs/api",
+			Stream:        "api/web/0123",
+			FilterPattern: `{ $.latency > 100 && $.path = "/orders?id=1" }`,
+			Timestamp:     1677672000000,
+			Start:         1677668400000,
+			End:           1677675600000,
+			Tail:          true,
+		},
+	} {
+		s := l.String()
+		got, err := locator.Parse(s)
+		if err != nil {
+			t.Errorf("Parse(%q): %s", s, err)
+			continue
+		}
+		if got != l {
+			t.Errorf("Parse(%q) = %+v, want %+v", s, got, l)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		l    locator.Locator
+		want string
+	}{
+		{locator.Locator{Group: "/aws/lambda/orders"}, "clviewer:///aws/lambda/orders"},
+		{locator.Locator{Group: "app/my group"}, "clviewer://app/my%20group"},
+		{locator.Locator{Group: "app", Start: 1, Tail: true}, "clviewer://app?start=1&tail=true"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.l.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := locator.Locator{
+		Profile:       "dev",
+		Region:        "us-east-1",
+		Group:         "app",
+		Stream:        "s",
+		FilterPattern: "ERROR",
+		Start:         1,
+	}
+
+	tests := []struct {
+		name     string
+		override locator.Locator
+		want     locator.Locator
+	}{
+		{"nothing set", locator.Locator{}, base},
+		{
+			"some fields",
+			locator.Locator{Region: "eu-west-1", Group: "other", End: 2, Tail: true},
+			locator.Locator{
+				Profile:       "dev",
+				Region:        "eu-west-1",
+				Group:         "other",
+				Stream:        "s",
+				FilterPattern: "ERROR",
+				Start:         1,
+				End:           2,
+				Tail:          true,
+			},
+		},
+		{
+			"every field",
+			locator.Locator{"prod", "cn-north-1", "g", "t", "WARN", 3, 4, 5, true},
+			locator.Locator{"prod", "cn-north-1", "g", "t", "WARN", 3, 4, 5, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.Merge(tt.override); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    int64
+		wantErr bool
+	}{
+		{s: "1677672000000", want: 1677672000000},
+		{s: "2023-03-01T12:00:00Z", want: now.UnixMilli()},
+		{s: "2023-03-01T13:00:00+01:00", want: now.UnixMilli()},
+		{s: "-15m", want: now.Add(-15 * time.Minute).UnixMilli()},
+		{s: "-1h30m", want: now.Add(-90 * time.Minute).UnixMilli()},
+		{s: "0s", want: now.UnixMilli()},
+		{s: "15m", wantErr: true},
+		{s: "+1h", wantErr: true},
+		{s: "yesterday", wantErr: true},
+		{s: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := locator.ParseTime(tt.s, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTime(%q) error %v, want error %t", tt.s, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTime(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package paging
+
+import (
+	"fmt"
+
+	"clviewer/internal/cloudwatch/event"
+	"clviewer/internal/cloudwatch/stream"
+)
+
+// Options are how many events are fetched at a time and held by each tab, and
+// when the lists fetch their next page
+type Options struct {
+	// PageSize is the number of events fetched at a time
+	PageSize int
+	// StreamPageSize is the number of streams fetched at a time, zero for
+	// the default
+	StreamPageSize int
+	// Prefetch is how close the cursor gets to the end of a list before its
+	// next page is fetched, zero to only fetch pages when asked to
+	Prefetch int
+	// MaxEvents is the most events a tab holds, no more pages are fetched once
+	// it's reached. Zero for no limit.
+	MaxEvents int
+	// FollowBuffer is the most events a tab holds while following, the oldest
+	// are evicted as new ones arrive. Zero stops following at MaxEvents
+	// instead.
+	FollowBuffer int
+}
+
+func Default() Options {
+	return Options{
+		PageSize:       event.DefaultPageSize,
+		StreamPageSize: stream.DefaultPageSize,
+		Prefetch:       20,
+		MaxEvents:      50000,
+		FollowBuffer:   10000,
+	}
+}
+
+// Validate returns an error if an option is out of range
+func (o Options) Validate() error {
+	if o.PageSize < 1 || o.PageSize > event.MaxPageSize {
+		return fmt.Errorf("page size must be between 1 and %d", event.MaxPageSize)
+	}
+	if o.StreamPageSize < 1 || o.StreamPageSize > stream.MaxPageSize {
+		return fmt.Errorf("stream page size must be between 1 and %d", stream.MaxPageSize)
+	}
+	if o.Prefetch < 0 {
+		return fmt.Errorf("prefetch can't be negative")
+	}
+	if o.MaxEvents < 0 {
+		return fmt.Errorf("max events can't be negative")
+	}
+	if o.FollowBuffer < 0 {
+		return fmt.Errorf("follow buffer can't be negative")
+	}
+	return nil
+}
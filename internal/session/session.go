@@ -0,0 +1,96 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"clviewer/internal/layout"
+	"clviewer/internal/locator"
+)
+
+// State is what is remembered between launches of the viewer
+type State struct {
+	Tabs   []Tab         `json:"tabs"`
+	Active int           `json:"active"`
+	Layout layout.Layout `json:"layout"`
+}
+
+// Tab is the state of a single tab, the selected group, stream, search and
+// event are kept in the locator
+type Tab struct {
+	Locator locator.Locator `json:"locator"`
+	Page    int             `json:"page,omitempty"`
+	Focused int             `json:"focused,omitempty"`
+
+	LambdaMode bool `json:"lambdaMode,omitempty"`
+	// Offset is the position of the selected event among the events that
+	// share its timestamp
+	Offset   int     `json:"offset,omitempty"`
+	Expanded []int64 `json:"expanded,omitempty"`
+	YOffset  int     `json:"yOffset,omitempty"`
+	XOffset  int     `json:"xOffset,omitempty"`
+	Wrap     bool    `json:"wrap,omitempty"`
+}
+
+// DefaultPath returns the location of the state file in the user's config
+// directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "clviewer", "session.json"), nil
+}
+
+// Load reads the state saved at path, a missing state file is an empty state
+func Load(path string) (State, error) {
+	var state State
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+// Save writes state to path. The state is written to a temporary file that
+// replaces the state file once it is complete, so being killed part way
+// through a save leaves the previous state intact.
+func Save(path string, state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
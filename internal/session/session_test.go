@@ -0,0 +1,125 @@
+package session_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"clviewer/internal/layout"
+	"clviewer/internal/locator"
+	"clviewer/internal/session"
+)
+
+func state() session.State {
+	return session.State{
+		Tabs: []session.Tab{
+			{Locator: locator.Locator{Group: "/aws/lambda/orders"}},
+			{
+				Locator:    locator.Locator{Region: "eu-west-1", Group: "app", Stream: "s", Timestamp: 1677672000000},
+				Page:       1,
+				Focused:    2,
+				LambdaMode: true,
+				Offset:     1,
+				Expanded:   []int64{1677672000000, 1677672001000},
+				YOffset:    10,
+				XOffset:    8,
+				Wrap:       true,
+			},
+		},
+		Active: 1,
+		Layout: layout.Layout{Streams: 0.25, Timestamps: 0.4, HideStreams: true},
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "clviewer", "session.json")
+
+	if err := session.Save(path, state()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := session.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, state()) {
+		t.Errorf("got %+v, want %+v", got, state())
+	}
+}
+
+func TestSaveReplaces(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.json")
+
+	if err := session.Save(path, state()); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Save(path, session.State{Active: 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := session.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tabs) != 0 {
+		t.Errorf("got %d tabs, want the second save to replace the first", len(got.Tabs))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files, want only the state file left behind", len(entries))
+	}
+}
+
+func TestSaveFailed(t *testing.T) {
+	dir := t.TempDir()
+	// a directory can't be replaced by the state file
+	path := filepath.Join(dir, "session.json")
+	if err := os.Mkdir(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := session.Save(path, state()); err == nil {
+		t.Fatal("saved over a directory")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		t.Errorf("got %d files, want the temporary file removed", len(entries))
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	got, err := session.Load(filepath.Join(t.TempDir(), "session.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, session.State{}) {
+		t.Errorf("got %+v, want an empty state", got)
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	for name, data := range map[string]string{
+		"truncated":   `{"tabs": [{"locator": "clviewer://app"`,
+		"bad locator": `{"tabs": [{"locator": "https://app"}]}`,
+		"string tab":  `{"tabs": ["clviewer://app"]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "session.json")
+			if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := session.Load(path); err == nil {
+				t.Error("loaded a corrupt state file")
+			}
+		})
+	}
+}
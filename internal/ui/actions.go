@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/cloudwatch/client"
+	"clviewer/internal/commands"
+	"clviewer/internal/locator"
+	"clviewer/internal/ui/palette"
+)
+
+// action is run by the ui itself when chosen from the command palette,
+// rather than being delivered to the current tab
+type action func(m *Model) (*Model, tea.Cmd)
+
+// clientMsg is sent once the client for a new profile or region is created
+type clientMsg struct {
+	cw  client.Client
+	err error
+}
+
+func init() {
+	palette.Register(
+		palette.Action{
+			Name: "quit",
+			Help: "quit the viewer",
+			Key:  keys.Quit,
+			Msg: func(string) tea.Msg {
+				return action(func(m *Model) (*Model, tea.Cmd) {
+					return m.quit()
+				})
+			},
+		},
+		palette.Action{
+			Name: "cancel",
+			Help: "cancel loading the groups, streams or events of the tab and stop following",
+			Key:  keys.Cancel,
+			Msg: func(string) tea.Msg {
+				return action(func(m *Model) (*Model, tea.Cmd) {
+					return m.updateTab(m.active, commands.CancelMsg{})
+				})
+			},
+		},
+		palette.Action{
+			Name: "new tab",
+			Help: "open a tab at a log group or clviewer:// locator",
+			Key:  keys.NewTab,
+			Args: "[group]",
+			Msg: func(args string) tea.Msg {
+				return action(func(m *Model) (*Model, tea.Cmd) {
+					initial, err := parseGroup(args)
+					if err != nil {
+						return m, commands.Error(fmt.Errorf("new tab: %w", err))
+					}
+					return m.openTab(initial)
+				})
+			},
+		},
+		palette.Action{
+			Name: "close tab",
+			Help: "close the current tab",
+			Key:  keys.CloseTab,
+			Msg: func(string) tea.Msg {
+				return action(func(m *Model) (*Model, tea.Cmd) {
+					return m.closeTab(m.active)
+				})
+			},
+		},
+		palette.Action{
+			Name: "next tab",
+			Help: "switch to the next tab",
+			Key:  keys.NextTab,
+			Msg: func(string) tea.Msg {
+				return action(func(m *Model) (*Model, tea.Cmd) {
+					return m.switchTab(1), nil
+				})
+			},
+		},
+		palette.Action{
+			Name: "previous tab",
+			Help: "switch to the previous tab",
+			Key:  keys.PrevTab,
+			Msg: func(string) tea.Msg {
+				return action(func(m *Model) (*Model, tea.Cmd) {
+					return m.switchTab(-1), nil
+				})
+			},
+		},
+		palette.Action{
+			Name: "show log groups",
+			Help: "switch to the log group list",
+			Key:  keys.PrevPage,
+			Msg: func(string) tea.Msg {
+				return action(func(m *Model) (*Model, tea.Cmd) {
+					m.tabs[m.active].paginator.Page = groupPage
+					return m, nil
+				})
+			},
+		},
+		palette.Action{
+			Name: "show log events",
+			Help: "switch to the log streams and events",
+			Key:  keys.NextPage,
+			Msg: func(string) tea.Msg {
+				return action(func(m *Model) (*Model, tea.Cmd) {
+					m.tabs[m.active].paginator.Page = eventPage
+					return m, nil
+				})
+			},
+		},
+		palette.Action{
+			Name: "open group",
+			Help: "list the streams of a log group in the current tab",
+			Args: "<group>",
+			Msg: func(args string) tea.Msg {
+				return action(func(m *Model) (*Model, tea.Cmd) {
+					m.tabs[m.active].paginator.Page = eventPage
+					return m.updateTab(m.active, commands.UpdateStreamListItemsMsg{
+						Group: args,
+					})
+				})
+			},
+		},
+		palette.Action{
+			Name: "profile",
+			Help: "open the current log group in a new tab using another aws profile",
+			Args: "<profile>",
+			Msg: func(args string) tea.Msg {
+				return action(func(m *Model) (*Model, tea.Cmd) {
+					return m, newClient(m.ctx, m.cw, args, m.cw.Region)
+				})
+			},
+		},
+		palette.Action{
+			Name: "region",
+			Help: "open the current log group in a new tab in another region",
+			Args: "<region>",
+			Msg: func(args string) tea.Msg {
+				return action(func(m *Model) (*Model, tea.Cmd) {
+					return m, newClient(m.ctx, m.cw, m.cw.Profile, args)
+				})
+			},
+		},
+	)
+}
+
+// parseGroup parses the argument of the new tab action, a clviewer://
+// locator or the name of a log group
+func parseGroup(args string) (locator.Locator, error) {
+	if strings.HasPrefix(args, locator.Scheme+"://") {
+		return locator.Parse(args)
+	}
+	return locator.Locator{Group: args}, nil
+}
+
+// newClient switches cw to another profile or region in the background as
+// loading the aws config can mean reading credentials
+func newClient(ctx context.Context, cw client.Client, profile, region string) tea.Cmd {
+	return func() tea.Msg {
+		cw, err := cw.Switch(ctx, profile, region)
+		return clientMsg{cw: cw, err: err}
+	}
+}
@@ -0,0 +1,77 @@
+package ansi
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/truncate"
+)
+
+// CutLeft removes the first n columns from every line of s. ANSI escape
+// sequences are kept so that styling carries over to the visible text.
+func CutLeft(s string, n int) string {
+	if n <= 0 {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		lines[i] = cutLineLeft(lines[i], n)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func cutLineLeft(line string, n int) string {
+	var (
+		b        strings.Builder
+		width    int
+		inEscape bool
+	)
+
+	for _, r := range line {
+		switch {
+		case r == '\x1b':
+			inEscape = true
+			b.WriteRune(r)
+		case inEscape:
+			b.WriteRune(r)
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+				inEscape = false
+			}
+		case width >= n:
+			b.WriteRune(r)
+		default:
+			width += runewidth.RuneWidth(r)
+		}
+	}
+	return b.String()
+}
+
+// Truncate cuts line down to n columns, keeping ANSI escape sequences
+func Truncate(line string, n int) string {
+	return truncate.String(line, uint(n))
+}
+
+// Overlay draws fg on top of bg with its top left corner at column x of row
+// y, the parts of bg around fg are kept
+func Overlay(bg, fg string, x, y int) string {
+	bgLines := strings.Split(bg, "\n")
+	for i, fgLine := range strings.Split(fg, "\n") {
+		row := y + i
+		if row < 0 || row >= len(bgLines) {
+			continue
+		}
+
+		left := Truncate(bgLines[row], x)
+		if width := lipgloss.Width(left); width < x {
+			left += strings.Repeat(" ", x-width)
+		}
+		right := CutLeft(bgLines[row], x+lipgloss.Width(fgLine))
+
+		bgLines[row] = left + reset + fgLine + reset + right
+	}
+	return strings.Join(bgLines, "\n")
+}
+
+const reset = "\x1b[0m"
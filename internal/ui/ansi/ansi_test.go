@@ -0,0 +1,36 @@
+package ansi_test
+
+import (
+	"testing"
+
+	"clviewer/internal/ui/ansi"
+)
+
+func TestCutLeft(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"hello", 0, "hello"},
+		{"hello", 2, "llo"},
+		{"hello", 9, ""},
+		{"hello\nworld", 3, "lo\nld"},
+		{"\x1b[31mhello\x1b[0m", 2, "\x1b[31mllo\x1b[0m"},
+		{"世界ab", 2, "界ab"},
+	}
+
+	for _, tt := range tests {
+		if got := ansi.CutLeft(tt.s, tt.n); got != tt.want {
+			t.Errorf("CutLeft(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	got := ansi.Overlay("aaaaa\nbbbbb\nccccc", "XY", 1, 1)
+	want := "aaaaa\nb\x1b[0mXY\x1b[0mbb\nccccc"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
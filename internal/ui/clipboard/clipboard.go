@@ -0,0 +1,66 @@
+package clipboard
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/commands"
+)
+
+// OSC52Msg asks the top level model to set the clipboard with an OSC 52
+// escape sequence, see Print
+type OSC52Msg struct {
+	seq osc52.Sequence
+}
+
+// Write writes text to the system clipboard in the background
+func Write(text string) tea.Cmd {
+	return func() tea.Msg {
+		return Copy(text)
+	}
+}
+
+// Copy writes text to the system clipboard, falling back to an OSC 52 escape
+// sequence so the terminal sets the clipboard when there is no clipboard
+// available, e.g. over ssh. It blocks so is only called from a tea.Cmd, and
+// returns an OSC52Msg to fall back or nil.
+func Copy(text string) tea.Msg {
+	if clipboard.Unsupported {
+		return osc52Msg(text)
+	}
+
+	if err := clipboard.WriteAll(text); err != nil {
+		log.Printf("clipboard unavailable, falling back to osc52: %s", err)
+		return osc52Msg(text)
+	}
+	return nil
+}
+
+func osc52Msg(text string) OSC52Msg {
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	return OSC52Msg{seq: seq}
+}
+
+// Print writes the escape sequence of msg to out, the program's output. The
+// sequence goes out in a single write, so it doesn't land in the middle of a
+// frame and the renderer carries on without being paused.
+func Print(out io.Writer, msg OSC52Msg) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := msg.seq.WriteTo(out); err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error with clipboard: %w", err)}
+		}
+		return nil
+	}
+}
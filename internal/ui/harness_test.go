@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/cloudwatch/client"
+	"clviewer/internal/cloudwatch/event"
+	"clviewer/internal/paging"
+	"clviewer/internal/session"
+	"clviewer/internal/ui/timeformat"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestMain(m *testing.M) {
+	// the models log what they're doing for debugging
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// defaultIdle is how long a command can run before it's taken to be a timer,
+// such as the cursor blinking or following, which tests don't wait for
+const defaultIdle = 250 * time.Millisecond
+
+// harness drives a ui.Model the way tea.Program does, running the commands
+// it returns and feeding their messages back in until it settles
+type harness struct {
+	t *testing.T
+	m *Model
+
+	msgs    chan tea.Msg
+	pending int // commands running
+	// idle is how long settle waits for a message while commands are running
+	idle time.Duration
+}
+
+// newHarness opens the viewer on state against cw, such as a fake backend or
+// a replay, in a window width by height. Pages are only fetched when asked for,
+// so tests decide when they're loaded.
+func newHarness(t *testing.T, cw client.API, state session.State, width, height int) *harness {
+	t.Helper()
+	return newPagingHarness(t, cw, state, paging.Options{PageSize: event.DefaultPageSize}, width, height)
+}
+
+// newPagingHarness is newHarness with the paging options opts
+func newPagingHarness(
+	t *testing.T,
+	cw client.API,
+	state session.State,
+	opts paging.Options,
+	width, height int,
+) *harness {
+	t.Helper()
+
+	format, err := timeformat.New("seconds", "utc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Tabs) == 0 {
+		state.Tabs = []session.Tab{{}}
+	}
+
+	// a client is used as it is, e.g. to keep its limiter
+	c, ok := cw.(client.Client)
+	if !ok {
+		c = client.Client{API: cw, Region: "test"}
+	}
+
+	h := &harness{
+		t:    t,
+		m:    New(context.Background(), c, state, format, opts, ""),
+		msgs: make(chan tea.Msg, 64),
+		idle: defaultIdle,
+	}
+	h.run(h.m.Init())
+	h.send(tea.WindowSizeMsg{Width: width, Height: height})
+	return h
+}
+
+// send updates the model with msgs, one after the other, letting it settle
+// after each
+func (h *harness) send(msgs ...tea.Msg) {
+	h.t.Helper()
+	for _, msg := range msgs {
+		h.update(msg)
+		h.settle()
+	}
+}
+
+// keys types each key in turn, named as tea.KeyMsg.String() names them.
+// Single characters are typed as runes.
+func (h *harness) keys(keys ...string) {
+	h.t.Helper()
+	for _, k := range keys {
+		h.send(keyMsg(k))
+	}
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	for t, name := range keyNames {
+		if name == k {
+			return tea.KeyMsg{Type: t}
+		}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+var keyNames = map[tea.KeyType]string{
+	tea.KeyEnter:     "enter",
+	tea.KeyTab:       "tab",
+	tea.KeyShiftTab:  "shift+tab",
+	tea.KeyEsc:       "esc",
+	tea.KeySpace:     " ",
+	tea.KeyUp:        "up",
+	tea.KeyDown:      "down",
+	tea.KeyLeft:      "left",
+	tea.KeyRight:     "right",
+	tea.KeyPgUp:      "pgup",
+	tea.KeyPgDown:    "pgdown",
+	tea.KeyBackspace: "backspace",
+	tea.KeyCtrlT:     "ctrl+t",
+	tea.KeyCtrlW:     "ctrl+w",
+	tea.KeyCtrlX:     "ctrl+x",
+}
+
+func (h *harness) update(msg tea.Msg) {
+	_, cmd := h.m.Update(msg)
+	h.run(cmd)
+}
+
+// run starts cmd, batches are split up so each of their commands is run on
+// its own like tea.Program does
+func (h *harness) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	h.pending++
+	go func() {
+		h.msgs <- cmd()
+	}()
+}
+
+// settle processes messages until no commands are running, or none have
+// finished for h.idle. Commands still running are picked up by the next
+// settle, as if that much time had passed.
+func (h *harness) settle() {
+	for h.pending > 0 {
+		select {
+		case msg := <-h.msgs:
+			h.pending--
+			h.handle(msg)
+		case <-time.After(h.idle):
+			return
+		}
+	}
+}
+
+// until processes messages until cond is true, then lets the model settle
+func (h *harness) until(timeout time.Duration, cond func() bool) {
+	h.t.Helper()
+	deadline := time.After(timeout)
+	for !cond() {
+		select {
+		case msg := <-h.msgs:
+			h.pending--
+			h.handle(msg)
+		case <-deadline:
+			h.t.Fatalf("gave up waiting after %s", timeout)
+		}
+	}
+	h.settle()
+}
+
+func (h *harness) handle(msg tea.Msg) {
+	if msg == tea.Quit() {
+		return
+	}
+	switch msg := msg.(type) {
+	case nil:
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			h.run(cmd)
+		}
+	default:
+		h.update(msg)
+	}
+}
+
+var escapes = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]|\x1b\][^\x07]*\x07`)
+
+// view is the rendered view without colours or trailing spaces
+func (h *harness) view() string {
+	lines := strings.Split(escapes.ReplaceAllString(h.m.View(), ""), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// golden compares the view with testdata/<test name>/<name>.golden, or
+// writes it there when run with -update
+func (h *harness) golden(name string) {
+	h.t.Helper()
+
+	path := filepath.Join("testdata", h.t.Name(), name+".golden")
+	view := h.view()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			h.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(view), 0o644); err != nil {
+			h.t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		h.t.Fatalf("%s, run the tests with -update to create it", err)
+	}
+	if view != string(want) {
+		h.t.Errorf("view doesn't match %s, got:\n%s", path, view)
+	}
+}
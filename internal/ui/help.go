@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpMode is how much of the help overlay is shown, ? cycles through them
+type helpMode int
+
+const (
+	helpClosed helpMode = iota
+	helpShort
+	helpFull
+	numHelpModes
+)
+
+var (
+	helpBoxStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("69")).
+			PaddingLeft(1).
+			PaddingRight(1)
+
+	helpHintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+// keyMaps shows the bindings of several key maps together
+type keyMaps []help.KeyMap
+
+func (k keyMaps) ShortHelp() []key.Binding {
+	var bindings []key.Binding
+	for _, keyMap := range k {
+		bindings = append(bindings, keyMap.ShortHelp()...)
+	}
+	return bindings
+}
+
+func (k keyMaps) FullHelp() [][]key.Binding {
+	var columns [][]key.Binding
+	for _, keyMap := range k {
+		columns = append(columns, keyMap.FullHelp()...)
+	}
+	return columns
+}
+
+// helpOverlay shows the global keys and the keys of the focused pane
+func (m *Model) helpOverlay() string {
+	width := overlayWidth(m.Width)
+
+	h := help.New()
+	h.ShowAll = m.helpMode == helpFull
+	h.Width = width - 4 // border and padding
+
+	title, paneKeys := m.tabs[m.active].help()
+
+	hint := "? more · esc close"
+	if m.helpMode == helpFull {
+		hint = "? close"
+	}
+
+	return helpBoxStyle.Width(width - 2).Render(strings.Join([]string{
+		bold.Render("global"),
+		helpKeysView(h, keys),
+		"",
+		bold.Render(title),
+		helpKeysView(h, keyMaps(paneKeys)),
+		"",
+		helpHintStyle.Render(hint),
+	}, "\n"))
+}
+
+// helpKeysView renders the bindings of keyMap, in full mode the columns that
+// don't fit the width of h are wrapped onto more rows rather than cut off
+func helpKeysView(h help.Model, keyMap help.KeyMap) string {
+	if !h.ShowAll {
+		return h.View(keyMap)
+	}
+
+	const separator = "    "
+
+	var (
+		rows    []string
+		row     []string
+		rowSize int
+	)
+	for _, column := range keyMap.FullHelp() {
+		view := h.FullHelpView([][]key.Binding{column})
+		width := lipgloss.Width(view)
+
+		if len(row) > 0 && rowSize+len(separator)+width > h.Width {
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+			row, rowSize = nil, 0
+		}
+		if len(row) > 0 {
+			row = append(row, separator)
+			rowSize += len(separator)
+		}
+		row = append(row, view)
+		rowSize += width
+	}
+	if len(row) > 0 {
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	}
+
+	return strings.Join(rows, "\n\n")
+}
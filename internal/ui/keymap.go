@@ -0,0 +1,71 @@
+package ui
+
+import "github.com/charmbracelet/bubbles/key"
+
+type keyMap struct {
+	Quit     key.Binding
+	Cancel   key.Binding
+	PrevPage key.Binding
+	NextPage key.Binding
+	NewTab   key.Binding
+	CloseTab key.Binding
+	PrevTab  key.Binding
+	NextTab  key.Binding
+	Palette  key.Binding
+	Help     key.Binding
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Palette, k.Help, k.Quit}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.PrevPage, k.NextPage},
+		{k.NewTab, k.CloseTab, k.PrevTab, k.NextTab},
+		{k.Cancel, k.Palette, k.Help, k.Quit},
+	}
+}
+
+var keys = keyMap{
+	Quit: key.NewBinding(
+		key.WithKeys("q", "ctrl+c"),
+		key.WithHelp("q", "quit"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("ctrl+x"),
+		key.WithHelp("ctrl+x", "cancel loading"),
+	),
+	PrevPage: key.NewBinding(
+		key.WithKeys("h"),
+		key.WithHelp("h", "log groups"),
+	),
+	NextPage: key.NewBinding(
+		key.WithKeys("l"),
+		key.WithHelp("l", "log events"),
+	),
+	NewTab: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "new tab"),
+	),
+	CloseTab: key.NewBinding(
+		key.WithKeys("ctrl+w"),
+		key.WithHelp("ctrl+w", "close tab"),
+	),
+	PrevTab: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous tab"),
+	),
+	NextTab: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next tab"),
+	),
+	Palette: key.NewBinding(
+		key.WithKeys(":", "ctrl+p"),
+		key.WithHelp(":", "command palette"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "help"),
+	),
+}
@@ -0,0 +1,170 @@
+package logevent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/commands"
+	"clviewer/internal/locator"
+	"clviewer/internal/ui/logevent/message"
+	"clviewer/internal/ui/palette"
+	"clviewer/internal/ui/timeformat"
+)
+
+// runKeyMsg runs the action bound to a key when it's chosen from the command
+// palette, whichever pane is focused
+type runKeyMsg tea.KeyMsg
+
+type (
+	filterMsg     string
+	timeRangeMsg  string
+	goToMsg       string
+	openStreamMsg string
+	timeLayoutMsg string
+)
+
+func init() {
+	palette.Register(
+		keyAction("load more events", "load the next page of events", keys.LoadMore),
+		keyAction("reload events", "load the events again from the start", keys.Reload),
+		keyAction("toggle follow", "fetch new events as they're written", keys.Follow),
+		keyAction("toggle collapsed", "expand or collapse the selected event", keys.Collapse),
+		keyAction("toggle collapsed all", "expand or collapse every event", keys.CollapseAll),
+		keyAction("toggle wrap", "wrap long lines instead of scrolling them", keys.Wrap),
+		keyAction("toggle invocations", "group lambda events by invocation", keys.Invocations),
+		keyAction("invocation filter", "show all, failed or timed out invocations", keys.InvocationFilter),
+		keyAction("next invocation", "select the next lambda invocation", keys.NextInvocation),
+		keyAction("previous invocation", "select the previous lambda invocation", keys.PrevInvocation),
+		keyAction("next histogram bucket", "select the first event of the next bucket", keys.NextBucket),
+		keyAction("previous histogram bucket", "select the first event of the previous bucket", keys.PrevBucket),
+		keyAction("cycle time format", "switch between timestamp layouts, relative and delta times", keys.TimeFormat),
+		keyAction("cycle time zone", "switch between local time, utc and the -tz zone", keys.TimeZone),
+		keyAction("copy", "copy the selected or marked events", keys.Copy),
+		keyAction("mark", "mark the start of a range of events to copy", keys.Mark),
+		keyAction("open in console", "open the current view in the aws console", keys.OpenConsole),
+		keyAction("copy console link", "copy a link to the current view in the aws console", keys.CopyConsoleURL),
+		keyAction("copy locator", "copy a clviewer:// locator for the selected event", keys.CopyLocator),
+		palette.Action{
+			Name: "filter",
+			Help: "search the events with a filter pattern, empty to clear it",
+			Args: "[pattern]",
+			Msg:  func(args string) tea.Msg { return filterMsg(args) },
+		},
+		palette.Action{
+			Name: "test pattern",
+			Help: "highlight the loaded events a metric filter pattern matches",
+			Args: "<pattern>",
+			Msg:  func(args string) tea.Msg { return commands.TestPatternMsg{Pattern: args} },
+		},
+		palette.Action{
+			Name: "highlight",
+			Help: "highlight the events a filter pattern matches as they're loaded, without calling aws",
+			Args: "<pattern>",
+			Msg:  func(args string) tea.Msg { return highlightMsg(args) },
+		},
+		palette.Action{
+			Name: "clear highlights",
+			Help: "clear the events highlighted by a pattern",
+			Msg:  func(string) tea.Msg { return clearHighlightsMsg{} },
+		},
+		palette.Action{
+			Name: "time range",
+			Help: "only show events between two times, e.g. -1h or 2023-04-01T10:00:00Z",
+			Args: "<start> [end]",
+			Msg:  func(args string) tea.Msg { return timeRangeMsg(args) },
+		},
+		palette.Action{
+			Name: "go to",
+			Help: "select the first event at or after a time",
+			Args: "<time>",
+			Msg:  func(args string) tea.Msg { return goToMsg(args) },
+		},
+		palette.Action{
+			Name: "open stream",
+			Help: "show the events of a stream in the current log group",
+			Args: "<stream>",
+			Msg:  func(args string) tea.Msg { return openStreamMsg(args) },
+		},
+		palette.Action{
+			Name: "time format",
+			Help: "minutes, seconds, millis or a go time layout",
+			Args: "<layout>",
+			Msg:  func(args string) tea.Msg { return timeLayoutMsg(args) },
+		},
+	)
+
+	for _, item := range copyMenuItems {
+		format := item.format
+		palette.Register(
+			palette.Action{
+				Name: "copy as " + format.String(),
+				Help: "copy the selected or marked events",
+				Msg: func(string) tea.Msg {
+					return message.CopyMessage{Format: format}
+				},
+			},
+			palette.Action{
+				Name: "copy all as " + format.String(),
+				Help: "copy every loaded event",
+				Msg: func(string) tea.Msg {
+					return message.CopyMessage{Format: format, All: true}
+				},
+			},
+		)
+	}
+}
+
+func keyAction(name, help string, binding key.Binding) palette.Action {
+	return palette.Action{
+		Name: name,
+		Help: help,
+		Key:  binding,
+		Msg: func(string) tea.Msg {
+			return runKeyMsg(palette.KeyMsg(binding))
+		},
+	}
+}
+
+// handleAction runs the actions of the command palette that take arguments
+func (m Model) handleAction(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case filterMsg:
+		m.filterPattern = string(msg)
+		return m.updateEventItems()
+	case timeRangeMsg:
+		args := strings.Fields(string(msg))
+		times := make([]int64, 2)
+		for i := 0; i < len(args) && i < len(times); i++ {
+			var err error
+			if times[i], err = locator.ParseTime(args[i], time.Now()); err != nil {
+				return m, commands.Error(fmt.Errorf("time range: %w", err))
+			}
+		}
+		m.startTime, m.endTime = times[0], times[1]
+		return m.updateEventItems()
+	case goToMsg:
+		timestamp, err := locator.ParseTime(string(msg), time.Now())
+		if err != nil {
+			return m, commands.Error(fmt.Errorf("go to: %w", err))
+		}
+		m.jumpTo = timestamp
+		return m.updateEventItems()
+	case openStreamMsg:
+		return m, commands.UpdateEventListItems(m.selectedGroup, string(msg))
+	case timeLayoutMsg:
+		zone := "local"
+		if m.timeFormat.Location != nil {
+			zone = m.timeFormat.Location.String()
+		}
+		format, err := timeformat.New(string(msg), zone)
+		if err != nil {
+			return m, commands.Error(fmt.Errorf("time format: %w", err))
+		}
+		return m, commands.SetTimeFormat(format)
+	}
+	return m, nil
+}
@@ -0,0 +1,90 @@
+package logevent
+
+import (
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/cloudwatch/console"
+	"clviewer/internal/locator"
+	"clviewer/internal/ui/clipboard"
+)
+
+// consoleView returns the location of the selected group, stream and event
+// in the CloudWatch console
+func (m Model) consoleView() console.View {
+	start := m.selectedTimestamp()
+	if start == 0 {
+		start = m.startTime
+	}
+
+	return console.View{
+		Region:        m.cw.Region,
+		Group:         m.selectedGroup,
+		Stream:        m.selectedStream,
+		FilterPattern: m.filterPattern,
+		Start:         start,
+		End:           m.endTime,
+	}
+}
+
+// Locator returns a locator that opens the viewer at the selected event
+func (m Model) Locator() locator.Locator {
+	return locator.Locator{
+		Profile:       m.cw.Profile,
+		Region:        m.cw.Region,
+		Group:         m.selectedGroup,
+		Stream:        m.selectedStream,
+		FilterPattern: m.filterPattern,
+		Timestamp:     m.selectedTimestamp(),
+		Start:         m.startTime,
+		End:           m.endTime,
+		Tail:          m.tail,
+	}
+}
+
+// copyLocator copies the locator of the current view to the clipboard
+func (m Model) copyLocator() tea.Cmd {
+	return clipboard.Write(m.Locator().String())
+}
+
+// selectedTimestamp returns the timestamp of the selected event, or of the
+// selected invocation when in lambda mode
+func (m Model) selectedTimestamp() int64 {
+	// the events to jump to haven't loaded yet
+	if m.jumpTo != 0 {
+		return m.jumpTo
+	}
+
+	if m.lambdaMode {
+		invocations := m.filteredInvocations()
+		if m.selectedEvent < len(invocations) {
+			return invocations[m.selectedEvent].Timestamp()
+		}
+		return 0
+	}
+
+	if m.selectedEvent < len(m.events) {
+		return aws.ToInt64(m.events[m.selectedEvent].Timestamp)
+	}
+	return 0
+}
+
+// openInConsole opens the current view in the CloudWatch console, or copies
+// the link to the clipboard if copyLink is set or no browser could be opened
+func (m Model) openInConsole(copyLink bool) tea.Cmd {
+	return func() tea.Msg {
+		url := console.URL(m.consoleView())
+
+		if !copyLink {
+			err := console.Open(url)
+			if err == nil {
+				return nil
+			}
+			log.Printf("error opening browser: %s", err)
+		}
+
+		return clipboard.Copy(url)
+	}
+}
@@ -0,0 +1,67 @@
+package logevent
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/ui/logevent/message"
+)
+
+// copyMenuItems maps the key pressed in the copy menu to the format the
+// selection is copied in, the upper case key copies every event
+var copyMenuItems = []struct {
+	key    string
+	format message.CopyFormat
+}{
+	{"d", message.CopyDisplayed},
+	{"r", message.CopyRaw},
+	{"j", message.CopyPrettyJSON},
+	{"n", message.CopyNDJSON},
+	{"m", message.CopyMarkdown},
+}
+
+// handleCopyMenuKey copies the selection in the format chosen by msg and
+// closes the copy menu, any key not in the menu just closes it
+func (m Model) handleCopyMenuKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	m.copyMenu = false
+
+	for _, item := range copyMenuItems {
+		switch msg.String() {
+		case item.key:
+			return m.copyEvents(message.CopyMessage{Format: item.format})
+		case strings.ToUpper(item.key):
+			return m.copyEvents(message.CopyMessage{Format: item.format, All: true})
+		}
+	}
+	return m, nil
+}
+
+// copyEvents copies the events msg selects, copying all only copies the
+// events the timestamp list is filtered down to
+func (m Model) copyEvents(msg message.CopyMessage) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if msg.All {
+		msg.Indexes = m.Timestamp.VisibleIndexes()
+	}
+	m.Messages, cmd = m.Messages.Update(msg)
+	return m, cmd
+}
+
+func (m Model) copyMenuView() string {
+	var items []string
+	for _, item := range copyMenuItems {
+		items = append(items, fmt.Sprintf(
+			"%s %s",
+			bold.Render(item.key),
+			purpleText.Render(item.format.String()),
+		))
+	}
+
+	return fmt.Sprintf(
+		" %s: %s  (shift copies all events) ",
+		bold.Render("Copy as"),
+		strings.Join(items, " · "),
+	)
+}
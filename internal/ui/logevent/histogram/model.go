@@ -0,0 +1,309 @@
+package histogram
+
+import (
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"clviewer/internal/commands"
+	"clviewer/internal/ui/timeformat"
+)
+
+// Height is the number of lines the histogram takes up
+const Height = 3
+
+const padding = 2
+
+var (
+	bars = []rune(" ▁▂▃▄▅▆▇█")
+
+	barStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("98"))
+	errorBarStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("160"))
+	selectedStyle = lipgloss.NewStyle().Background(lipgloss.Color("237"))
+	axisStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	// errorRegex matches the error level of common log formats, the bare
+	// words only when upper case so prose mentioning an error isn't counted
+	errorRegex = regexp.MustCompile(
+		`(?i:\[error\]|\terror\t|"level"\s*:\s*"(error|fatal)"|level=(error|fatal))` +
+			`|\b(ERROR|FATAL)\b|"errorType"|Runtime\.[A-Za-z]+Error|Traceback \(most recent call last\)`,
+	)
+)
+
+// Item is a single event counted by the histogram
+type Item struct {
+	Timestamp int64 // milliseconds since epoch
+	Error     bool
+}
+
+// IsError returns true if the message looks like it was logged at error
+// level, to be drawn on the error line of the histogram
+func IsError(message string) bool {
+	return errorRegex.MatchString(message)
+}
+
+type bucket struct {
+	count  int
+	errors int
+	first  int // index of the first item in the bucket, -1 if empty
+}
+
+// Model is a sparkline of the number of events over time, with error level
+// events drawn on a separate line
+type Model struct {
+	Width    int
+	items    []Item
+	buckets  []bucket
+	start    int64
+	end      int64
+	selected int // selected bucket, -1 if no bucket has been selected
+	format   timeformat.Format
+}
+
+func New() Model {
+	return Model{
+		selected: -1,
+		format:   timeformat.Default(),
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetItemsMsg replaces the items counted by the histogram, items must be
+// in the same order as they are displayed in the event list
+type SetItemsMsg []Item
+
+// AppendItemsMsg adds items after the counted items
+type AppendItemsMsg []Item
+
+// PrependItemsMsg adds items before the counted items
+type PrependItemsMsg []Item
+
+// DropItemsMsg drops the N oldest items
+type DropItemsMsg struct{ N int }
+
+// SelectItemMsg tells the histogram the cursor moved to the item at Index,
+// the selected bucket is cleared if it doesn't hold the item
+type SelectItemMsg struct{ Index int }
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.bucketItems()
+	case SetItemsMsg:
+		m.items = msg
+		m.selected = -1
+		m.bucketItems()
+	case AppendItemsMsg:
+		m.items = append(m.items, msg...)
+		m.addItems(len(m.items)-len(msg), len(m.items))
+	case PrependItemsMsg:
+		m.items = append(append([]Item{}, msg...), m.items...)
+		for i := range m.buckets {
+			if m.buckets[i].first >= 0 {
+				m.buckets[i].first += len(msg)
+			}
+		}
+		m.addItems(0, len(msg))
+	case DropItemsMsg:
+		// copied so the dropped items can be freed
+		m.items = append([]Item(nil), m.items[min(msg.N, len(m.items)):]...)
+		m.selected = -1
+		m.bucketItems()
+	case SelectItemMsg:
+		m.deselectOutside(msg.Index)
+	case commands.SetTimeFormatMsg:
+		m.format = msg.Format
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	if len(m.buckets) == 0 {
+		return strings.Repeat("\n", Height-1)
+	}
+
+	maxCount := 0
+	for _, b := range m.buckets {
+		maxCount = max(maxCount, b.count)
+	}
+
+	var total, errors strings.Builder
+	for i, b := range m.buckets {
+		countBar := barStyle.Render(string(bars[barHeight(b.count, maxCount)]))
+		errorBar := errorBarStyle.Render(string(bars[barHeight(b.errors, maxCount)]))
+		if i == m.selected {
+			countBar = selectedStyle.Render(countBar)
+			errorBar = selectedStyle.Render(errorBar)
+		}
+		total.WriteString(countBar)
+		errors.WriteString(errorBar)
+	}
+
+	style := lipgloss.NewStyle().PaddingLeft(padding)
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		style.Render(total.String()),
+		style.Render(errors.String()),
+		style.Render(m.axisView()),
+	)
+}
+
+// SelectBucket moves the selected bucket by direction, skipping empty
+// buckets, and returns the index of the first item in the newly selected
+// bucket. If no bucket is selected, the bucket containing the item at
+// current is used as the starting point.
+func (m Model) SelectBucket(direction int, current int) (Model, int) {
+	if len(m.buckets) == 0 {
+		return m, current
+	}
+
+	m.deselectOutside(current)
+	selected := m.selected
+	if selected < 0 && current >= 0 && current < len(m.items) {
+		selected = m.bucketOf(m.items[current].Timestamp)
+	}
+
+	for i := selected + direction; i >= 0 && i < len(m.buckets); i += direction {
+		if m.buckets[i].count > 0 {
+			m.selected = i
+			return m, m.buckets[i].first
+		}
+	}
+	return m, current
+}
+
+// deselectOutside clears the selected bucket unless it holds the item at
+// index, so moving the cursor elsewhere doesn't leave another time labelled
+func (m *Model) deselectOutside(index int) {
+	if m.selected < 0 || index < 0 || index >= len(m.items) {
+		return
+	}
+	if m.bucketOf(m.items[index].Timestamp) != m.selected {
+		m.selected = -1
+	}
+}
+
+func (m *Model) bucketItems() {
+	m.buckets = nil
+	if len(m.items) == 0 || m.Width <= padding {
+		return
+	}
+
+	m.start, m.end = m.items[0].Timestamp, m.items[0].Timestamp
+	for _, item := range m.items {
+		if item.Timestamp < m.start {
+			m.start = item.Timestamp
+		}
+		if item.Timestamp > m.end {
+			m.end = item.Timestamp
+		}
+	}
+
+	m.buckets = make([]bucket, m.Width-padding)
+	for i := range m.buckets {
+		m.buckets[i].first = -1
+	}
+
+	for i, item := range m.items {
+		b := &m.buckets[m.bucketOf(item.Timestamp)]
+		b.count++
+		if item.Error {
+			b.errors++
+		}
+		if b.first < 0 {
+			b.first = i
+		}
+	}
+
+	if m.selected >= len(m.buckets) {
+		m.selected = -1
+	}
+}
+
+// addItems counts the items from index from up to to into the buckets. The
+// buckets are only rebuilt when an item falls outside the time they span,
+// which changes every bucket's span.
+func (m *Model) addItems(from, to int) {
+	if len(m.buckets) == 0 {
+		m.bucketItems()
+		return
+	}
+	for _, item := range m.items[from:to] {
+		if item.Timestamp < m.start || item.Timestamp > m.end {
+			m.selected = -1
+			m.bucketItems()
+			return
+		}
+	}
+
+	for i := from; i < to; i++ {
+		b := &m.buckets[m.bucketOf(m.items[i].Timestamp)]
+		b.count++
+		if m.items[i].Error {
+			b.errors++
+		}
+		if b.first < 0 || i < b.first {
+			b.first = i
+		}
+	}
+}
+
+func (m Model) bucketOf(timestamp int64) int {
+	span := m.end - m.start + 1
+	return int((timestamp - m.start) * int64(len(m.buckets)) / span)
+}
+
+// axisView labels the start and end time of the histogram, or points to the
+// selected bucket and labels its start time if there is one
+func (m Model) axisView() string {
+	if m.selected >= 0 {
+		// multiplied first, a span shorter than the buckets would round to 0
+		label := m.format.Clock(m.start + (m.end-m.start+1)*int64(m.selected)/int64(len(m.buckets)))
+
+		if m.selected+len(label)+2 <= len(m.buckets) {
+			return axisStyle.Render(strings.Repeat(" ", m.selected) + "▲ " + label)
+		}
+		indent := max(0, m.selected-len(label)-1)
+		return axisStyle.Render(strings.Repeat(" ", indent) + label + " ▲")
+	}
+
+	start := m.format.Clock(m.start)
+	end := m.format.Clock(m.end)
+
+	// too narrow to label both ends
+	if len(start)+1+len(end) > len(m.buckets) {
+		return axisStyle.Render(start[:min(len(start), len(m.buckets))])
+	}
+
+	gap := max(1, len(m.buckets)-lipgloss.Width(start)-lipgloss.Width(end))
+	return axisStyle.Render(start + strings.Repeat(" ", gap) + end)
+}
+
+// barHeight scales count to one of the bar characters, any non zero count
+// gets at least the smallest bar
+func barHeight(count, maxCount int) int {
+	if count == 0 || maxCount == 0 {
+		return 0
+	}
+	return max(1, count*(len(bars)-1)/maxCount)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
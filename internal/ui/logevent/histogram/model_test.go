@@ -0,0 +1,206 @@
+package histogram
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestIsError(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"[ERROR] something broke", true},
+		{"2023-03-01T12:00:00.000Z\tabc\tERROR\tsomething broke", true},
+		{`{"level":"error","msg":"boom"}`, true},
+		{`{"level": "FATAL"}`, true},
+		{"time=12:00 level=error msg=boom", true},
+		{"FATAL: out of memory", true},
+		{"ERROR something broke", true},
+		{`{"errorType":"TypeError","errorMessage":"x is undefined"}`, true},
+		{"Runtime.ImportModuleError: cannot find module", true},
+		{"Traceback (most recent call last):", true},
+		{"handled order", false},
+		{"retrying after an error", false},
+		{`{"level":"info","msg":"no errors"}`, false},
+		{"level=info msg=ERRORS", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsError(tt.message); got != tt.want {
+			t.Errorf("IsError(%q) = %t, want %t", tt.message, got, tt.want)
+		}
+	}
+}
+
+// model returns a histogram 10 buckets wide over items at the given
+// timestamps, items with a negative timestamp are errors
+func model(timestamps ...int64) Model {
+	var items SetItemsMsg
+	for _, ts := range timestamps {
+		item := Item{Timestamp: ts}
+		if ts < 0 {
+			item = Item{Timestamp: -ts, Error: true}
+		}
+		items = append(items, item)
+	}
+
+	m := New()
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 10 + padding})
+	m, _ = m.Update(items)
+	return m
+}
+
+func TestBuckets(t *testing.T) {
+	m := model(0, 5, -15, 42, -99, 99)
+
+	want := []bucket{
+		{count: 2, first: 0},
+		{count: 1, errors: 1, first: 2},
+		{first: -1},
+		{first: -1},
+		{count: 1, first: 3},
+		{first: -1},
+		{first: -1},
+		{first: -1},
+		{first: -1},
+		{count: 2, errors: 1, first: 4},
+	}
+	if len(m.buckets) != len(want) {
+		t.Fatalf("got %d buckets, want %d", len(m.buckets), len(want))
+	}
+	for i := range want {
+		if m.buckets[i] != want[i] {
+			t.Errorf("bucket %d is %+v, want %+v", i, m.buckets[i], want[i])
+		}
+	}
+}
+
+func TestBucketsResize(t *testing.T) {
+	m := model(0, 99)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 4 + padding})
+	if len(m.buckets) != 4 {
+		t.Fatalf("got %d buckets, want 4", len(m.buckets))
+	}
+
+	m, _ = m.Update(tea.WindowSizeMsg{Width: padding})
+	if len(m.buckets) != 0 {
+		t.Errorf("got %d buckets, want none when there is no room", len(m.buckets))
+	}
+}
+
+func TestAddItems(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  tea.Msg
+		want Model // the same items counted from scratch
+	}{
+		{"append in range", AppendItemsMsg{{Timestamp: 150}, {Timestamp: 160, Error: true}}, model(100, 105, 199, 150, -160)},
+		{"append after the end", AppendItemsMsg{{Timestamp: 299}}, model(100, 105, 199, 299)},
+		{"prepend in range", PrependItemsMsg{{Timestamp: 101, Error: true}, {Timestamp: 180}}, model(-101, 180, 100, 105, 199)},
+		{"prepend before the start", PrependItemsMsg{{Timestamp: 0}}, model(0, 100, 105, 199)},
+		{"drop", DropItemsMsg{N: 2}, model(199)},
+		{"drop everything", DropItemsMsg{N: 5}, model()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := model(100, 105, 199).Update(tt.msg)
+			if !reflect.DeepEqual(m.items, tt.want.items) {
+				t.Fatalf("got items %+v, want %+v", m.items, tt.want.items)
+			}
+			if !reflect.DeepEqual(m.buckets, tt.want.buckets) {
+				t.Errorf("got buckets %+v, want %+v", m.buckets, tt.want.buckets)
+			}
+		})
+	}
+}
+
+func TestSelectBucket(t *testing.T) {
+	m := model(0, 5, 15, 42, 99)
+
+	tests := []struct {
+		name      string
+		direction int
+		want      int // index of the selected item
+		bucket    int
+	}{
+		{"from the selected item", 1, 3, 4},
+		{"skips empty buckets", 1, 4, 9},
+		{"stops at the end", 1, 4, 9},
+		{"back", -1, 3, 4},
+		{"back again", -1, 2, 1},
+		{"back to the start", -1, 0, 0},
+		{"stops at the start", -1, 0, 0},
+	}
+
+	current := 2
+	for _, tt := range tests {
+		m, current = m.SelectBucket(tt.direction, current)
+		if current != tt.want || m.selected != tt.bucket {
+			t.Fatalf("%s: selected item %d in bucket %d, want item %d in bucket %d",
+				tt.name, current, m.selected, tt.want, tt.bucket)
+		}
+	}
+
+	m, _ = m.Update(SetItemsMsg{{Timestamp: 1}})
+	if m.selected != -1 {
+		t.Errorf("bucket %d still selected after the items changed", m.selected)
+	}
+}
+
+func TestSelectItem(t *testing.T) {
+	m := model(0, 5, 15, 42, 99)
+	m, _ = m.SelectBucket(1, 0)
+	if m.selected != 1 {
+		t.Fatalf("selected bucket %d, want 1", m.selected)
+	}
+
+	m, _ = m.Update(SelectItemMsg{Index: 2})
+	if m.selected != 1 {
+		t.Errorf("bucket deselected with the cursor still in it")
+	}
+	m, _ = m.Update(SelectItemMsg{Index: 3})
+	if m.selected != -1 {
+		t.Errorf("bucket %d still selected after the cursor left it", m.selected)
+	}
+
+	// moving on from an item outside the selected bucket starts from the item
+	m, _ = m.SelectBucket(1, 1)
+	m, index := m.SelectBucket(1, 3)
+	if index != 4 || m.selected != 9 {
+		t.Errorf("selected item %d in bucket %d, want item 4 in bucket 9", index, m.selected)
+	}
+}
+
+func TestAxisLabel(t *testing.T) {
+	// fewer milliseconds than buckets, the last bucket starts in the next second
+	m := model(999, 1004)
+	m, _ = m.SelectBucket(1, 0)
+	if want := m.format.Clock(1003); !strings.Contains(m.axisView(), want) {
+		t.Errorf("got axis %q, want the bucket labelled %s", m.axisView(), want)
+	}
+}
+
+func TestView(t *testing.T) {
+	if lines := strings.Count(New().View(), "\n") + 1; lines != Height {
+		t.Errorf("empty histogram is %d lines, want %d", lines, Height)
+	}
+
+	m := model(0, -50, 99)
+	lines := strings.Split(m.View(), "\n")
+	if len(lines) != Height {
+		t.Fatalf("histogram is %d lines, want %d", len(lines), Height)
+	}
+
+	// every non empty bucket holds one item, so gets the tallest bar
+	want := []string{"  █    █   █", "       █"}
+	for i, want := range want {
+		if got := strings.TrimRight(lines[i], " "); got != want {
+			t.Errorf("line %d is %q, want %q", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package logevent
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/cloudwatch/lambda"
+)
+
+// invocationFilter limits which invocations are shown in lambda mode
+type invocationFilter int
+
+const (
+	showAllInvocations invocationFilter = iota
+	showFailedInvocations
+	showTimedOutInvocations
+	numInvocationFilters
+)
+
+func (f invocationFilter) String() string {
+	switch f {
+	case showFailedInvocations:
+		return "failed"
+	case showTimedOutInvocations:
+		return "timed out"
+	default:
+		return "all"
+	}
+}
+
+func (f invocationFilter) matches(inv lambda.Invocation) bool {
+	switch f {
+	case showFailedInvocations:
+		return inv.Failed()
+	case showTimedOutInvocations:
+		return inv.TimedOut
+	default:
+		return true
+	}
+}
+
+// filteredInvocations groups the loaded events into invocations and applies
+// the invocation filter
+func (m Model) filteredInvocations() []lambda.Invocation {
+	var invocations []lambda.Invocation
+	for _, inv := range lambda.Group(m.events) {
+		if m.invocationFilter.matches(inv) {
+			invocations = append(invocations, inv)
+		}
+	}
+	return invocations
+}
+
+// toggleLambdaMode switches between the flat event list and the grouped
+// invocation list, keeping the cursor as close as possible to the selected
+// event
+func (m Model) toggleLambdaMode() (Model, tea.Cmd) {
+	var selectedTime int64
+	if m.lambdaMode {
+		invocations := m.filteredInvocations()
+		if m.selectedEvent < len(invocations) {
+			selectedTime = invocations[m.selectedEvent].Timestamp()
+		}
+	} else if m.selectedEvent < len(m.events) {
+		selectedTime = aws.ToInt64(m.events[m.selectedEvent].Timestamp)
+	}
+
+	m.lambdaMode = !m.lambdaMode
+
+	m.selectedEvent = 0
+	if m.lambdaMode {
+		for i, inv := range m.filteredInvocations() {
+			if inv.Timestamp() <= selectedTime {
+				m.selectedEvent = i
+			}
+		}
+	} else {
+		for i, e := range m.events {
+			if aws.ToInt64(e.Timestamp) >= selectedTime {
+				m.selectedEvent = i
+				break
+			}
+		}
+	}
+
+	return m, m.showItems()
+}
+
+// nextInvocation returns the index of the next invocation in direction
+// (1 or -1) from the selected event. In lambda mode every item is an
+// invocation, otherwise the next START line is searched for.
+func (m Model) nextInvocation(direction int) int {
+	if m.lambdaMode {
+		return m.selectedEvent + direction
+	}
+
+	for i := m.selectedEvent + direction; i >= 0 && i < len(m.events); i += direction {
+		if strings.HasPrefix(aws.ToString(m.events[i].Message), "START RequestId:") {
+			return i
+		}
+	}
+	return m.selectedEvent
+}
+
+// invocationsToEvents converts invocations into a single event each so they
+// can be listed in the timestamp model
+func invocationsToEvents(invocations []lambda.Invocation) []types.OutputLogEvent {
+	var events []types.OutputLogEvent
+	for _, inv := range invocations {
+		events = append(events, types.OutputLogEvent{
+			Timestamp: aws.Int64(inv.Timestamp()),
+			Message:   aws.String(inv.RequestID),
+		})
+	}
+	return events
+}
@@ -15,6 +15,8 @@ type keyMap struct {
 	Quit         key.Binding
 	Filter       key.Binding
 	LoadMore     key.Binding
+	Head         key.Binding
+	Tail         key.Binding
 	Collapse     key.Binding
 	CollapseAll  key.Binding
 	NextWindow   key.Binding
@@ -25,30 +27,48 @@ type keyMap struct {
 	HalfPageUp   key.Binding
 	HalfPageDown key.Binding
 	Reload       key.Binding
+	Follow       key.Binding
+
+	Invocations      key.Binding
+	InvocationFilter key.Binding
+	NextInvocation   key.Binding
+	PrevInvocation   key.Binding
+	NextBucket       key.Binding
+	PrevBucket       key.Binding
+	TimeFormat       key.Binding
+	TimeZone         key.Binding
+	Wrap             key.Binding
+	CopyAs           key.Binding
+	Mark             key.Binding
+	OpenConsole      key.Binding
+	CopyConsoleURL   key.Binding
+	CopyLocator      key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Help, k.Quit}
+	return []key.Binding{k.PrevItem, k.NextItem, k.Collapse, k.Copy, k.LoadMore}
 }
 
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Help, k.Quit},
-		{k.NextWindow, k.PrevWindow, k.PrevItem, k.NextItem},
+		{k.PrevItem, k.NextItem, k.Head, k.Tail, k.Filter, k.LoadMore, k.Reload, k.Follow},
 		{k.ScrollUp, k.ScrollDown, k.PageUp, k.PageDown},
-		{k.HalfPageUp, k.HalfPageDown, k.Collapse, k.CollapseAll},
-		{k.Filter, k.Copy, k.LoadMore, k.Reload},
+		{k.HalfPageUp, k.HalfPageDown, k.Left, k.Right, k.Wrap},
+		{k.Collapse, k.CollapseAll, k.Copy, k.CopyAs, k.Mark},
+		{k.OpenConsole, k.CopyConsoleURL, k.CopyLocator},
+		{k.Invocations, k.InvocationFilter, k.NextInvocation, k.PrevInvocation},
+		{k.PrevBucket, k.NextBucket, k.TimeFormat, k.TimeZone},
 	}
 }
 
 var keys = keyMap{
 	PrevItem: key.NewBinding(
 		key.WithKeys("up", "k"),
-		key.WithHelp("↑/k", "next item"),
+		key.WithHelp("↑/k", "prev item"),
 	),
 	NextItem: key.NewBinding(
 		key.WithKeys("down", "j"),
-		key.WithHelp("↓/j", "prev item"),
+		key.WithHelp("↓/j", "next item"),
 	),
 	ScrollUp: key.NewBinding(
 		key.WithKeys("shift+up", "K"),
@@ -58,13 +78,14 @@ var keys = keyMap{
 		key.WithKeys("shift+down", "J"),
 		key.WithHelp("shift+↓/J", "scroll down"),
 	),
+	// h and l switch pages, so only the arrow keys scroll horizontally
 	Left: key.NewBinding(
-		key.WithKeys("left", "h"),
-		key.WithHelp("←/h", "scroll left"),
+		key.WithKeys("left"),
+		key.WithHelp("←", "scroll left"),
 	),
 	Right: key.NewBinding(
-		key.WithKeys("right", "l"),
-		key.WithHelp("→/l", "scroll right"),
+		key.WithKeys("right"),
+		key.WithHelp("→", "scroll right"),
 	),
 	Help: key.NewBinding(
 		key.WithKeys("?"),
@@ -82,6 +103,14 @@ var keys = keyMap{
 		key.WithKeys("L"),
 		key.WithHelp("L", "load more events"),
 	),
+	Head: key.NewBinding(
+		key.WithKeys("g", "home"),
+		key.WithHelp("gg", "oldest event"),
+	),
+	Tail: key.NewBinding(
+		key.WithKeys("G", "end"),
+		key.WithHelp("G", "newest event"),
+	),
 	Copy: key.NewBinding(
 		key.WithKeys("c"),
 		key.WithHelp("c", "copy"),
@@ -122,4 +151,64 @@ var keys = keyMap{
 		key.WithKeys("R"),
 		key.WithHelp("R", "reload events"),
 	),
+	Follow: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "follow new events"),
+	),
+	Invocations: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "group lambda invocations"),
+	),
+	InvocationFilter: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "show all/failed/timed out invocations"),
+	),
+	NextInvocation: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next invocation"),
+	),
+	PrevInvocation: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "prev invocation"),
+	),
+	NextBucket: key.NewBinding(
+		key.WithKeys(">"),
+		key.WithHelp(">", "next histogram bar"),
+	),
+	PrevBucket: key.NewBinding(
+		key.WithKeys("<"),
+		key.WithHelp("<", "prev histogram bar"),
+	),
+	TimeFormat: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "cycle timestamp format"),
+	),
+	TimeZone: key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "cycle time zone"),
+	),
+	Wrap: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "toggle line wrap"),
+	),
+	CopyAs: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "copy as..."),
+	),
+	Mark: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "mark range to copy"),
+	),
+	OpenConsole: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "open in aws console"),
+	),
+	CopyConsoleURL: key.NewBinding(
+		key.WithKeys("O"),
+		key.WithHelp("O", "copy aws console link"),
+	),
+	CopyLocator: key.NewBinding(
+		key.WithKeys("Y"),
+		key.WithHelp("Y", "copy clviewer:// link"),
+	),
 }
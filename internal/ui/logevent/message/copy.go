@@ -0,0 +1,183 @@
+package message
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/ui/clipboard"
+)
+
+// CopyFormat is the format events are copied to the clipboard in
+type CopyFormat int
+
+const (
+	// CopyDisplayed copies events as they are displayed, expanded or collapsed
+	CopyDisplayed CopyFormat = iota
+	// CopyRaw copies the unmodified messages
+	CopyRaw
+	// CopyPrettyJSON copies json messages indented, other messages unmodified
+	CopyPrettyJSON
+	// CopyNDJSON copies one json object per event with its timestamp
+	CopyNDJSON
+	// CopyMarkdown copies the events inside of a markdown code block
+	CopyMarkdown
+)
+
+func (f CopyFormat) String() string {
+	switch f {
+	case CopyRaw:
+		return "raw"
+	case CopyPrettyJSON:
+		return "pretty json"
+	case CopyNDJSON:
+		return "ndjson"
+	case CopyMarkdown:
+		return "markdown"
+	default:
+		return "displayed"
+	}
+}
+
+// CopyMessage copies the selected event, or the marked range of events if
+// there is one, to the clipboard
+type CopyMessage struct {
+	Format CopyFormat
+	// All copies every event instead of the selection
+	All bool
+	// Indexes are the events All copies when the event list is filtered, nil
+	// when it isn't
+	Indexes []int
+}
+
+// ToggleMarkMsg marks the selected event as the start of a range of events,
+// or clears the mark if it is already set
+type ToggleMarkMsg struct{}
+
+// copyMessages copies the events selected by msg to the clipboard
+func (m *Model) copyMessages(msg CopyMessage) tea.Cmd {
+	messages := m.copied(msg)
+	if len(messages) == 0 {
+		return nil
+	}
+
+	text := formatCopy(messages, msg.Format)
+	log.Printf("copied %d events as %s", len(messages), msg.Format)
+
+	m.marked = false
+	return clipboard.Write(text)
+}
+
+// copied returns the events msg copies
+func (m Model) copied(msg CopyMessage) []message {
+	if len(m.messages) == 0 {
+		return nil
+	}
+
+	if msg.All && msg.Indexes != nil {
+		messages := make([]message, 0, len(msg.Indexes))
+		for _, i := range msg.Indexes {
+			if i >= 0 && i < len(m.messages) {
+				messages = append(messages, m.messages[i])
+			}
+		}
+		return messages
+	}
+
+	start, end := m.selectedEvent, m.selectedEvent
+	switch {
+	case msg.All:
+		start, end = 0, len(m.messages)-1
+	case m.marked:
+		start, end = m.markedRange()
+	}
+	return m.messages[start : end+1]
+}
+
+// markedRange returns the first and last index of the marked events
+func (m Model) markedRange() (int, int) {
+	if !m.marked {
+		return m.selectedEvent, m.selectedEvent
+	}
+	if m.mark < m.selectedEvent {
+		return m.mark, m.selectedEvent
+	}
+	return m.selectedEvent, m.mark
+}
+
+func formatCopy(messages []message, format CopyFormat) string {
+	var out []string
+
+	switch format {
+	case CopyRaw:
+		for _, msg := range messages {
+			out = append(out, msg.content)
+		}
+	case CopyPrettyJSON:
+		for _, msg := range messages {
+			out = append(out, prettyMessage(msg))
+		}
+	case CopyNDJSON:
+		for _, msg := range messages {
+			out = append(out, ndjson(msg))
+		}
+	case CopyMarkdown:
+		lang := "json"
+		for _, msg := range messages {
+			if !json.Valid([]byte(msg.content)) {
+				lang = ""
+			}
+			out = append(out, prettyMessage(msg))
+		}
+		return fmt.Sprintf("```%s\n%s\n```", lang, strings.Join(out, "\n"))
+	default:
+		for _, msg := range messages {
+			out = append(out, removeANSIColorCodes(formatItem(msg)))
+		}
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// prettyMessage indents the json of a message, or of each line of a message
+// that groups several events
+func prettyMessage(msg message) string {
+	if msg.lines == nil {
+		return prettyJSON(msg.content)
+	}
+
+	var lines []string
+	for _, line := range msg.lines {
+		lines = append(lines, prettyJSON(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// prettyJSON indents in if it is json, otherwise in is returned unmodified
+func prettyJSON(in string) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(in), "", "  "); err != nil {
+		return strings.TrimRight(in, "\n")
+	}
+	return out.String()
+}
+
+func ndjson(msg message) string {
+	var content interface{} = strings.TrimRight(msg.content, "\n")
+	if json.Valid([]byte(msg.content)) {
+		content = json.RawMessage(msg.content)
+	}
+
+	line, err := json.Marshal(struct {
+		Timestamp int64       `json:"timestamp"`
+		Message   interface{} `json:"message"`
+	}{msg.timestamp, content})
+	if err != nil {
+		return ""
+	}
+	return string(line)
+}
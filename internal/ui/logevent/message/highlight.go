@@ -0,0 +1,25 @@
+package message
+
+// HighlightMsg highlights the messages Match returns true for, such as the
+// events matched by a filter pattern. A nil Match clears the highlights, as
+// does resetting the messages.
+type HighlightMsg struct {
+	Match func(message string) bool
+}
+
+// highlighted returns true if msg, or any event grouped under it, is
+// highlighted
+func (m Model) highlighted(msg *message) bool {
+	if m.highlight == nil {
+		return false
+	}
+	if len(msg.lines) == 0 {
+		return m.highlight(msg.content)
+	}
+	for _, line := range msg.lines {
+		if m.highlight(line) {
+			return true
+		}
+	}
+	return false
+}
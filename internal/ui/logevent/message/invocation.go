@@ -0,0 +1,79 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/charmbracelet/lipgloss"
+
+	"clviewer/internal/cloudwatch/lambda"
+)
+
+var (
+	failedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("160"))
+	successStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("34"))
+)
+
+// LoadMoreInvocationsMsg loads lambda invocations, each displayed as a
+// collapsible summary of the REPORT line
+type LoadMoreInvocationsMsg struct {
+	Invocations []lambda.Invocation
+	Collapsed   bool
+}
+
+func invocationsToMessages(invocations []lambda.Invocation, collapsed bool) []message {
+	var messages []message
+	for _, inv := range invocations {
+		var lines []string
+		for _, e := range inv.Events {
+			lines = append(lines, strings.TrimRight(aws.ToString(e.Message), "\n"))
+		}
+
+		messages = append(messages, message{
+			title:     invocationTitle(inv),
+			content:   strings.Join(lines, "\n"),
+			lines:     lines,
+			timestamp: inv.Timestamp(),
+			collapsed: collapsed,
+		})
+	}
+	return messages
+}
+
+// invocationTitle summarises an invocation on a single line
+func invocationTitle(inv lambda.Invocation) string {
+	id := inv.RequestID
+	if id == "" {
+		id = "(no request id)"
+	}
+
+	status := successStyle.Render("✓")
+	switch {
+	case inv.TimedOut:
+		status = failedStyle.Render("timed out")
+	case inv.Errors > 0:
+		status = failedStyle.Render(fmt.Sprintf("✗ %d errors", inv.Errors))
+	case inv.Failed():
+		status = failedStyle.Render("✗ " + inv.Report.Status)
+	}
+
+	title := fmt.Sprintf("%s %s", status, id)
+
+	r := inv.Report
+	if r == nil {
+		return title + " (in progress)"
+	}
+
+	title += fmt.Sprintf(
+		"  %.2f ms (billed %.0f ms)  mem %d/%d MB",
+		r.Duration,
+		r.BilledDuration,
+		r.MaxMemoryUsed,
+		r.MemorySize,
+	)
+	if r.InitDuration > 0 {
+		title += fmt.Sprintf("  init %.2f ms", r.InitDuration)
+	}
+	return title
+}
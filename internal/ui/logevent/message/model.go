@@ -3,17 +3,17 @@ package message
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"regexp"
 	"strings"
 
 	"github.com/TylerBrock/colorjson"
-	"github.com/atotto/clipboard"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"clviewer/internal/ui/mouse"
 )
 
 const useHighPerformanceRenderer = false
@@ -35,6 +35,10 @@ var (
 	}()
 
 	lineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("98"))
+
+	endStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	viewportStyle = lipgloss.NewStyle().Margin(1, 1)
 )
 
 type Model struct {
@@ -44,12 +48,33 @@ type Model struct {
 	Viewport      viewport.Model
 	messages      []message
 	selectedEvent int
+	xOffset       int  // columns scrolled to the right
+	wrap          bool // wrap lines longer than the viewport
+	mark          int  // start of the marked range of events
+	marked        bool // whether mark is set
+	end           bool // the newest events have been loaded
+	highlight     func(message string) bool
+
+	yOffset  int // first line shown, the viewport only holds the lines it shows
+	lines    int // lines of every message
+	measured struct {
+		width int
+		wrap  bool
+	} // what the heights of the messages were measured for
 }
 
 type message struct {
-	content    string
-	collapsed  bool
-	lineNumber int
+	title     string   // summary shown when collapsed, empty for plain events
+	content   string   // raw message
+	lines     []string // raw messages grouped under title
+	timestamp int64
+	collapsed bool
+
+	line        int    // first line of the message, set by layout
+	height      int    // lines once rendered, zero until measured
+	formatted   string // formatItem of the message, empty until formatted
+	rendered    string // empty until rendered
+	renderedFor renderKey
 }
 
 func New(title string, events string) Model {
@@ -72,44 +97,53 @@ type LoadMoreEventsMsg struct {
 	Collapsed    bool
 }
 
+// PrependEventsMsg adds events before the messages, keeping the selected and
+// marked events
+type PrependEventsMsg struct {
+	AwsLogEvents []types.OutputLogEvent
+	Collapsed    bool
+}
+
 type ToggleCollapsedMsg struct {
 	ToggleAll bool
 }
 
 type ResetMsg struct{}
 
+// DropEventsMsg drops the N oldest events, keeping the selected event selected
+type DropEventsMsg struct{ N int }
+
+// EndOfStreamMsg sets whether the newest events have been loaded, which is
+// marked below the last event
+type EndOfStreamMsg struct{ Reached bool }
+
 type NextEventMsg struct{ Index int }
 
 type PrevEventMsg struct{ Index int }
 
-type CopyMessage struct{}
+type SelectEventMsg struct{ Index int }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
-	var (
-		cmd  tea.Cmd
-		cmds []tea.Cmd
-	)
+	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.String() == "J" || msg.String() == "shift+down" {
-			m.Viewport.LineDown(3)
-		}
-		if msg.String() == "K" || msg.String() == "shift+up" {
-			m.Viewport.LineUp(3)
-		}
-		m.Viewport, cmd = m.Viewport.Update(msg)
+		m.layout()
+		m.handleScrollKey(msg)
+	case mouse.Msg:
+		m = m.handleMouse(msg)
 	case tea.WindowSizeMsg:
 		headerHeight := lipgloss.Height(m.headerView())
 		footerHeight := lipgloss.Height(m.footerView())
-		verticalMarginHeight := headerHeight + footerHeight
+		verticalMarginHeight := headerHeight + footerHeight + viewportStyle.GetVerticalFrameSize()
+		width := msg.Width - viewportStyle.GetHorizontalFrameSize()
 
 		if !m.Ready {
-			m.Viewport = viewport.New(msg.Width, msg.Height-verticalMarginHeight)
+			m.Viewport = viewport.New(width, msg.Height-verticalMarginHeight)
 			m.Viewport.HighPerformanceRendering = useHighPerformanceRenderer
 			m.Ready = true
 		} else {
-			m.Viewport.Width = msg.Width
+			m.Viewport.Width = width
 			m.Viewport.Height = msg.Height - verticalMarginHeight
 		}
 
@@ -119,6 +153,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 	case ResetMsg:
 		m.selectedEvent = 0
+		m.marked = false
+		m.end = false
+		m.highlight = nil
+		m.yOffset = 0
 		m.messages = []message{}
 	case NextEventMsg:
 		m.selectedEvent = msg.Index
@@ -126,21 +164,47 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case PrevEventMsg:
 		m.selectedEvent = msg.Index
 		m.centerViewOnItem()
+	case SelectEventMsg:
+		if msg.Index < 0 || msg.Index >= len(m.messages) {
+			break
+		}
+		m.selectedEvent = msg.Index
+		m.centerViewOnItem()
 	case LoadMoreEventsMsg:
 		m.messages = append(
 			m.messages,
 			eventsToMessages(msg.AwsLogEvents, msg.Collapsed)...,
 		)
+	case PrependEventsMsg:
+		m.messages = append(
+			eventsToMessages(msg.AwsLogEvents, msg.Collapsed),
+			m.messages...,
+		)
+		m.selectedEvent += len(msg.AwsLogEvents)
+		m.mark += len(msg.AwsLogEvents)
+	case LoadMoreInvocationsMsg:
+		m.messages = append(
+			m.messages,
+			invocationsToMessages(msg.Invocations, msg.Collapsed)...,
+		)
+	case DropEventsMsg:
+		m.dropEvents(min(msg.N, len(m.messages)))
+	case EndOfStreamMsg:
+		m.end = msg.Reached
+	case HighlightMsg:
+		m.highlight = msg.Match
+	case ScrollHorizontalMsg:
+		m.scrollHorizontal(msg.Columns)
+	case ToggleWrapMsg:
+		m.wrap = !m.wrap
+		m.xOffset = 0
+	case ToggleMarkMsg:
+		m.mark = m.selectedEvent
+		m.marked = !m.marked && len(m.messages) > 0
 	case CopyMessage:
-		eventMsg := m.messages[m.selectedEvent].content
-		collapsed := m.messages[m.selectedEvent].collapsed
-
-		eventMsg = FormatMessage(eventMsg, !collapsed)
-		eventMsg = removeANSIColorCodes(eventMsg)
-
-		if err := clipboard.WriteAll(eventMsg); err != nil {
-			log.Printf("error with clipboard: %s", err)
-		}
+		return m, m.copyMessages(msg)
+	case RestoreStateMsg:
+		m.restoreState(msg)
 		return m, nil
 	case ToggleCollapsedMsg:
 		// break if no messages have been set
@@ -151,6 +215,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		// Toggle one item
 		if !msg.ToggleAll {
 			m.messages[m.selectedEvent].collapsed = !m.messages[m.selectedEvent].collapsed
+			m.messages[m.selectedEvent].invalidate()
 			break
 		}
 
@@ -165,15 +230,16 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 				}
 			}
 			for k := range m.messages {
-				m.messages[k].collapsed = collapseItems
+				if m.messages[k].collapsed != collapseItems {
+					m.messages[k].collapsed = collapseItems
+					m.messages[k].invalidate()
+				}
 			}
 
 		}
 	}
 
-	m.Viewport.SetContent(m.renderContent())
-	m.Viewport, cmd = m.Viewport.Update(msg)
-	cmds = append(cmds, cmd)
+	m.renderView()
 
 	return m, tea.Batch(cmds...)
 }
@@ -183,12 +249,10 @@ func (m Model) View() string {
 		return "\n  Initializing..."
 	}
 
-	viewPortView := lipgloss.NewStyle().Margin(1, 1)
-
 	return fmt.Sprintf(
 		"%s\n%s\n%s",
 		m.headerView(),
-		viewPortView.Render(m.Viewport.View()),
+		viewportStyle.Render(m.Viewport.View()),
 		m.footerView(),
 	)
 }
@@ -196,87 +260,47 @@ func (m Model) View() string {
 func (m Model) headerView() string {
 	title := titleStyle.Render(m.Title)
 	line := lineStyle.Render(
-		strings.Repeat("─", max(0, m.Viewport.Width-lipgloss.Width(title))),
+		strings.Repeat("─", max(0, m.width()-lipgloss.Width(title))),
 	)
 	return lipgloss.JoinHorizontal(lipgloss.Center, title, line)
 }
 
 func (m Model) footerView() string {
-	info := infoStyle.Render(fmt.Sprintf("%3.f%%", m.Viewport.ScrollPercent()*100))
-	line := lineStyle.Render(strings.Repeat("─", max(0, m.Viewport.Width-lipgloss.Width(info))))
-	return lipgloss.JoinHorizontal(lipgloss.Center, line, info)
-}
-
-func (m *Model) centerViewOnItem() {
-	itemHeightOffset := lipgloss.Height(FormatMessage(
-		m.messages[m.selectedEvent].content,
-		!m.messages[m.selectedEvent].collapsed,
-	))
-
-	if itemHeightOffset > m.Viewport.Height {
-		m.Viewport.SetYOffset(max(
-			0,
-			m.messages[m.selectedEvent].lineNumber-2, // TODO use const?
-		))
-		return
+	status := fmt.Sprintf("%3.f%%", m.scrollPercent()*100)
+	switch {
+	case m.wrap:
+		status = "wrap " + status
+	case m.xOffset > 0:
+		status = fmt.Sprintf("col %d %s", m.xOffset+1, status)
 	}
 
-	m.Viewport.SetYOffset(max(
-		0,
-		m.messages[m.selectedEvent].lineNumber-(m.Viewport.Height/2)+(itemHeightOffset/2),
-	))
+	info := infoStyle.Render(status)
+	line := lineStyle.Render(strings.Repeat("─", max(0, m.width()-lipgloss.Width(info))))
+	return lipgloss.JoinHorizontal(lipgloss.Center, line, info)
 }
 
-func (m *Model) renderContent() string {
-	var content string
-
-	for i, event := range m.messages {
-		formattedItem := FormatMessage(
-			event.content,
-			!event.collapsed,
-		)
-
-		// Style if item is unselected
-		style := lipgloss.NewStyle().
-			BorderLeft(false).
-			BorderStyle(lipgloss.NormalBorder()).
-			BorderLeftForeground(lipgloss.Color("237")).
-			PaddingLeft(3).
-			PaddingRight(m.Viewport.Width - lipgloss.Width(formattedItem) - 3)
-
-		// Style if item is selected
-		if m.selectedEvent == i {
-			style = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("127")).
-				BorderLeft(true).
-				BorderStyle(lipgloss.NormalBorder()).
-				BorderLeftForeground(lipgloss.Color("127")).
-				PaddingLeft(3).
-				PaddingRight(m.Viewport.Width - lipgloss.Width(formattedItem) - 4)
-		}
-
-		// format collapsed items
-		if lipgloss.Height(formattedItem) == 1 {
-			bgColor := "232"
-			if i%2 == 0 {
-				bgColor = "235"
-			}
-			formattedItem = style.
-				Background(lipgloss.Color(bgColor)).
-				Bold(true).
-				Render(formattedItem)
-		} else {
-			// format expanded items
-			formattedItem = style.Render(formattedItem)
-		}
+// Selected returns the index of the selected event
+func (m Model) Selected() int {
+	return m.selectedEvent
+}
 
-		// Set line number
-		m.messages[i].lineNumber = lipgloss.Height(content) + 1
+// width is the width of the model, the viewport and its margin
+func (m Model) width() int {
+	return m.Viewport.Width + viewportStyle.GetHorizontalFrameSize()
+}
 
-		content += formattedItem + "\n"
+func (m *Model) centerViewOnItem() {
+	if len(m.messages) == 0 {
+		return
+	}
+	m.layout()
+	selected := m.messages[m.selectedEvent]
 
+	if selected.height > m.Viewport.Height {
+		m.yOffset = selected.line
+		return
 	}
-	return content
+	m.yOffset = max(0, selected.line+centerBias-(m.Viewport.Height/2)+(selected.height/2))
 }
 
 func max(a, b int) int {
@@ -286,25 +310,64 @@ func max(a, b int) int {
 	return b
 }
 
-func FormatMessage(in string, formatAsJson bool) string {
-	in = strings.ReplaceAll(in, "\t", " ")
-	in = strings.ReplaceAll(in, "\n", " ")
+// formatItem formats a message as it is displayed in the viewport
+func formatItem(m message) string {
+	if m.title == "" {
+		return FormatMessage(m.content, !m.collapsed)
+	}
+	if m.collapsed {
+		return m.title
+	}
 
-	if in[0] == '{' && formatAsJson {
-		return formatJson(in)
+	lines := []string{m.title}
+	for _, line := range m.lines {
+		line = FormatMessage(line, true)
+		lines = append(lines, "  "+strings.ReplaceAll(line, "\n", "\n  "))
 	}
-	return in
+	return strings.Join(lines, "\n")
 }
 
-func formatJson(in string) string {
+// FormatMessage formats a message as a single line, or when expanded as
+// indented json or as text with its original line breaks
+func FormatMessage(in string, expanded bool) string {
+	if !expanded {
+		in = strings.ReplaceAll(in, "\t", " ")
+		return strings.ReplaceAll(in, "\n", " ")
+	}
+
+	if len(in) > 0 && in[0] == '{' {
+		if formatted, ok := formatJson(in); ok {
+			return formatted
+		}
+	}
+	return formatText(in)
+}
+
+func formatJson(in string) (string, bool) {
 	var obj map[string]interface{}
-	json.Unmarshal([]byte(in), &obj)
+	if err := json.Unmarshal([]byte(in), &obj); err != nil {
+		return "", false
+	}
 
 	f := colorjson.NewFormatter()
 	f.Indent = 2
 
 	s, _ := f.Marshal(obj)
-	return string(s)
+	return string(s), true
+}
+
+// formatText keeps the line breaks and indentation of multi-line messages,
+// stack traces have their framework frames folded
+func formatText(in string) string {
+	in = strings.ReplaceAll(in, "\r\n", "\n")
+	in = strings.ReplaceAll(in, "\t", "    ")
+	in = strings.TrimRight(in, "\n ")
+
+	lines := strings.Split(in, "\n")
+	if isStackTrace(lines) {
+		return formatStackTrace(lines)
+	}
+	return in
 }
 
 func removeANSIColorCodes(in string) string {
@@ -318,9 +381,9 @@ func eventsToMessages(logEvents []types.OutputLogEvent, collaped bool) []message
 		events = append(
 			events,
 			message{
-				content:    aws.ToString(logEvents[k].Message),
-				collapsed:  collaped,
-				lineNumber: k,
+				content:   aws.ToString(logEvents[k].Message),
+				timestamp: aws.ToInt64(logEvents[k].Timestamp),
+				collapsed: collaped,
 			},
 		)
 	}
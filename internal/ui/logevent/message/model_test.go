@@ -0,0 +1,219 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// events returns n events alternating between json and multi-line text
+func events(n, from int) []types.OutputLogEvent {
+	events := make([]types.OutputLogEvent, n)
+	for i := range events {
+		message := fmt.Sprintf(`{"level":"info","request":%d,"path":"/orders"}`, from+i)
+		if i%2 == 1 {
+			message = fmt.Sprintf("handled request %d\n  in %dms", from+i, 10+i%100)
+		}
+		events[i] = types.OutputLogEvent{
+			Timestamp: aws.Int64(int64(from+i) * 1000),
+			Message:   aws.String(message),
+		}
+	}
+	return events
+}
+
+// loaded returns a model of n events in a typical window
+func loaded(n int) Model {
+	m := New("Log Messages", "...")
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.Update(LoadMoreEventsMsg{AwsLogEvents: events(n, 0), Collapsed: true})
+	return m
+}
+
+// rendered returns the indexes of the messages that have been rendered
+func rendered(m Model) []int {
+	var indexes []int
+	for i, msg := range m.messages {
+		if msg.rendered != "" {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// formatted returns the number of messages that have been formatted
+func formatted(m Model) int {
+	n := 0
+	for _, msg := range m.messages {
+		if msg.formatted != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRendersVisibleMessages(t *testing.T) {
+	m := loaded(1000)
+	if got := rendered(m); len(got) == 0 || len(got) > m.Viewport.Height {
+		t.Fatalf("rendered messages %v, want at most the %d in the viewport", got, m.Viewport.Height)
+	}
+	if got := formatted(m); got > m.Viewport.Height {
+		t.Fatalf("formatted %d messages, want at most the %d in the viewport", got, m.Viewport.Height)
+	}
+
+	m, _ = m.Update(SelectEventMsg{Index: 500})
+	if m.messages[500].rendered == "" {
+		t.Error("selected message wasn't rendered")
+	}
+	if !strings.Contains(m.View(), `"request":500`) {
+		t.Error("selected message isn't in the view")
+	}
+	if m.lines != 1001 {
+		t.Errorf("got %d lines, want a line for each message and the last line break", m.lines)
+	}
+}
+
+func TestCollapseInvalidates(t *testing.T) {
+	m := loaded(10)
+	m, _ = m.Update(ToggleCollapsedMsg{})
+	if m.messages[0].height == 1 {
+		t.Fatal("expanded message is still measured as one line")
+	}
+	if !strings.Contains(m.View(), `"request": 0`) {
+		t.Error("expanded message isn't formatted as indented json")
+	}
+}
+
+func TestDropEvents(t *testing.T) {
+	m := loaded(100)
+	m, _ = m.Update(SelectEventMsg{Index: 60})
+	top := m.messageAt(m.yOffset)
+
+	m, _ = m.Update(DropEventsMsg{N: 40})
+	if len(m.messages) != 60 || m.Selected() != 20 {
+		t.Fatalf("got %d messages with %d selected, want 60 with 20 selected", len(m.messages), m.Selected())
+	}
+	if got := m.messageAt(m.yOffset); got != top-40 {
+		t.Errorf("got message %d at the top of the view, want %d", got, top-40)
+	}
+}
+
+func benchmarkSizes(b *testing.B, fn func(b *testing.B, n int)) {
+	for _, n := range []int{1000, 10000, 50000} {
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) { fn(b, n) })
+	}
+}
+
+// BenchmarkSelect moves the selection down one event and draws the view, as
+// holding down j does
+func BenchmarkSelect(b *testing.B) {
+	benchmarkSizes(b, func(b *testing.B, n int) {
+		m := loaded(n)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m, _ = m.Update(NextEventMsg{Index: i % n})
+			_ = m.View()
+		}
+	})
+}
+
+// BenchmarkLoadPage appends a page of events, as each poll does while
+// following
+func BenchmarkLoadPage(b *testing.B) {
+	benchmarkSizes(b, func(b *testing.B, n int) {
+		m := loaded(n)
+		page := events(200, n)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			loaded := m
+			loaded.messages = append([]message{}, m.messages...)
+			b.StartTimer()
+			loaded, _ = loaded.Update(LoadMoreEventsMsg{AwsLogEvents: page, Collapsed: true})
+			_ = loaded.View()
+		}
+	})
+}
+
+// BenchmarkToggleExpanded expands and collapses the selected event
+func BenchmarkToggleExpanded(b *testing.B) {
+	benchmarkSizes(b, func(b *testing.B, n int) {
+		m := loaded(n)
+		m, _ = m.Update(SelectEventMsg{Index: n / 2})
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m, _ = m.Update(ToggleCollapsedMsg{})
+			_ = m.View()
+		}
+	})
+}
+
+func TestHighlight(t *testing.T) {
+	m := loaded(10)
+	m, _ = m.Update(HighlightMsg{Match: func(message string) bool {
+		return strings.HasPrefix(message, "handled")
+	}})
+	for _, i := range rendered(m) {
+		if got, want := m.messages[i].renderedFor.highlighted, i%2 == 1; got != want {
+			t.Errorf("message %d highlighted %t, want %t", i, got, want)
+		}
+	}
+
+	m, _ = m.Update(HighlightMsg{})
+	for _, i := range rendered(m) {
+		if m.messages[i].renderedFor.highlighted {
+			t.Errorf("message %d is still highlighted once cleared", i)
+		}
+	}
+}
+
+func TestCopied(t *testing.T) {
+	m := loaded(10)
+	m, _ = m.Update(SelectEventMsg{Index: 4})
+
+	tests := []struct {
+		name string
+		msg  CopyMessage
+		want []int64
+	}{
+		{"selected", CopyMessage{}, []int64{4000}},
+		{"all", CopyMessage{All: true}, []int64{0, 1000, 2000, 3000, 4000, 5000, 6000, 7000, 8000, 9000}},
+		{"filtered", CopyMessage{All: true, Indexes: []int{1, 7}}, []int64{1000, 7000}},
+		{"filtered out", CopyMessage{All: true, Indexes: []int{}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			for _, msg := range m.copied(tt.msg) {
+				got = append(got, msg.timestamp)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("copied %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrollHorizontal(t *testing.T) {
+	m := loaded(10)
+	m, _ = m.Update(ScrollHorizontalMsg{Columns: HorizontalScrollStep})
+	if m.xOffset != 0 {
+		t.Errorf("scrolled to column %d, want 0 as every line fits", m.xOffset)
+	}
+
+	long := events(1, 10)
+	long[0].Message = aws.String(strings.Repeat("x", 200))
+	m, _ = m.Update(LoadMoreEventsMsg{AwsLogEvents: long, Collapsed: true})
+	m, _ = m.Update(SelectEventMsg{Index: 10})
+	m, _ = m.Update(ScrollHorizontalMsg{Columns: 1000})
+	if want := m.widestLine() - m.Viewport.Width; want <= 0 || m.xOffset != want {
+		t.Errorf("scrolled to column %d, want %d to end at the widest line", m.xOffset, want)
+	}
+	if !strings.Contains(m.View(), "xxx") {
+		t.Error("the end of the widest line isn't in the view")
+	}
+}
@@ -0,0 +1,32 @@
+package message
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"clviewer/internal/ui/mouse"
+)
+
+// EventAt returns the index of the event drawn at row y of the model, using
+// the line offsets recorded by layout
+func (m Model) EventAt(y int) (int, bool) {
+	top := lipgloss.Height(m.headerView()) + viewportStyle.GetMarginTop()
+	if y < top || y >= top+m.Viewport.Height {
+		return 0, false
+	}
+
+	i := m.messageAt(m.yOffset + y - top)
+	return i, i < len(m.messages)
+}
+
+// handleMouse scrolls the viewport with the wheel
+func (m Model) handleMouse(msg mouse.Msg) Model {
+	m.layout()
+	switch msg.Type {
+	case tea.MouseWheelUp:
+		m.scrollBy(-mouse.WheelLines)
+	case tea.MouseWheelDown:
+		m.scrollBy(mouse.WheelLines)
+	}
+	return m
+}
@@ -0,0 +1,310 @@
+package message
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"clviewer/internal/ui/ansi"
+)
+
+// Only the messages in the viewport are formatted and rendered. Every message
+// is measured so the viewport can be scrolled through all of them, but a
+// message's height only changes with its collapsed state, the width and
+// wrapping, so it's kept along with the formatted and rendered text of the
+// messages in view until one of them changes.
+
+// endOfStream is drawn below the last message once the newest events have
+// loaded
+const endOfStream = "— end of stream —"
+
+// centerBias is how many lines below the middle of the viewport a selected
+// message is centred
+const centerBias = 2
+
+// renderKey is everything a rendered message depends on besides its text
+type renderKey struct {
+	width       int
+	xOffset     int
+	wrap        bool
+	selected    bool
+	marked      bool
+	highlighted bool
+	even        bool
+}
+
+// invalidate drops the formatted and rendered text of the message, when its
+// collapsed state changes
+func (msg *message) invalidate() {
+	msg.formatted = ""
+	msg.rendered = ""
+	msg.height = 0
+}
+
+// format returns the message as it's displayed, before it's styled, and keeps
+// it until the message is invalidated
+func (msg *message) format() string {
+	if msg.formatted == "" {
+		msg.formatted = formatItem(*msg)
+	}
+	return msg.formatted
+}
+
+// layout measures the messages whose height isn't known and sets the line of
+// every message
+func (m *Model) layout() {
+	if m.measured.width != m.Viewport.Width || m.measured.wrap != m.wrap {
+		for i := range m.messages {
+			m.messages[i].height = 0
+		}
+		m.measured.width, m.measured.wrap = m.Viewport.Width, m.wrap
+	}
+
+	line := 0
+	for i := range m.messages {
+		msg := &m.messages[i]
+		if msg.height == 0 {
+			msg.height = m.measure(msg)
+		}
+		msg.line = line
+		line += msg.height
+	}
+	if m.end {
+		line++
+	}
+	// the content ends with a line break, which the viewport counts as a line
+	m.lines = line + 1
+}
+
+// measure returns the number of lines msg takes once rendered. Wrapped
+// messages are wrapped to the same width whether they're selected or not, so
+// their height doesn't depend on the selection.
+//
+// Collapsed messages are a single line unless wrapped, and are only formatted
+// to be measured when they are, without keeping the formatted text as most
+// are never in view.
+func (m Model) measure(msg *message) int {
+	if msg.collapsed && !m.wrap {
+		return 1
+	}
+
+	formatted := msg.formatted
+	switch {
+	case !msg.collapsed:
+		formatted = msg.format()
+	case formatted == "":
+		formatted = formatItem(*msg)
+	}
+
+	if !m.wrap {
+		return lipgloss.Height(formatted)
+	}
+	return lipgloss.Height(lipgloss.NewStyle().Width(m.textWidth()).Render(formatted))
+}
+
+// textWidth is the width wrapped messages are wrapped to, inside the left
+// border and padding
+func (m Model) textWidth() int {
+	return max(1, m.Viewport.Width-4)
+}
+
+// render returns the message at index i styled for the viewport, rendering
+// it again only if its text or style has changed since it was last rendered
+func (m *Model) render(i int) string {
+	msg := &m.messages[i]
+	start, end := m.markedRange()
+	key := renderKey{
+		width:       m.Viewport.Width,
+		xOffset:     m.xOffset,
+		wrap:        m.wrap,
+		selected:    m.selectedEvent == i,
+		marked:      m.marked && start <= i && i <= end,
+		highlighted: m.highlighted(msg),
+		even:        i%2 == 0,
+	}
+	if msg.rendered != "" && msg.renderedFor == key {
+		return msg.rendered
+	}
+
+	msg.rendered = m.style(msg.format(), key)
+	msg.renderedFor = key
+	return msg.rendered
+}
+
+// style renders a formatted message as it's drawn in the viewport
+func (m Model) style(formattedItem string, k renderKey) string {
+	if !k.wrap {
+		formattedItem = ansi.CutLeft(formattedItem, k.xOffset)
+	}
+
+	// Style if item is unselected
+	style := lipgloss.NewStyle().
+		BorderLeft(false).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeftForeground(lipgloss.Color("237")).
+		PaddingLeft(3).
+		PaddingRight(k.width - lipgloss.Width(formattedItem) - 3)
+	if k.wrap {
+		// wrapped as narrow as the selected item
+		style = style.PaddingRight(1).Width(k.width)
+	}
+
+	// Style if item is highlighted, unless it's marked
+	if k.highlighted && !k.marked {
+		style = style.
+			Foreground(lipgloss.Color("42")).
+			BorderLeft(true).
+			BorderLeftForeground(lipgloss.Color("42")).
+			PaddingRight(k.width - lipgloss.Width(formattedItem) - 4)
+		if k.wrap {
+			style = style.PaddingRight(0).Width(k.width - 1)
+		}
+	}
+
+	// Style if item is in the marked range
+	if k.marked {
+		style = style.
+			BorderLeft(true).
+			BorderLeftForeground(lipgloss.Color("69")).
+			PaddingRight(k.width - lipgloss.Width(formattedItem) - 4)
+		if k.wrap {
+			style = style.PaddingRight(0).Width(k.width - 1)
+		}
+	}
+
+	// Style if item is selected
+	if k.selected {
+		style = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("127")).
+			BorderLeft(true).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderLeftForeground(lipgloss.Color("127")).
+			PaddingLeft(3).
+			PaddingRight(k.width - lipgloss.Width(formattedItem) - 4)
+		if k.wrap {
+			// leave room for the left border
+			style = style.PaddingRight(0).Width(k.width - 1)
+		}
+	}
+
+	// format collapsed items
+	if lipgloss.Height(formattedItem) == 1 {
+		bgColor := "232"
+		if k.even {
+			bgColor = "235"
+		}
+		return style.
+			Background(lipgloss.Color(bgColor)).
+			Bold(true).
+			Render(formattedItem)
+	}
+	// format expanded items
+	return style.Render(formattedItem)
+}
+
+// renderView sets the content of the viewport to the messages it shows
+func (m *Model) renderView() {
+	m.layout()
+	m.yOffset = max(0, min(m.yOffset, m.lines-m.Viewport.Height))
+
+	first := m.messageAt(m.yOffset)
+	start := m.lines - 1
+	if m.end {
+		start--
+	}
+	if first < len(m.messages) {
+		start = m.messages[first].line
+	}
+
+	var b strings.Builder
+	bottom := m.yOffset + m.Viewport.Height
+	for i := first; i < len(m.messages) && m.messages[i].line < bottom; i++ {
+		b.WriteString(m.render(i))
+		b.WriteString("\n")
+	}
+	if m.end && m.lines-2 < bottom {
+		b.WriteString(endStyle.
+			Width(m.Viewport.Width).
+			Align(lipgloss.Center).
+			Render(endOfStream) + "\n")
+	}
+
+	m.Viewport.SetContent(b.String())
+	m.Viewport.SetYOffset(m.yOffset - start)
+}
+
+// dropEvents drops the first n messages, keeping the lines below them in
+// place
+func (m *Model) dropEvents(n int) {
+	if n == 0 {
+		return
+	}
+	m.layout()
+	dropped := m.lines - 1
+	if n < len(m.messages) {
+		dropped = m.messages[n].line
+	}
+
+	// copied so the dropped messages can be freed
+	m.messages = append([]message(nil), m.messages[n:]...)
+	m.selectedEvent = max(0, m.selectedEvent-n)
+	m.mark -= n
+	m.marked = m.marked && m.mark >= 0
+	m.yOffset = max(0, m.yOffset-dropped)
+}
+
+// messageAt returns the index of the message at line, or the number of
+// messages if it's below the last one
+func (m Model) messageAt(line int) int {
+	return sort.Search(len(m.messages), func(i int) bool {
+		return m.messages[i].line+m.messages[i].height > line
+	})
+}
+
+// scrollBy scrolls the viewport down by lines, or up if negative
+func (m *Model) scrollBy(lines int) {
+	m.yOffset = max(0, min(m.yOffset+lines, m.lines-m.Viewport.Height))
+}
+
+// scrollPercent is how far down the messages the viewport is scrolled
+func (m Model) scrollPercent() float64 {
+	if m.Viewport.Height >= m.lines {
+		return 1.0
+	}
+	percent := float64(m.yOffset) / float64(m.lines-m.Viewport.Height)
+	return math.Max(0, math.Min(1, percent))
+}
+
+// handleScrollKey scrolls the viewport with the keys of its key map
+func (m *Model) handleScrollKey(msg tea.KeyMsg) {
+	k := m.Viewport.KeyMap
+	switch {
+	case msg.String() == "J" || msg.String() == "shift+down":
+		m.scrollBy(3)
+	case msg.String() == "K" || msg.String() == "shift+up":
+		m.scrollBy(-3)
+	case key.Matches(msg, k.PageDown):
+		m.scrollBy(m.Viewport.Height)
+	case key.Matches(msg, k.PageUp):
+		m.scrollBy(-m.Viewport.Height)
+	case key.Matches(msg, k.HalfPageDown):
+		m.scrollBy(m.Viewport.Height / 2)
+	case key.Matches(msg, k.HalfPageUp):
+		m.scrollBy(-m.Viewport.Height / 2)
+	case key.Matches(msg, k.Down):
+		m.scrollBy(1)
+	case key.Matches(msg, k.Up):
+		m.scrollBy(-1)
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,36 @@
+package message
+
+import "github.com/charmbracelet/lipgloss"
+
+// HorizontalScrollStep is the number of columns scrolled per keypress
+const HorizontalScrollStep = 8
+
+type ScrollHorizontalMsg struct {
+	// Columns to scroll by, negative values scroll left
+	Columns int
+}
+
+type ToggleWrapMsg struct{}
+
+// scrollHorizontal moves the horizontal offset by columns, no further than
+// the end of the widest line in the viewport. It is not possible to scroll
+// horizontally while lines are wrapped.
+func (m *Model) scrollHorizontal(columns int) {
+	if m.wrap {
+		return
+	}
+	m.xOffset = max(0, min(m.xOffset+columns, m.widestLine()-m.Viewport.Width))
+}
+
+// widestLine returns the width of the widest line of the messages in the
+// viewport, as it's rendered before being scrolled
+func (m *Model) widestLine() int {
+	m.layout()
+	widest := 0
+	bottom := m.yOffset + m.Viewport.Height
+	for i := m.messageAt(m.yOffset); i < len(m.messages) && m.messages[i].line < bottom; i++ {
+		// the left border and padding are in front of the text
+		widest = max(widest, lipgloss.Width(m.messages[i].format())+4)
+	}
+	return widest
+}
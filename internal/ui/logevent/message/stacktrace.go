@@ -0,0 +1,161 @@
+package message
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	// logback appends the jar of the frame, e.g. "~[app.jar:1.0]" or "[na:1.8]"
+	javaFrameRegex     = regexp.MustCompile(`^\s+at ([\w$.<>/]+)\(.*\)(?:\s+~?\[[^\]]*\])?\s*$`)
+	pythonFrameRegex   = regexp.MustCompile(`^\s+File "([^"]+)", line \d+, in .*$`)
+	nodeFrameRegex     = regexp.MustCompile(`^\s+at (?:async )?(?:[^()]+ \()?([^()\s]+):\d+:\d+\)?\s*$`)
+	exceptionTypeRegex = regexp.MustCompile(
+		`^(\s*(?:Caused by: |Exception in thread "[^"]*" )?)([\w$.]+(?:Exception|Error|Throwable|Exit|Interrupt)\b)(.*)$`,
+	)
+
+	// frames from these packages/paths are folded away, leaving the frames
+	// from the application itself
+	javaFrameworkPrefixes = []string{
+		"java.", "javax.", "jdk.", "sun.", "com.sun.", "kotlin.", "scala.",
+		"org.springframework.", "org.apache.", "org.hibernate.", "io.netty.",
+		"reactor.", "com.amazonaws.", "software.amazon.", "lambdainternal.",
+	}
+	pythonFrameworkPaths = []string{
+		"site-packages/", "dist-packages/", "/var/runtime/", "/var/lang/lib/", "<frozen ",
+	}
+	nodeFrameworkPaths = []string{
+		"node:", "node_modules/", "/var/runtime/",
+	}
+
+	exceptionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("160")).Bold(true)
+	appFrameStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	foldedStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true)
+)
+
+type frameKind int
+
+const (
+	notAFrame frameKind = iota
+	appFrame
+	frameworkFrame
+)
+
+// isStackTrace returns true if any of lines is a java, python or node stack
+// frame
+func isStackTrace(lines []string) bool {
+	for _, line := range lines {
+		if classifyFrame(line) != notAFrame {
+			return true
+		}
+	}
+	return false
+}
+
+// formatStackTrace folds runs of consecutive framework frames into a single
+// line and highlights exception types and the first frame from the
+// application
+func formatStackTrace(lines []string) string {
+	var (
+		out          []string
+		pending      []string // lines of the framework frames being folded
+		folded       int
+		highlighted  bool
+		skipNextLine bool
+	)
+
+	flush := func() {
+		if folded == 1 {
+			out = append(out, pending...)
+		} else if folded > 1 {
+			out = append(out, foldedStyle.Render(
+				fmt.Sprintf("    ... %d framework frames", folded),
+			))
+		}
+		pending = nil
+		folded = 0
+	}
+
+	for i, line := range lines {
+		if skipNextLine {
+			skipNextLine = false
+			continue
+		}
+
+		// python frames are followed by the line of source they point to
+		hasSource := pythonFrameRegex.MatchString(line) && i+1 < len(lines) &&
+			classifyFrame(lines[i+1]) == notAFrame &&
+			!exceptionTypeRegex.MatchString(lines[i+1])
+
+		frame := []string{line}
+		if hasSource {
+			frame = append(frame, lines[i+1])
+			skipNextLine = true
+		}
+
+		switch classifyFrame(line) {
+		case frameworkFrame:
+			pending = append(pending, frame...)
+			folded++
+		case appFrame:
+			flush()
+			if !highlighted {
+				highlighted = true
+				for k := range frame {
+					frame[k] = appFrameStyle.Render(frame[k])
+				}
+			}
+			out = append(out, frame...)
+		default:
+			flush()
+			out = append(out, highlightExceptionType(line))
+		}
+	}
+	flush()
+
+	return strings.Join(out, "\n")
+}
+
+func classifyFrame(line string) frameKind {
+	if match := javaFrameRegex.FindStringSubmatch(line); match != nil {
+		for _, prefix := range javaFrameworkPrefixes {
+			if strings.HasPrefix(match[1], prefix) {
+				return frameworkFrame
+			}
+		}
+		return appFrame
+	}
+
+	if match := pythonFrameRegex.FindStringSubmatch(line); match != nil {
+		for _, path := range pythonFrameworkPaths {
+			if strings.Contains(match[1], path) {
+				return frameworkFrame
+			}
+		}
+		return appFrame
+	}
+
+	if match := nodeFrameRegex.FindStringSubmatch(line); match != nil {
+		for _, path := range nodeFrameworkPaths {
+			if strings.Contains(match[1], path) {
+				return frameworkFrame
+			}
+		}
+		return appFrame
+	}
+
+	return notAFrame
+}
+
+// highlightExceptionType highlights the exception class in lines such as
+// "Caused by: java.io.IOException: broken pipe" or "KeyError: 'id'"
+func highlightExceptionType(line string) string {
+	match := exceptionTypeRegex.FindStringSubmatch(line)
+	if match == nil {
+		return line
+	}
+	return match[1] + exceptionStyle.Render(match[2]) + match[3]
+}
@@ -0,0 +1,137 @@
+package message
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyFrame(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want frameKind
+	}{
+		{"java app", "\tat com.example.orders.Handler.handle(Handler.java:42)", appFrame},
+		{"java framework", "\tat java.base/java.lang.Thread.run(Thread.java:833)", frameworkFrame},
+		{"java native", "\tat jdk.internal.reflect.NativeMethodAccessorImpl.invoke0(Native Method)", frameworkFrame},
+		{"logback jar", "\tat com.example.orders.Handler.handle(Handler.java:42) ~[app.jar:1.0]", appFrame},
+		{"logback unknown jar", "\tat org.springframework.web.servlet.FrameworkServlet.service(FrameworkServlet.java:883) [na:1.8]", frameworkFrame},
+		{"logback no tilde", "\tat com.example.Main.main(Main.java:7) [app.jar:1.0]", appFrame},
+		{"python app", `  File "/var/task/app.py", line 10, in handler`, appFrame},
+		{"python framework", `  File "/var/runtime/bootstrap.py", line 60, in handle`, frameworkFrame},
+		{"python package", `  File "/opt/python/site-packages/requests/api.py", line 59, in request`, frameworkFrame},
+		{"node app", "    at Object.handler (/var/task/index.js:10:15)", appFrame},
+		{"node anonymous", "    at /var/task/index.js:5:3", appFrame},
+		{"node async", "    at async Runtime.handleOnceNonStreaming (file:///var/runtime/index.mjs:1173:29)", frameworkFrame},
+		{"node internal", "    at processTicksAndRejections (node:internal/process/task_queues:95:5)", frameworkFrame},
+		{"node module", "    at Layer.handle (/var/task/node_modules/express/lib/router/layer.js:95:5)", frameworkFrame},
+		{"exception", "java.lang.IllegalStateException: no order", notAFrame},
+		{"text", "    at the end of the day", notAFrame},
+		{"unindented", "at com.example.Main.main(Main.java:7)", notAFrame},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyFrame(tt.line); got != tt.want {
+				t.Errorf("classifyFrame(%q) = %d, want %d", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatStackTrace(t *testing.T) {
+	tests := []struct {
+		name  string
+		trace string
+		want  string
+	}{
+		{
+			"java",
+			`java.lang.IllegalStateException: no order
+	at com.example.orders.Handler.handle(Handler.java:42)
+	at jdk.internal.reflect.NativeMethodAccessorImpl.invoke0(Native Method)
+	at java.base/java.lang.reflect.Method.invoke(Method.java:568)
+	at com.example.orders.Main.main(Main.java:7)`,
+			`java.lang.IllegalStateException: no order
+	at com.example.orders.Handler.handle(Handler.java:42)
+    ... 2 framework frames
+	at com.example.orders.Main.main(Main.java:7)`,
+		},
+		{
+			"logback",
+			`java.lang.IllegalStateException: no order
+	at com.example.orders.Handler.handle(Handler.java:42) ~[app.jar:1.0]
+	at org.springframework.web.servlet.FrameworkServlet.service(FrameworkServlet.java:883) ~[spring-webmvc.jar:5.3]
+	at org.apache.catalina.core.ApplicationFilterChain.doFilter(ApplicationFilterChain.java:166) [na:1.8]
+Caused by: java.io.IOException: broken pipe
+	at java.base/sun.nio.ch.SocketDispatcher.write0(Native Method) ~[na:na]`,
+			`java.lang.IllegalStateException: no order
+	at com.example.orders.Handler.handle(Handler.java:42) ~[app.jar:1.0]
+    ... 2 framework frames
+Caused by: java.io.IOException: broken pipe
+	at java.base/sun.nio.ch.SocketDispatcher.write0(Native Method) ~[na:na]`,
+		},
+		{
+			"python",
+			`Traceback (most recent call last):
+  File "/var/runtime/bootstrap.py", line 60, in handle
+    response = request_handler(event, context)
+  File "/var/lang/lib/python3.9/importlib/__init__.py", line 127, in import_module
+    return _bootstrap._gcd_import(name[level:], package, level)
+  File "/var/task/app.py", line 10, in handler
+    return orders[event["id"]]
+KeyError: 'id'`,
+			`Traceback (most recent call last):
+    ... 2 framework frames
+  File "/var/task/app.py", line 10, in handler
+    return orders[event["id"]]
+KeyError: 'id'`,
+		},
+		{
+			"node",
+			`TypeError: Cannot read properties of undefined (reading 'id')
+    at Object.handler (/var/task/index.js:10:15)
+    at Layer.handle (/var/task/node_modules/express/lib/router/layer.js:95:5)
+    at next (/var/task/node_modules/express/lib/router/route.js:137:13)
+    at async Runtime.handleOnceNonStreaming (file:///var/runtime/index.mjs:1173:29)
+    at /var/task/index.js:5:3`,
+			`TypeError: Cannot read properties of undefined (reading 'id')
+    at Object.handler (/var/task/index.js:10:15)
+    ... 3 framework frames
+    at /var/task/index.js:5:3`,
+		},
+		{
+			"single framework frame is kept",
+			`Error: boom
+    at Object.handler (/var/task/index.js:10:15)
+    at processTicksAndRejections (node:internal/process/task_queues:95:5)`,
+			`Error: boom
+    at Object.handler (/var/task/index.js:10:15)
+    at processTicksAndRejections (node:internal/process/task_queues:95:5)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(tt.trace, "\n")
+			if !isStackTrace(lines) {
+				t.Fatal("not detected as a stack trace")
+			}
+			if got := formatStackTrace(lines); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNotStackTrace(t *testing.T) {
+	for _, text := range []string{
+		"handled request\n  in 12ms",
+		"usage:\n  at least one argument is required",
+		"{\n  \"id\": 1\n}",
+	} {
+		if isStackTrace(strings.Split(text, "\n")) {
+			t.Errorf("%q detected as a stack trace", text)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package message
+
+// State is the part of the model restored when resuming a session
+type State struct {
+	Expanded []int64 // timestamps of the expanded messages
+	YOffset  int
+	XOffset  int
+	Wrap     bool
+}
+
+// RestoreStateMsg expands the messages and scrolls the viewport as they were
+// when the state was saved
+type RestoreStateMsg State
+
+// State returns the expanded messages and scroll position of the model
+func (m Model) State() State {
+	state := State{
+		YOffset: m.yOffset,
+		XOffset: m.xOffset,
+		Wrap:    m.wrap,
+	}
+	for _, msg := range m.messages {
+		if !msg.collapsed {
+			state.Expanded = append(state.Expanded, msg.timestamp)
+		}
+	}
+	return state
+}
+
+func (m *Model) restoreState(state RestoreStateMsg) {
+	expanded := make(map[int64]bool, len(state.Expanded))
+	for _, timestamp := range state.Expanded {
+		expanded[timestamp] = true
+	}
+	for k := range m.messages {
+		if expanded[m.messages[k].timestamp] {
+			m.messages[k].collapsed = false
+			m.messages[k].invalidate()
+		}
+	}
+
+	m.wrap = state.Wrap
+	m.xOffset = 0
+	if !m.wrap {
+		m.xOffset = state.XOffset
+	}
+
+	m.layout()
+	m.yOffset = state.YOffset
+	m.renderView()
+}
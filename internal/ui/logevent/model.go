@@ -2,20 +2,40 @@ package logevent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"clviewer/internal/cloudwatch/client"
 	"clviewer/internal/cloudwatch/event"
+	"clviewer/internal/cloudwatch/pattern"
 	"clviewer/internal/commands"
+	"clviewer/internal/layout"
+	"clviewer/internal/locator"
+	"clviewer/internal/paging"
+	"clviewer/internal/ui/ansi"
+	"clviewer/internal/ui/logevent/histogram"
 	"clviewer/internal/ui/logevent/message"
 	"clviewer/internal/ui/logevent/timestamp"
+	"clviewer/internal/ui/mouse"
+	"clviewer/internal/ui/timeformat"
 )
 
+// jumpContext is how far before the timestamp of a locator events are loaded
+// from, so the events leading up to it can be seen
+const jumpContext = 5 * time.Minute
+
+// followInterval is how often new events are fetched while following
+const followInterval = 2 * time.Second
+
 var (
 	doubleBorder = lipgloss.NewStyle().
 			BorderStyle(lipgloss.DoubleBorder()).
@@ -32,46 +52,119 @@ var (
 type Model struct {
 	Timestamp      timestamp.Model
 	Messages       message.Model
+	Histogram      histogram.Model
 	eventPaginator *event.Paginator
 	numberOfEvents int
 	selectedGroup  string
 	selectedStream string
 	selectedEvent  int
-	help           help.Model
+
+	events           []types.OutputLogEvent
+	lambdaMode       bool
+	invocationFilter invocationFilter
+	timeFormat       timeformat.Format
+	copyMenu         bool
+	width            int
+	layout           layout.Layout
+
+	cw            client.Client
+	ctx           context.Context    // cancelled when the tab is closed
+	cancel        context.CancelFunc // cancels the page being fetched
+	filterPattern string
+	unparsed      error // why the viewer can't parse the filter pattern, told once CloudWatch accepts it
+	startTime     int64
+	endTime       int64
+	jumpTo        int64 // timestamp to select once events are loaded
+	loading       bool
+	newerPages    bool
+	olderPages    bool
+	evicted       bool   // events were evicted while following
+	restore       *State // view state to apply once events are loaded
+
+	paging paging.Options
+
+	tail       bool // start streams at their newest events
+	selectLast bool // select the newest event once the first page loads
+	pendingG   bool // g was pressed, a second g jumps to the oldest event
+
+	following bool
+	followID  int // increased each time following starts, to stop old ticks
+}
+
+// eventsLoadedMsg is a page of events fetched by paginator
+type eventsLoadedMsg struct {
+	paginator *event.Paginator
+	events    []types.OutputLogEvent
+	older     bool // the page is before the events loaded so far
+	newer     bool // the paginator has newer pages
+	hasOlder  bool // and older ones
+	err       error
+}
+
+// fetch is which page of events to fetch
+type fetch int
+
+const (
+	fetchNewer fetch = iota
+	fetchOlder
+	fetchPoll // newer events, even once every page has been read
+)
+
+// followMsg polls for new events while following
+type followMsg struct {
+	id int
 }
 
 func New(
+	ctx context.Context,
+	cw client.Client,
+	opts paging.Options,
 	timestampModel timestamp.Model,
 	msg message.Model,
-	initialGroup, initialStream string,
+	initial locator.Locator,
 ) Model {
-	helpModel := help.New()
-	helpModel.ShowAll = true
-
 	model := Model{
 		Timestamp:      timestampModel,
 		Messages:       message.Model{},
+		Histogram:      histogram.New(),
+		timeFormat:     timeformat.Default(),
+		layout:         layout.Default(),
 		eventPaginator: nil,
 		numberOfEvents: 0,
-		selectedGroup:  initialGroup,
-		selectedStream: "",
+		selectedGroup:  initial.Group,
+		selectedStream: initial.Stream,
 		selectedEvent:  0,
-		help:           helpModel,
-	}
-
-	if initialGroup != "" {
-		model, _ = model.Update(commands.UpdateStreamListItemsMsg{Group: initialGroup})
-		model, _ = model.Update(commands.UpdateEventListItemsMsg{
-			Group:  initialGroup,
-			Stream: initialStream,
-		})
+		cw:             cw,
+		ctx:            ctx,
+		paging:         opts,
+		filterPattern:  initial.FilterPattern,
+		startTime:      initial.Start,
+		endTime:        initial.End,
+		jumpTo:         initial.Timestamp,
+		tail:           initial.Tail,
 	}
 
 	return model
 }
 
+// Init loads the events of the initial locator, when it has no stream or
+// filter pattern the events are loaded once a stream is selected instead
 func (m Model) Init() tea.Cmd {
-	return nil
+	if m.selectedGroup == "" || (m.selectedStream == "" && m.filterPattern == "") {
+		return nil
+	}
+
+	msg := commands.UpdateEventListItemsMsg{
+		Group:         m.selectedGroup,
+		Stream:        m.selectedStream,
+		FilterPattern: m.filterPattern,
+		Start:         m.startTime,
+		End:           m.endTime,
+		Timestamp:     m.jumpTo,
+	}
+	return func() tea.Msg {
+		return msg
+	}
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
@@ -82,7 +175,11 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		return m.handleUpdateWindowSize(msg)
 	case tea.KeyMsg:
-		return m.handleUpdateKey(msg)
+		m, cmd = m.handleUpdateKey(msg)
+		return m, tea.Batch(cmd, m.prefetchEvents())
+	case mouse.Msg:
+		m, cmd = m.handleMouse(msg)
+		return m, tea.Batch(cmd, m.prefetchEvents())
 		// TODO combine these? or refactor somehow?
 	case commands.UpdateStreamListItemsMsg:
 		m.selectedGroup = msg.Group
@@ -91,13 +188,53 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case commands.UpdateEventListItemsMsg:
 		m.selectedGroup = msg.Group
 		m.selectedStream = msg.Stream
+		m.filterPattern = msg.FilterPattern
+		m.startTime = msg.Start
+		m.endTime = msg.End
+		m.jumpTo = msg.Timestamp
 		m, cmd = m.updateEventItems()
 		return m, cmd
+	case eventsLoadedMsg:
+		return m.handleEventsLoaded(msg)
+	case followMsg:
+		if !m.following || msg.id != m.followID {
+			return m, nil
+		}
+		return m, tea.Batch(m.fetchEvents(fetchPoll), m.scheduleFollow())
+	case commands.CancelMsg:
+		// the page is dropped when its fetch returns cancelled
+		m.cancelFetch()
+		m.following = false
+		return m, nil
+	case runKeyMsg:
+		return m.handleUpdateKey(tea.KeyMsg(msg))
+	case filterMsg, timeRangeMsg, goToMsg, openStreamMsg, timeLayoutMsg:
+		return m.handleAction(msg)
+	case commands.TestPatternMsg:
+		return m, m.testPattern(msg.Pattern)
+	case patternTestedMsg:
+		return m.highlightTested(msg)
+	case message.CopyMessage:
+		return m.copyEvents(msg)
+	case highlightMsg:
+		return m.highlight(string(msg))
+	case clearHighlightsMsg:
+		m.Messages, cmd = m.Messages.Update(message.HighlightMsg{})
+		return m, cmd
+	case commands.SetTimeFormatMsg:
+		m.timeFormat = msg.Format
+	case commands.SetLayoutMsg:
+		// the columns are resized by the WindowSizeMsg that follows
+		m.layout = msg.Layout
+		return m, nil
 	}
 
 	m.Timestamp, cmd = m.Timestamp.Update(msg)
 	cmds = append(cmds, cmd)
 
+	m.Histogram, cmd = m.Histogram.Update(msg)
+	cmds = append(cmds, cmd)
+
 	m.Messages, cmd = m.Messages.Update(msg)
 	cmds = append(cmds, cmd)
 
@@ -105,18 +242,24 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
+	if m.layout.HideTimestamps {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			m.titleView(),
+			m.Messages.View(),
+		)
+	}
+
 	logEventView := lipgloss.JoinVertical(
 		lipgloss.Left,
-		doubleBorder.Render(fmt.Sprintf(
-			" %s: %s %s: %s ",
-			bold.Render("LogGroup"),
-			purpleText.Render(m.selectedGroup),
-			bold.Render("LogStream"),
-			purpleText.Render(m.selectedStream),
-		))+"\n",
+		m.titleView(),
 		lipgloss.JoinHorizontal(
 			lipgloss.Top,
-			m.Timestamp.View(),
+			lipgloss.JoinVertical(
+				lipgloss.Left,
+				m.Histogram.View(),
+				m.Timestamp.View(),
+			),
 			m.Messages.View(),
 		),
 	)
@@ -124,20 +267,63 @@ func (m Model) View() string {
 	return logEventView
 }
 
+// titleView is the box above the events with the group, stream and filters
+func (m Model) titleView() string {
+	title := fmt.Sprintf(
+		" %s: %s %s: %s %s: %s ",
+		bold.Render("LogGroup"),
+		purpleText.Render(m.selectedGroup),
+		bold.Render("LogStream"),
+		purpleText.Render(m.selectedStream),
+		bold.Render("Time"),
+		purpleText.Render(m.timeFormat.String()),
+	)
+	if m.filterPattern != "" {
+		title += fmt.Sprintf(
+			"%s: %s ",
+			bold.Render("Filter"),
+			purpleText.Render(m.filterPattern),
+		)
+	}
+	if m.lambdaMode {
+		title += fmt.Sprintf(
+			"%s: %s ",
+			bold.Render("Invocations"),
+			purpleText.Render(m.invocationFilter.String()),
+		)
+	}
+
+	if m.copyMenu {
+		title = m.copyMenuView()
+	}
+	if m.width > 0 {
+		title = ansi.Truncate(title, m.width-doubleBorder.GetHorizontalFrameSize())
+	}
+
+	return doubleBorder.Render(title) + "\n"
+}
+
 func (m Model) handleUpdateWindowSize(msg tea.WindowSizeMsg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
-	const statusBarHeight = 4
+	m.width = msg.Width
+	height := msg.Height - lipgloss.Height(m.titleView())
 
-	height := msg.Height - statusBarHeight
+	timestampWidth, messageWidth := layout.Split(msg.Width, m.layout.Timestamps)
+	if m.layout.HideTimestamps {
+		messageWidth = msg.Width
+	}
 
-	timestampWidth := int(float32(msg.Width) / 3.0)
-	messageWidth := msg.Width - timestampWidth
+	m.Histogram, cmd = m.Histogram.Update(tea.WindowSizeMsg{
+		Width:  timestampWidth,
+		Height: histogram.Height,
+	})
+	cmds = append(cmds, cmd)
 
 	m.Timestamp, cmd = m.Timestamp.Update(tea.WindowSizeMsg{
 		Width:  timestampWidth,
-		Height: height,
+		Height: height - histogram.Height,
 	})
 	cmds = append(cmds, cmd)
 
@@ -154,10 +340,25 @@ func (m Model) handleUpdateKey(msg tea.KeyMsg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
+	if m.copyMenu {
+		return m.handleCopyMenuKey(msg)
+	}
+
+	pendingG := m.pendingG
+	m.pendingG = false
+
 	switch {
+	case key.Matches(msg, keys.Head):
+		if msg.String() == "g" && !pendingG {
+			m.pendingG = true
+			return m, nil
+		}
+		return m.jumpToHead()
+	case key.Matches(msg, keys.Tail):
+		return m.jumpToTail()
 	case key.Matches(msg, keys.NextItem):
 		if m.numberOfEvents-1 <= m.selectedEvent {
-			return m, nil
+			return m, m.loadMoreEvents()
 		}
 		m.selectedEvent += 1
 		m.Messages, cmd = m.Messages.Update(message.NextEventMsg{
@@ -171,7 +372,7 @@ func (m Model) handleUpdateKey(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m, cmd
 	case key.Matches(msg, keys.PrevItem):
 		if m.selectedEvent <= 0 {
-			return m, nil
+			return m, m.loadOlderEvents()
 		}
 		m.selectedEvent -= 1
 		m.Messages, cmd = m.Messages.Update(message.PrevEventMsg{
@@ -193,11 +394,63 @@ func (m Model) handleUpdateKey(msg tea.KeyMsg) (Model, tea.Cmd) {
 			message.ToggleCollapsedMsg{ToggleAll: true},
 		)
 		return m, cmd
+	case key.Matches(msg, keys.Left):
+		m.Messages, cmd = m.Messages.Update(message.ScrollHorizontalMsg{
+			Columns: -message.HorizontalScrollStep,
+		})
+		return m, cmd
+	case key.Matches(msg, keys.Right):
+		m.Messages, cmd = m.Messages.Update(message.ScrollHorizontalMsg{
+			Columns: message.HorizontalScrollStep,
+		})
+		return m, cmd
+	case key.Matches(msg, keys.Wrap):
+		m.Messages, cmd = m.Messages.Update(message.ToggleWrapMsg{})
+		return m, cmd
 	case key.Matches(msg, keys.Copy):
 		m.Messages, cmd = m.Messages.Update(message.CopyMessage{})
 		return m, cmd
+	case key.Matches(msg, keys.CopyAs):
+		m.copyMenu = true
+		return m, nil
+	case key.Matches(msg, keys.OpenConsole):
+		return m, m.openInConsole(false)
+	case key.Matches(msg, keys.CopyConsoleURL):
+		return m, m.openInConsole(true)
+	case key.Matches(msg, keys.CopyLocator):
+		return m, m.copyLocator()
+	case key.Matches(msg, keys.Mark):
+		m.Messages, cmd = m.Messages.Update(message.ToggleMarkMsg{})
+		return m, cmd
+	case key.Matches(msg, keys.Invocations):
+		return m.toggleLambdaMode()
+	case key.Matches(msg, keys.InvocationFilter):
+		if !m.lambdaMode {
+			return m, nil
+		}
+		m.invocationFilter = (m.invocationFilter + 1) % numInvocationFilters
+		m.selectedEvent = 0
+		return m, m.showItems()
+	case key.Matches(msg, keys.NextInvocation):
+		return m.selectEvent(m.nextInvocation(1))
+	case key.Matches(msg, keys.PrevInvocation):
+		return m.selectEvent(m.nextInvocation(-1))
+	case key.Matches(msg, keys.NextBucket):
+		var index int
+		m.Histogram, index = m.Histogram.SelectBucket(1, m.selectedEvent)
+		return m.selectEvent(index)
+	case key.Matches(msg, keys.PrevBucket):
+		var index int
+		m.Histogram, index = m.Histogram.SelectBucket(-1, m.selectedEvent)
+		return m.selectEvent(index)
+	case key.Matches(msg, keys.TimeFormat):
+		return m, commands.SetTimeFormat(m.timeFormat.NextMode())
+	case key.Matches(msg, keys.TimeZone):
+		return m, commands.SetTimeFormat(m.timeFormat.NextLocation())
 	case key.Matches(msg, keys.LoadMore):
 		return m, m.loadMoreEvents()
+	case key.Matches(msg, keys.Follow):
+		return m.toggleFollow()
 	case key.Matches(msg, keys.Reload):
 		m, cmd = m.updateEventItems()
 		return m, cmd
@@ -220,23 +473,45 @@ func (m Model) updateEventItems() (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
-	// get a new paginator for our log group & stream
-	paginator := event.New(
-		context.Background(),
-		m.selectedGroup,
-		m.selectedStream,
-		"",
-	)
+	query := event.Query{
+		Group:         m.selectedGroup,
+		Stream:        m.selectedStream,
+		FilterPattern: m.filterPattern,
+		Start:         m.startTime,
+		End:           m.endTime,
+		PageSize:      m.paging.PageSize,
+	}
+	if m.jumpTo != 0 && query.Start == 0 {
+		query.Start = m.jumpTo - jumpContext.Milliseconds()
+	}
+	// jumping to an event reads forwards from just before it
+	query.Tail = m.tail && m.jumpTo == 0
+
+	// get a new paginator for our log group & stream, any page still loading
+	// from the previous paginator is cancelled and dropped when it arrives
+	m.cancelFetch()
+	paginator := event.New(m.cw, query)
 	m.eventPaginator = &paginator
+	m.loading = false
+	m.evicted = false
+	m.newerPages = true
+	m.olderPages = query.Tail && paginator.CanTail()
+	m.selectLast = m.olderPages
+	// CloudWatch has the last word on which patterns are valid, so a pattern
+	// the viewer can't parse is still searched for
+	_, m.unparsed = pattern.Parse(m.filterPattern)
 
 	{ // reset data
 		m.selectedEvent = 0
 		m.numberOfEvents = 0
+		m.events = nil
 
 		m.Timestamp, cmd = m.Timestamp.Update(timestamp.ResetMsg{})
 		cmds = append(cmds, cmd)
 		m.Messages, cmd = m.Messages.Update(message.ResetMsg{})
 		cmds = append(cmds, cmd)
+		m.Histogram, cmd = m.Histogram.Update(histogram.SetItemsMsg{})
+		cmds = append(cmds, cmd)
 	}
 
 	// get initial set of events
@@ -246,17 +521,234 @@ func (m Model) updateEventItems() (Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// loadMoreEvents fetches the next page of events in the background, only one
+// page is fetched at a time
 func (m *Model) loadMoreEvents() tea.Cmd {
+	return m.fetchEvents(fetchNewer)
+}
+
+// loadOlderEvents fetches the page before the loaded events in the
+// background, if the paginator started after the oldest event
+func (m *Model) loadOlderEvents() tea.Cmd {
+	if !m.olderPages {
+		return nil
+	}
+	return m.fetchEvents(fetchOlder)
+}
+
+// prefetchEvents fetches the next page of events once the selected event is
+// near the last one
+func (m *Model) prefetchEvents() tea.Cmd {
+	if m.paging.Prefetch == 0 || !m.newerPages || m.following {
+		return nil
+	}
+	if m.selectedEvent < m.numberOfEvents-m.paging.Prefetch {
+		return nil
+	}
+	return m.loadMoreEvents()
+}
+
+// full returns true once the model holds as many events as it's allowed,
+// which it never does while following with a buffer to evict events from
+func (m Model) full() bool {
+	if m.following && m.paging.FollowBuffer > 0 {
+		return false
+	}
+	return m.paging.MaxEvents > 0 && len(m.events) >= m.paging.MaxEvents
+}
+
+// evictOldest drops the oldest events once the model holds more than the
+// follow buffer while following, returning how many were dropped. The page
+// tokens don't reach the events left, so older pages can't be loaded after.
+func (m *Model) evictOldest() int {
+	n := len(m.events) - m.paging.FollowBuffer
+	if !m.following || m.paging.FollowBuffer == 0 || n <= 0 {
+		return 0
+	}
+	// copied so the dropped events can be freed
+	m.events = append([]types.OutputLogEvent(nil), m.events[n:]...)
+	m.evicted = true
+	m.olderPages = false
+	return n
+}
+
+// fetchEvents fetches a page of events in the background, unless the model
+// is full. Polling fetches the events written since the last page once every
+// page has been read.
+func (m *Model) fetchEvents(f fetch) tea.Cmd {
+	if m.eventPaginator == nil || m.loading || m.full() {
+		return nil
+	}
+	m.loading = true
+
+	// the context of the last page is released once it arrives, so this only
+	// cancels one that was left behind
+	m.cancelFetch()
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancel = cancel
+
+	paginator := m.eventPaginator
+	return func() tea.Msg {
+		var (
+			events []types.OutputLogEvent
+			err    error
+		)
+		switch f {
+		case fetchOlder:
+			events, err = paginator.Older(ctx)
+		case fetchPoll:
+			events, err = paginator.Poll(ctx)
+		default:
+			events, err = paginator.Newer(ctx)
+		}
+		return eventsLoadedMsg{
+			paginator: paginator,
+			events:    events,
+			older:     f == fetchOlder,
+			newer:     paginator.HasNewer(),
+			hasOlder:  paginator.HasOlder(),
+			err:       err,
+		}
+	}
+}
+
+// cancelFetch cancels the page being fetched, if any, and releases its
+// context
+func (m *Model) cancelFetch() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+}
+
+// toggleFollow starts or stops fetching new events as they're written, the
+// newest event is selected as they arrive
+func (m Model) toggleFollow() (Model, tea.Cmd) {
+	m.following = !m.following
+	if !m.following {
+		return m, nil
+	}
+	m.followID++
+
+	// rather than reading every page up to the newest events, the stream is
+	// read again from its end
+	if m.newerPages && m.eventPaginator != nil && m.eventPaginator.CanTail() {
+		var cmd tea.Cmd
+		m.tail = true
+		m, cmd = m.updateEventItems()
+		return m, tea.Batch(cmd, m.scheduleFollow())
+	}
+	return m, tea.Batch(m.fetchEvents(fetchPoll), m.scheduleFollow())
+}
+
+// jumpToHead selects the oldest event, reading the stream again from its start
+// if it started at its end or had events evicted and the oldest event isn't
+// loaded, which stops following
+func (m Model) jumpToHead() (Model, tea.Cmd) {
+	if m.olderPages || m.evicted {
+		m.tail = false
+		m.following = false
+		return m.updateEventItems()
+	}
+	return m.selectEvent(0)
+}
+
+// jumpToTail selects the newest event, reading the stream again from its end if
+// the newest event hasn't been loaded. Searches can only be read forwards, so
+// their next page is loaded instead.
+func (m Model) jumpToTail() (Model, tea.Cmd) {
+	if !m.newerPages || m.eventPaginator == nil {
+		return m.selectEvent(m.numberOfEvents - 1)
+	}
+	if !m.eventPaginator.CanTail() {
+		m, cmd := m.selectEvent(m.numberOfEvents - 1)
+		return m, tea.Batch(cmd, m.loadMoreEvents())
+	}
+	m.tail = true
+	return m.updateEventItems()
+}
+
+// scrollEvents moves the selection by delta, loading older events when moving
+// before the first event and newer events when moving past the last
+func (m Model) scrollEvents(delta int) (Model, tea.Cmd) {
+	index := m.selectedEvent + delta
+	switch {
+	case index < 0:
+		return m, m.loadOlderEvents()
+	case index >= m.numberOfEvents:
+		return m, m.loadMoreEvents()
+	}
+	return m.selectEvent(index)
+}
+
+func (m Model) scheduleFollow() tea.Cmd {
+	id := m.followID
+	return tea.Tick(followInterval, func(time.Time) tea.Msg {
+		return followMsg{id: id}
+	})
+}
+
+func (m Model) handleEventsLoaded(msg eventsLoadedMsg) (Model, tea.Cmd) {
 	var (
 		cmd  tea.Cmd
 		cmds []tea.Cmd
 	)
 
-	ctx := context.Background()
+	// the query was changed while the page was loading
+	if msg.paginator != m.eventPaginator {
+		return m, nil
+	}
+	m.loading = false
+	// the page has arrived, so its context is released from the tab's
+	m.cancelFetch()
+	m.newerPages = msg.newer
+	m.olderPages = msg.hasOlder && !m.evicted
 
-	events := m.eventPaginator.NextPage(ctx)
-	if events == nil {
-		return nil
+	if errors.Is(msg.err, context.Canceled) {
+		return m, nil
+	}
+	if msg.err != nil {
+		return m, commands.Error(fmt.Errorf("error loading log events: %w", msg.err))
+	}
+	if m.unparsed != nil {
+		cmds = append(cmds, commands.Notice(fmt.Sprintf(
+			"filter: CloudWatch accepted the pattern the viewer can't parse: %s", m.unparsed,
+		)))
+		m.unparsed = nil
+	}
+	m.Messages, cmd = m.Messages.Update(message.EndOfStreamMsg{Reached: !m.newerPages})
+	cmds = append(cmds, cmd)
+
+	if msg.older {
+		m, cmd = m.prependEvents(msg.events)
+		return m, tea.Batch(append(cmds, cmd)...)
+	}
+	m, cmd = m.appendEvents(msg.events)
+	cmds = append(cmds, cmd)
+
+	// short pages, such as those of a sparse search, can leave the cursor
+	// near the end
+	return m, tea.Batch(append(cmds, m.prefetchEvents())...)
+}
+
+// appendEvents adds a page of events after the loaded events
+func (m Model) appendEvents(events []types.OutputLogEvent) (Model, tea.Cmd) {
+	var (
+		cmd  tea.Cmd
+		cmds []tea.Cmd
+	)
+
+	if len(events) == 0 {
+		return m.jump()
+	}
+	m.events = append(m.events, events...)
+	evicted := m.evictOldest()
+
+	// invocations can span pages so they are regrouped from scratch
+	if m.lambdaMode {
+		cmds = append(cmds, m.showItems())
+		m, cmd = m.jump()
+		return m, tea.Batch(append(cmds, cmd)...)
 	}
 	m.numberOfEvents += len(events)
 
@@ -271,10 +763,221 @@ func (m *Model) loadMoreEvents() tea.Cmd {
 			Collapsed:    true,
 		})
 		cmds = append(cmds, cmd)
+
+		m.Histogram, cmd = m.Histogram.Update(histogram.AppendItemsMsg(eventItems(events)))
+		cmds = append(cmds, cmd)
+
+		if evicted > 0 {
+			m.numberOfEvents -= evicted
+			m.selectedEvent -= evicted
+			if m.selectedEvent < 0 {
+				m.selectedEvent = 0
+			}
+			m.Timestamp, cmd = m.Timestamp.Update(timestamp.DropEventsMsg{N: evicted})
+			cmds = append(cmds, cmd)
+			m.Messages, cmd = m.Messages.Update(message.DropEventsMsg{N: evicted})
+			cmds = append(cmds, cmd)
+			m.Histogram, cmd = m.Histogram.Update(histogram.DropItemsMsg{N: evicted})
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	m, cmd = m.jump()
+	cmds = append(cmds, cmd)
+
+	return m, tea.Batch(cmds...)
+}
+
+// jump selects the event of the locator the model was opened at and restores
+// the view state, once the first page of events has loaded. While following
+// the newest event is selected instead.
+func (m Model) jump() (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
+
+	if m.jumpTo != 0 {
+		index := m.indexOfTimestamp(m.jumpTo)
+		m.jumpTo = 0
+		m, cmd = m.selectEvent(index)
+		cmds = append(cmds, cmd)
+	}
+
+	m, cmd = m.applyRestore()
+	cmds = append(cmds, cmd)
+
+	if m.following || m.selectLast {
+		m.selectLast = false
+		m, cmd = m.selectEvent(m.numberOfEvents - 1)
+		cmds = append(cmds, cmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// prependEvents adds a page of events from before the loaded events, keeping
+// the selected event selected
+func (m Model) prependEvents(events []types.OutputLogEvent) (Model, tea.Cmd) {
+	var (
+		cmd  tea.Cmd
+		cmds []tea.Cmd
+	)
+
+	if len(events) == 0 {
+		return m, nil
+	}
+
+	selected := m.selectedTimestamp()
+	m.events = append(append([]types.OutputLogEvent{}, events...), m.events...)
+
+	if m.lambdaMode {
+		cmds = append(cmds, m.showItems())
+		m, cmd = m.selectEvent(m.indexOfTimestamp(selected))
+		return m, tea.Batch(append(cmds, cmd)...)
+	}
+	m.numberOfEvents += len(events)
+	m.selectedEvent += len(events)
+
+	m.Timestamp, cmd = m.Timestamp.Update(timestamp.PrependEventsMsg(events))
+	cmds = append(cmds, cmd)
+
+	m.Messages, cmd = m.Messages.Update(message.PrependEventsMsg{
+		AwsLogEvents: events,
+		Collapsed:    true,
+	})
+	cmds = append(cmds, cmd)
+
+	m.Histogram, cmd = m.Histogram.Update(histogram.PrependItemsMsg(eventItems(events)))
+	cmds = append(cmds, cmd)
+
+	return m, tea.Batch(cmds...)
+}
+
+// showItems reloads the timestamp and message models with every loaded event,
+// grouped into invocations when in lambda mode
+func (m *Model) showItems() tea.Cmd {
+	var (
+		cmd  tea.Cmd
+		cmds []tea.Cmd
+	)
+
+	m.Timestamp, cmd = m.Timestamp.Update(timestamp.ResetMsg{})
+	cmds = append(cmds, cmd)
+	m.Messages, cmd = m.Messages.Update(message.ResetMsg{})
+	cmds = append(cmds, cmd)
+
+	m.Histogram, cmd = m.Histogram.Update(m.histogramItems())
+	cmds = append(cmds, cmd)
+
+	if m.lambdaMode {
+		invocations := m.filteredInvocations()
+		m.numberOfEvents = len(invocations)
+
+		m.Timestamp, cmd = m.Timestamp.Update(
+			timestamp.LoadMoreEventsMsg(invocationsToEvents(invocations)),
+		)
+		cmds = append(cmds, cmd)
+
+		m.Messages, cmd = m.Messages.Update(message.LoadMoreInvocationsMsg{
+			Invocations: invocations,
+			Collapsed:   true,
+		})
+		cmds = append(cmds, cmd)
+	} else {
+		m.numberOfEvents = len(m.events)
+
+		m.Timestamp, cmd = m.Timestamp.Update(
+			timestamp.LoadMoreEventsMsg(m.events),
+		)
+		cmds = append(cmds, cmd)
+
+		m.Messages, cmd = m.Messages.Update(message.LoadMoreEventsMsg{
+			AwsLogEvents: m.events,
+			Collapsed:    true,
+		})
+		cmds = append(cmds, cmd)
 	}
+
+	*m, cmd = m.selectEvent(m.selectedEvent)
+	cmds = append(cmds, cmd)
+
 	return tea.Batch(cmds...)
 }
 
-func (m Model) HelpView() string {
-	return m.help.View(keys)
+// histogramItems returns an item for each event in the event list, or each
+// invocation when in lambda mode
+func (m Model) histogramItems() histogram.SetItemsMsg {
+	var items histogram.SetItemsMsg
+	if m.lambdaMode {
+		for _, inv := range m.filteredInvocations() {
+			items = append(items, histogram.Item{
+				Timestamp: inv.Timestamp(),
+				Error:     inv.Failed(),
+			})
+		}
+		return items
+	}
+	return eventItems(m.events)
+}
+
+// eventItems returns an item for each event, pages of events are added to the
+// histogram as they load so each event is only classified once
+func eventItems(events []types.OutputLogEvent) []histogram.Item {
+	items := make([]histogram.Item, 0, len(events))
+	for _, e := range events {
+		items = append(items, histogram.Item{
+			Timestamp: aws.ToInt64(e.Timestamp),
+			Error:     histogram.IsError(aws.ToString(e.Message)),
+		})
+	}
+	return items
+}
+
+// indexOfTimestamp returns the index of the first item at or after timestamp
+func (m Model) indexOfTimestamp(timestamp int64) int {
+	if m.lambdaMode {
+		for i, inv := range m.filteredInvocations() {
+			if inv.Timestamp() >= timestamp {
+				return i
+			}
+		}
+		return m.numberOfEvents - 1
+	}
+
+	for i, e := range m.events {
+		if aws.ToInt64(e.Timestamp) >= timestamp {
+			return i
+		}
+	}
+	return m.numberOfEvents - 1
+}
+
+// selectEvent moves the cursor of both the timestamp and message models to
+// the event at index
+func (m Model) selectEvent(index int) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
+
+	if index >= m.numberOfEvents {
+		index = m.numberOfEvents - 1
+	}
+	if index < 0 {
+		index = 0
+	}
+	m.selectedEvent = index
+
+	m.Timestamp, cmd = m.Timestamp.Update(timestamp.SelectEventMsg{Index: index})
+	cmds = append(cmds, cmd)
+
+	m.Messages, cmd = m.Messages.Update(message.SelectEventMsg{Index: index})
+	cmds = append(cmds, cmd)
+
+	m.Histogram, cmd = m.Histogram.Update(histogram.SelectItemMsg{Index: index})
+	cmds = append(cmds, cmd)
+
+	return m, tea.Batch(cmds...)
+}
+
+// HelpKeys returns the keys of the event pane for the help overlay
+func (m Model) HelpKeys() help.KeyMap {
+	return keys
 }
@@ -0,0 +1,79 @@
+package logevent
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"clviewer/internal/layout"
+	"clviewer/internal/ui/logevent/histogram"
+	"clviewer/internal/ui/logevent/message"
+	"clviewer/internal/ui/mouse"
+)
+
+// handleMouse selects the event clicked on in either column, clicking the
+// selected event expands or collapses it and double clicking copies it. The
+// wheel moves through the timestamps or scrolls the messages.
+func (m Model) handleMouse(msg mouse.Msg) (Model, tea.Cmd) {
+	if m.copyMenu {
+		return m, nil
+	}
+
+	msg = msg.Translate(0, lipgloss.Height(m.titleView()))
+	if msg.Y < 0 {
+		return m, nil
+	}
+
+	timestampWidth, _ := layout.Split(m.width, m.layout.Timestamps)
+	if m.layout.HideTimestamps {
+		timestampWidth = 0
+	}
+	if msg.X < timestampWidth {
+		return m.handleTimestampMouse(msg.Translate(0, histogram.Height))
+	}
+	return m.handleMessageMouse(msg.Translate(timestampWidth, 0))
+}
+
+func (m Model) handleTimestampMouse(msg mouse.Msg) (Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.MouseWheelUp:
+		return m.scrollEvents(-1)
+	case tea.MouseWheelDown:
+		return m.scrollEvents(1)
+	case tea.MouseLeft:
+		if index, ok := m.Timestamp.EventAt(msg.Y); ok {
+			return m.clickEvent(index, msg.Double)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) handleMessageMouse(msg mouse.Msg) (Model, tea.Cmd) {
+	if !msg.Click() {
+		var cmd tea.Cmd
+		m.Messages, cmd = m.Messages.Update(msg)
+		return m, cmd
+	}
+
+	if index, ok := m.Messages.EventAt(msg.Y); ok {
+		return m.clickEvent(index, msg.Double)
+	}
+	return m, nil
+}
+
+// clickEvent selects the event at index, if it's already selected it is
+// expanded or collapsed instead, or copied on a double click
+func (m Model) clickEvent(index int, double bool) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch {
+	case double:
+		m.Messages, cmd = m.Messages.Update(message.CopyMessage{})
+	case index == m.selectedEvent:
+		m.Messages, cmd = m.Messages.Update(
+			message.ToggleCollapsedMsg{ToggleAll: false},
+		)
+	default:
+		return m.selectEvent(index)
+	}
+	return m, cmd
+}
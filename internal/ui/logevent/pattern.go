@@ -0,0 +1,138 @@
+package logevent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	tea "github.com/charmbracelet/bubbletea"
+
+	group "clviewer/internal/cloudwatch/group"
+	"clviewer/internal/cloudwatch/pattern"
+	"clviewer/internal/commands"
+	"clviewer/internal/ui/logevent/message"
+)
+
+// maxTestedMessages is the most distinct messages a pattern is tested
+// against, the newest are tested first. It's 50 calls of TestMetricFilter.
+const maxTestedMessages = 2500
+
+// highlightMsg highlights the events a pattern matches without calling
+// CloudWatch, and clearHighlightsMsg clears the events highlighted
+type (
+	highlightMsg       string
+	clearHighlightsMsg struct{}
+)
+
+// patternTestedMsg is the messages of the loaded events matched by a pattern
+type patternTestedMsg struct {
+	pattern   string
+	matched   map[string]bool
+	truncated bool  // not every distinct message was tested
+	unparsed  error // why the viewer couldn't parse the pattern CloudWatch accepted
+}
+
+// testPattern tests a filter pattern against the messages of the loaded
+// events with CloudWatch, the events loaded after it aren't tested
+func (m Model) testPattern(p string) tea.Cmd {
+	if len(m.events) == 0 {
+		return commands.Error(errors.New("no events loaded to test the pattern against"))
+	}
+	messages, truncated := m.distinctMessages()
+	// the warning is only worth showing if CloudWatch doesn't reject it
+	_, unparsed := pattern.Parse(p)
+
+	ctx, cw := m.ctx, m.cw
+	return func() tea.Msg {
+		tested := patternTestedMsg{pattern: p, truncated: truncated, unparsed: unparsed}
+		if strings.TrimSpace(p) == "" {
+			// an empty pattern matches every event
+			tested.matched = map[string]bool{}
+			for _, message := range messages {
+				tested.matched[message] = true
+			}
+			return tested
+		}
+
+		var err error
+		if tested.matched, err = group.TestPattern(ctx, cw, p, messages); err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error testing %s: %w", p, err)}
+		}
+		return tested
+	}
+}
+
+// distinctMessages returns the messages of the loaded events without
+// duplicates, newest first, and whether there were more than can be tested
+func (m Model) distinctMessages() ([]string, bool) {
+	seen := map[string]bool{}
+	var messages []string
+	for i := len(m.events) - 1; i >= 0; i-- {
+		message := testedMessage(aws.ToString(m.events[i].Message))
+		if message == "" || seen[message] {
+			continue
+		}
+		if len(messages) == maxTestedMessages {
+			return messages, true
+		}
+		seen[message] = true
+		messages = append(messages, message)
+	}
+	return messages, false
+}
+
+// testedMessage is an event's message as it's tested, without the line
+// break it usually ends with
+func testedMessage(message string) string {
+	return strings.TrimRight(message, "\n")
+}
+
+// highlight highlights the loaded events a pattern matches, and those loaded
+// after them. A pattern the viewer can't parse is tested with CloudWatch
+// instead, which only highlights the loaded events.
+func (m Model) highlight(s string) (Model, tea.Cmd) {
+	p, err := pattern.Parse(s)
+	if err != nil {
+		return m, m.testPattern(s)
+	}
+	return m.highlightMatches(s, p.Match)
+}
+
+// highlightTested highlights the events whose messages were matched by
+// testing a pattern with CloudWatch
+func (m Model) highlightTested(msg patternTestedMsg) (Model, tea.Cmd) {
+	var notes []string
+	if msg.truncated {
+		notes = append(notes, fmt.Sprintf("testing the newest %d distinct messages", maxTestedMessages))
+	}
+	if msg.unparsed != nil {
+		notes = append(notes, fmt.Sprintf("though the viewer can't parse it: %s", msg.unparsed))
+	}
+	return m.highlightMatches(msg.pattern, func(message string) bool {
+		return msg.matched[testedMessage(message)]
+	}, notes...)
+}
+
+// highlightMatches highlights the events match returns true for and tells
+// how many of the loaded events there are, followed by any notes
+func (m Model) highlightMatches(source string, match func(string) bool, notes ...string) (Model, tea.Cmd) {
+	matches := 0
+	for _, e := range m.events {
+		if match(aws.ToString(e.Message)) {
+			matches++
+		}
+	}
+
+	var cmd tea.Cmd
+	m.Messages, cmd = m.Messages.Update(message.HighlightMsg{Match: match})
+
+	if strings.TrimSpace(source) == "" {
+		source = `""`
+	}
+	notice := fmt.Sprintf("%d of %d loaded events match %s", matches, len(m.events), source)
+	for _, note := range notes {
+		notice += ", " + note
+	}
+	return m, tea.Batch(cmd, commands.Notice(notice))
+}
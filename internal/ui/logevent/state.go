@@ -0,0 +1,88 @@
+package logevent
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/ui/logevent/message"
+)
+
+// State is the part of the model restored when resuming a session, the
+// group, stream and selected event are restored from the Locator
+type State struct {
+	LambdaMode bool
+	// Offset is the position of the selected event among the events that
+	// share its timestamp
+	Offset   int
+	Messages message.State
+}
+
+// State returns the view state of the model
+func (m Model) State() State {
+	// the events of a restored model haven't loaded yet
+	if m.restore != nil {
+		return *m.restore
+	}
+
+	return State{
+		LambdaMode: m.lambdaMode,
+		Offset:     m.selectedOffset(),
+		Messages:   m.Messages.State(),
+	}
+}
+
+// Restore sets the view state to apply once the first page of events loads
+func (m Model) Restore(state State) Model {
+	m.lambdaMode = state.LambdaMode
+	m.restore = &state
+	return m
+}
+
+// selectedOffset returns the number of events before the selected event with
+// the same timestamp
+func (m Model) selectedOffset() int {
+	if m.lambdaMode || m.selectedEvent >= len(m.events) {
+		return 0
+	}
+
+	offset := 0
+	timestamp := aws.ToInt64(m.events[m.selectedEvent].Timestamp)
+	for i := m.selectedEvent - 1; i >= 0; i-- {
+		if aws.ToInt64(m.events[i].Timestamp) != timestamp {
+			break
+		}
+		offset++
+	}
+	return offset
+}
+
+// applyRestore moves the cursor past the events sharing the selected event's
+// timestamp and restores the expanded messages and scroll position
+func (m Model) applyRestore() (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
+
+	if m.restore == nil {
+		return m, nil
+	}
+	state := *m.restore
+	m.restore = nil
+
+	if !m.lambdaMode && state.Offset > 0 && m.selectedEvent < len(m.events) {
+		index := m.selectedEvent
+		timestamp := aws.ToInt64(m.events[index].Timestamp)
+		for i := 0; i < state.Offset && index+1 < len(m.events); i++ {
+			if aws.ToInt64(m.events[index+1].Timestamp) != timestamp {
+				break
+			}
+			index++
+		}
+		m, cmd = m.selectEvent(index)
+		cmds = append(cmds, cmd)
+	}
+
+	m.Messages, cmd = m.Messages.Update(message.RestoreStateMsg(state.Messages))
+	cmds = append(cmds, cmd)
+
+	return m, tea.Batch(cmds...)
+}
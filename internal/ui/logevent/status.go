@@ -0,0 +1,36 @@
+package logevent
+
+// Status is what the status bar shows about the events of the model
+type Status struct {
+	Events     int
+	MorePages  bool // newer pages
+	OlderPages bool
+	Full       bool // no more pages are fetched as the most events allowed are held
+	Loading    bool
+	Following  bool
+
+	FilterPattern string
+	Start         int64
+	End           int64
+	// Invocations is the invocation filter, empty unless in lambda mode
+	Invocations string
+}
+
+// Status returns the loading and filter state of the model
+func (m Model) Status() Status {
+	status := Status{
+		Events:        len(m.events),
+		MorePages:     m.eventPaginator != nil && m.newerPages,
+		OlderPages:    m.eventPaginator != nil && m.olderPages,
+		Full:          m.full(),
+		Loading:       m.loading,
+		Following:     m.following,
+		FilterPattern: m.filterPattern,
+		Start:         m.startTime,
+		End:           m.endTime,
+	}
+	if m.lambdaMode {
+		status.Invocations = m.invocationFilter.String()
+	}
+	return status
+}
@@ -1,14 +1,14 @@
 package timestamp
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	"github.com/charmbracelet/bubbles/list"
+
+	"clviewer/internal/ui/timeformat"
 )
 
 var (
@@ -18,23 +18,22 @@ var (
 
 // List.Item that contains cloudwatch events as its content
 type Item struct {
-	TimeStamp string
+	TimeStamp int64 // milliseconds since epoch
 	Message   string
 }
 
-func (i Item) Title() string       { return i.TimeStamp }
+func (i Item) Title() string       { return strconv.FormatInt(i.TimeStamp, 10) }
 func (i Item) Description() string { return "" }
 func (i Item) FilterValue() string { return i.Message }
 
 // TODO combine these two functions
-func (i Item) getTruncatedTimeStamp(maxLength int) string {
+// prev is the timestamp of the previous item, or -1 for the first item
+func (i Item) getTruncatedTimeStamp(maxLength int, format timeformat.Format, prev int64) string {
 	if maxLength < 10 {
 		maxLength = 10
 	}
 
-	// TODO add error handling
-	timeInt, _ := strconv.ParseInt(i.TimeStamp, 10, 64)
-	time := time.Unix(0, timeInt*int64(time.Millisecond)).Format("2006-01-02 15:04 MST")
+	time := format.Format(i.TimeStamp, prev)
 	if len(time) > maxLength {
 		return time[0:maxLength-3] + "..."
 	}
@@ -57,13 +56,13 @@ func logEventsToItemList(logEvents []types.OutputLogEvent) []list.Item {
 	var items []list.Item
 	for k := range logEvents {
 		msg := aws.ToString(logEvents[k].Message)
-		timeStamp := logEvents[k].Timestamp
+		timeStamp := aws.ToInt64(logEvents[k].Timestamp)
 
 		items = append(
 			items,
 			Item{
 				Message:   msg,
-				TimeStamp: fmt.Sprintf("%v", *timeStamp),
+				TimeStamp: timeStamp,
 			},
 		)
 	}
@@ -6,9 +6,13 @@ import (
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/ui/timeformat"
 )
 
-type ItemDelegate struct{}
+type ItemDelegate struct {
+	Format timeformat.Format
+}
 
 func (i *ItemDelegate) Height() int { return 1 }
 
@@ -24,7 +28,11 @@ func (i *ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem lis
 
 	item, ok := listItem.(Item)
 	if ok {
-		str = fmt.Sprintf("%s", item.getTruncatedTimeStamp(m.Width()-10))
+		str = fmt.Sprintf("%s", item.getTruncatedTimeStamp(
+			m.Width()-10,
+			i.Format,
+			previousTimeStamp(m, index),
+		))
 	} else {
 		str = fmt.Sprintf("%s", listItem.FilterValue())
 	}
@@ -38,3 +46,16 @@ func (i *ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem lis
 
 	fmt.Fprint(w, fn(str))
 }
+
+// previousTimeStamp returns the timestamp of the visible item before index,
+// or -1 if there isn't one
+func previousTimeStamp(m list.Model, index int) int64 {
+	if index <= 0 {
+		return -1
+	}
+	prev, ok := m.VisibleItems()[index-1].(Item)
+	if !ok {
+		return -1
+	}
+	return prev.TimeStamp
+}
@@ -1,10 +1,16 @@
 package timestamp
 
 import (
+	"sort"
+
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"clviewer/internal/commands"
+	"clviewer/internal/ui/mouse"
+	"clviewer/internal/ui/timeformat"
 )
 
 var (
@@ -29,7 +35,12 @@ type Model struct {
 func New(
 	title string,
 ) Model {
-	eventList := list.New([]list.Item{}, &ItemDelegate{}, 0, 0)
+	eventList := list.New(
+		[]list.Item{},
+		&ItemDelegate{Format: timeformat.Default()},
+		0,
+		0,
+	)
 
 	eventList.SetShowStatusBar(false)
 	eventList.SetFilteringEnabled(true)
@@ -51,12 +62,21 @@ func (m Model) Init() tea.Cmd {
 
 type LoadMoreEventsMsg []types.OutputLogEvent
 
+// PrependEventsMsg adds events before the listed events, keeping the selected
+// event selected
+type PrependEventsMsg []types.OutputLogEvent
+
+// DropEventsMsg drops the N oldest events, keeping the selected event selected
+type DropEventsMsg struct{ N int }
+
 type ResetMsg struct{}
 
 type NextEventMsg struct{}
 
 type PrevEventMsg struct{}
 
+type SelectEventMsg struct{ Index int }
+
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
@@ -64,7 +84,9 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.List.SetWidth(msg.Width)
-		m.List.SetHeight(msg.Height)
+		// the list is a line taller than its height once it has more than
+		// one page, the line above the pagination isn't counted
+		m.List.SetHeight(msg.Height - 1)
 		return m, nil
 	case tea.KeyMsg:
 		return m.updateKeyMsg(msg)
@@ -73,6 +95,25 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			m.List.Items(),
 			logEventsToItemList(msg)...,
 		))
+	case PrependEventsMsg:
+		index := m.List.Index()
+		m.List.SetItems(append(
+			logEventsToItemList(msg),
+			m.List.Items()...,
+		))
+		m.List.Select(index + len(msg))
+	case DropEventsMsg:
+		items := m.List.Items()
+		n := msg.N
+		if n > len(items) {
+			n = len(items)
+		}
+		index := m.List.Index() - n
+		if index < 0 {
+			index = 0
+		}
+		m.List.SetItems(append([]list.Item(nil), items[n:]...))
+		m.List.Select(index)
 	case ResetMsg:
 		m.List.ResetSelected()
 		m.List.SetItems([]list.Item{})
@@ -80,6 +121,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.List.CursorDown()
 	case PrevEventMsg:
 		m.List.CursorUp()
+	case SelectEventMsg:
+		m.List.Select(msg.Index)
+	case commands.SetTimeFormatMsg:
+		m.List.SetDelegate(&ItemDelegate{Format: msg.Format})
 	}
 
 	m.List, cmd = m.List.Update(msg)
@@ -92,6 +137,39 @@ func (m Model) View() string {
 	return m.List.View()
 }
 
+// VisibleIndexes returns the indexes of the events the list's filter matches,
+// in the order of the events, or nil if the list isn't filtered
+func (m Model) VisibleIndexes() []int {
+	if m.List.FilterState() == list.Unfiltered {
+		return nil
+	}
+
+	// the filter ranks the items it matches, so they're found by value
+	indexes := map[list.Item][]int{}
+	for i, item := range m.List.Items() {
+		indexes[item] = append(indexes[item], i)
+	}
+	visible := make([]int, 0, len(m.List.VisibleItems()))
+	for _, item := range m.List.VisibleItems() {
+		if is := indexes[item]; len(is) > 0 {
+			visible = append(visible, is[0])
+			indexes[item] = is[1:]
+		}
+	}
+	sort.Ints(visible)
+	return visible
+}
+
+// EventAt returns the index of the event drawn at row y of the list, clicks
+// are ignored while the list is filtered as its indexes aren't the events'
+func (m Model) EventAt(y int) (int, bool) {
+	if m.List.FilterState() != list.Unfiltered {
+		return 0, false
+	}
+	d := ItemDelegate{}
+	return mouse.ListIndex(m.List, y, d.Height()+d.Spacing())
+}
+
 // updateKeyMsg updates model based on the tea.KeyMsg
 func (m Model) updateKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 	var (
@@ -0,0 +1,32 @@
+package loggroup
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/ui/palette"
+)
+
+// runKeyMsg runs the action bound to a key when it's chosen from the command
+// palette, on the group selected in the list
+type runKeyMsg tea.KeyMsg
+
+func init() {
+	palette.Register(
+		keyAction("retention", "show or change how long the selected log group keeps events", keys.Retention),
+		keyAction("tags", "show or change the tags of the selected log group", keys.Tags),
+		keyAction("filters", "show the metric and subscription filters of the selected log group", keys.Filters),
+		keyAction("delete group", "delete the selected log group and all of its streams", keys.Delete),
+	)
+}
+
+func keyAction(name, help string, binding key.Binding) palette.Action {
+	return palette.Action{
+		Name: name,
+		Help: help,
+		Key:  binding,
+		Msg: func(string) tea.Msg {
+			return runKeyMsg(palette.KeyMsg(binding))
+		},
+	}
+}
@@ -0,0 +1,222 @@
+package loggroup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	group "clviewer/internal/cloudwatch/group"
+	"clviewer/internal/commands"
+)
+
+// The filters panel lists the metric and subscription filters of a group
+// beside the group list. It takes the keys while it's open, enter tests the
+// pattern of the selected filter against the loaded events.
+
+var (
+	panelStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderLeft(true).
+			BorderForeground(lipgloss.Color("241")).
+			PaddingLeft(1)
+
+	headingStyle = lipgloss.NewStyle().Bold(true)
+	detailStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+// filter is a metric or subscription filter as it's listed in the panel
+type filter struct {
+	name    string
+	pattern string
+	target  string // the metric it counts, or where events are sent
+}
+
+// filtersPanel is the filters of a group, metric filters first
+type filtersPanel struct {
+	open          bool
+	group         string
+	metric        []filter
+	subscriptions []filter
+	loaded        bool
+	cursor        int
+}
+
+// filtersLoadedMsg is the filters of a group, described when the panel opens
+type filtersLoadedMsg struct {
+	group         string
+	metric        []types.MetricFilter
+	subscriptions []types.SubscriptionFilter
+}
+
+// toggleFilters opens the panel for the selected group, or closes it
+func (m Model) toggleFilters() (Model, tea.Cmd) {
+	if m.filters.open {
+		m.filters.open = false
+		m.resize()
+		return m, nil
+	}
+	i, ok := m.List.SelectedItem().(Item)
+	if !ok {
+		return m, nil
+	}
+
+	m.filters = filtersPanel{open: true, group: string(i)}
+	m.resize()
+	return m, m.loadFilters(string(i))
+}
+
+func (m Model) loadFilters(name string) tea.Cmd {
+	ctx, cw := m.tabCtx, m.cw
+	return func() tea.Msg {
+		metric, err := group.MetricFilters(ctx, cw, name)
+		if err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error loading metric filters of %s: %w", name, err)}
+		}
+		subscriptions, err := group.SubscriptionFilters(ctx, cw, name)
+		if err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error loading subscription filters of %s: %w", name, err)}
+		}
+		return filtersLoadedMsg{group: name, metric: metric, subscriptions: subscriptions}
+	}
+}
+
+// setFilters lists the filters loaded, if the panel is still open for their
+// group
+func (m Model) setFilters(msg filtersLoadedMsg) Model {
+	if !m.filters.open || m.filters.group != msg.group {
+		return m
+	}
+
+	m.filters.metric = nil
+	for _, f := range msg.metric {
+		var metrics []string
+		for _, t := range f.MetricTransformations {
+			metrics = append(metrics, fmt.Sprintf("%s/%s = %s",
+				aws.ToString(t.MetricNamespace),
+				aws.ToString(t.MetricName),
+				aws.ToString(t.MetricValue),
+			))
+		}
+		m.filters.metric = append(m.filters.metric, filter{
+			name:    aws.ToString(f.FilterName),
+			pattern: aws.ToString(f.FilterPattern),
+			target:  strings.Join(metrics, ", "),
+		})
+	}
+	m.filters.subscriptions = nil
+	for _, f := range msg.subscriptions {
+		m.filters.subscriptions = append(m.filters.subscriptions, filter{
+			name:    aws.ToString(f.FilterName),
+			pattern: aws.ToString(f.FilterPattern),
+			target:  aws.ToString(f.DestinationArn),
+		})
+	}
+	m.filters.loaded = true
+	m.filters.cursor = 0
+	return m
+}
+
+// handleFiltersKey moves through the filters of the open panel and tests
+// their patterns
+func (m Model) handleFiltersKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	n := len(m.filters.metric) + len(m.filters.subscriptions)
+	switch {
+	case key.Matches(msg, keys.Filters, keys.CloseFilters):
+		return m.toggleFilters()
+	case key.Matches(msg, keys.FilterDown):
+		if m.filters.cursor < n-1 {
+			m.filters.cursor++
+		}
+	case key.Matches(msg, keys.FilterUp):
+		if m.filters.cursor > 0 {
+			m.filters.cursor--
+		}
+	case key.Matches(msg, keys.TestPattern):
+		if f, ok := m.filters.selected(); ok {
+			return m, commands.TestPattern(f.pattern)
+		}
+	}
+	return m, nil
+}
+
+// selected returns the filter under the cursor, false if there are none
+func (p filtersPanel) selected() (filter, bool) {
+	if p.cursor < len(p.metric) {
+		return p.metric[p.cursor], true
+	}
+	if i := p.cursor - len(p.metric); i < len(p.subscriptions) {
+		return p.subscriptions[i], true
+	}
+	return filter{}, false
+}
+
+// view renders the panel width columns wide, scrolled to keep the selected
+// filter in its height
+func (p filtersPanel) view(width, height int) string {
+	textWidth := width - panelStyle.GetHorizontalFrameSize()
+	lines := []string{titleStyle.Render("Filters of " + p.group), ""}
+
+	selectedLine := 0
+	section := func(heading string, filters []filter, first int, target string) {
+		lines = append(lines, headingStyle.Render(heading))
+		if !p.loaded {
+			lines = append(lines, itemStyle.Render("loading…"))
+		} else if len(filters) == 0 {
+			lines = append(lines, itemStyle.Render("none"))
+		}
+		for i, f := range filters {
+			name := itemStyle.Render(f.name)
+			if first+i == p.cursor {
+				selectedLine = len(lines)
+				name = selectedItemStyle.Render("> " + f.name)
+			}
+			pattern := f.pattern
+			if pattern == "" {
+				pattern = `""`
+			}
+			lines = append(lines,
+				name,
+				itemStyle.Render(detailStyle.Render("  pattern: "+pattern)),
+				itemStyle.Render(detailStyle.Render("  "+target+": "+f.target)),
+			)
+		}
+		lines = append(lines, "")
+	}
+	section("Metric filters", p.metric, 0, "metric")
+	section("Subscription filters", p.subscriptions, len(p.metric), "destination")
+	if p.loaded && len(p.metric)+len(p.subscriptions) > 0 {
+		lines = append(lines, detailStyle.Render("enter tests the pattern against the loaded events"))
+	}
+
+	// keep the title, and the selected filter with its details, in view
+	if bottom := selectedLine + 3; bottom > height && height > 2 {
+		lines = append(lines[:2], lines[bottom-height+2:]...)
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for i, line := range lines {
+		lines[i] = lipgloss.NewStyle().MaxWidth(textWidth).Render(line)
+	}
+
+	return panelStyle.
+		Width(width - panelStyle.GetHorizontalBorderSize()).
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// filtersHelp is the keys of the filters panel for the help overlay
+type filtersHelp struct{}
+
+func (filtersHelp) ShortHelp() []key.Binding {
+	return []key.Binding{keys.FilterUp, keys.FilterDown, keys.TestPattern, keys.CloseFilters}
+}
+
+func (h filtersHelp) FullHelp() [][]key.Binding {
+	return [][]key.Binding{h.ShortHelp()}
+}
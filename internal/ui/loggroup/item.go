@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/charmbracelet/bubbles/list"
 
+	"clviewer/internal/cloudwatch/client"
 	group "clviewer/internal/cloudwatch/group"
 )
 
@@ -17,8 +18,8 @@ type Item string
 
 func (i Item) FilterValue() string { return string(i) }
 
-func GetLogGroupsAsItemList(pattern string) []list.Item {
-	logGroups := group.GetLogGroups(context.Background(), cloudwatchlogs.DescribeLogGroupsInput{
+func GetLogGroupsAsItemList(ctx context.Context, cw client.API, pattern string) ([]list.Item, error) {
+	logGroups, err := group.GetLogGroups(ctx, cw, cloudwatchlogs.DescribeLogGroupsInput{
 		LogGroupNamePrefix: aws.String(pattern),
 	})
 
@@ -29,7 +30,7 @@ func GetLogGroupsAsItemList(pattern string) []list.Item {
 		groups = append(groups, Item(name))
 	}
 
-	return groups
+	return groups, err
 }
 
 func (i Item) getTruncatedDescription(maxLength int) string {
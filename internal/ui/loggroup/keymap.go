@@ -0,0 +1,56 @@
+package loggroup
+
+import "github.com/charmbracelet/bubbles/key"
+
+type keyMap struct {
+	Select    key.Binding
+	Retention key.Binding
+	Tags      key.Binding
+	Delete    key.Binding
+	Filters   key.Binding
+
+	// keys of the filters panel while it's open
+	FilterUp     key.Binding
+	FilterDown   key.Binding
+	TestPattern  key.Binding
+	CloseFilters key.Binding
+}
+
+var keys = keyMap{
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "open group"),
+	),
+	Retention: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "retention"),
+	),
+	Tags: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "tags"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "delete group"),
+	),
+	Filters: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "filters"),
+	),
+	FilterUp: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous filter"),
+	),
+	FilterDown: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next filter"),
+	),
+	TestPattern: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "test pattern"),
+	),
+	CloseFilters: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close filters"),
+	),
+}
@@ -0,0 +1,305 @@
+package loggroup
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/cloudwatch/client"
+	group "clviewer/internal/cloudwatch/group"
+	"clviewer/internal/commands"
+	"clviewer/internal/ui/prompt"
+)
+
+// The retention and tags of a group are described when they're asked for,
+// then shown in a prompt to change them, or in the status bar when the client
+// is read-only. Every change, like deleting a group, then asks for the group's
+// name to be typed before it's made.
+
+// retentionLoadedMsg and tagsLoadedMsg are the group described for the
+// retention and tags actions
+type (
+	retentionLoadedMsg struct {
+		group types.LogGroup
+	}
+	tagsLoadedMsg struct {
+		name string
+		arn  string
+		tags map[string]string
+	}
+)
+
+// setRetentionMsg, editTagsMsg and deleteGroupMsg are submitted by the
+// prompts of the actions
+type (
+	setRetentionMsg struct {
+		name string
+		days int32
+	}
+	editTagsMsg struct {
+		name string
+		arn  string
+		edit group.TagEdit
+	}
+	deleteGroupMsg struct {
+		name string
+	}
+)
+
+// confirmMsg asks for the name of the group to be typed before msg changes it
+type confirmMsg struct {
+	name   string
+	title  string
+	change string // what msg changes
+	msg    tea.Msg
+}
+
+// groupDeletedMsg is the name of a group once it's been deleted
+type groupDeletedMsg string
+
+// handleManageKey runs the action of a management key on the selected group
+func (m Model) handleManageKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if key.Matches(msg, keys.Filters) {
+		return m.toggleFilters()
+	}
+
+	i, ok := m.List.SelectedItem().(Item)
+	if !ok {
+		return m, nil
+	}
+	name := string(i)
+
+	switch {
+	case key.Matches(msg, keys.Retention):
+		return m, m.loadRetention(name)
+	case key.Matches(msg, keys.Tags):
+		return m, m.loadTags(name)
+	case key.Matches(msg, keys.Delete):
+		if client.IsReadOnly(m.cw) {
+			return m, commands.Error(fmt.Errorf("can't delete %s: %w", name, client.ErrReadOnly))
+		}
+		return m, promptDelete(name)
+	}
+	return m, nil
+}
+
+func (m Model) loadRetention(name string) tea.Cmd {
+	ctx, cw := m.tabCtx, m.cw
+	return func() tea.Msg {
+		g, err := group.Describe(ctx, cw, name)
+		if err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error loading retention of %s: %w", name, err)}
+		}
+		return retentionLoadedMsg{group: g}
+	}
+}
+
+// promptRetention asks for the new retention of a group, a read-only client
+// only shows it
+func (m Model) promptRetention(msg retentionLoadedMsg) tea.Cmd {
+	name := aws.ToString(msg.group.LogGroupName)
+	days := aws.ToInt32(msg.group.RetentionInDays)
+	if client.IsReadOnly(m.cw) {
+		return commands.Notice(fmt.Sprintf("events of %s %s", name, retentionPhrase(days)))
+	}
+
+	value := "never"
+	if days > 0 {
+		value = fmt.Sprint(days)
+	}
+	return prompt.Open(prompt.Prompt{
+		Title: "Retention of " + name,
+		Body: fmt.Sprintf(
+			"Events %s. Enter the days to keep them, or never to keep them forever.",
+			retentionPhrase(days),
+		),
+		Placeholder: "days or never",
+		Value:       value,
+		Validate: func(value string) error {
+			_, err := group.ParseRetention(value)
+			return err
+		},
+		Msg: func(value string) tea.Msg {
+			days, _ := group.ParseRetention(value)
+			return confirmMsg{
+				name:   name,
+				title:  "Change retention of " + name,
+				change: expiryPhrase(days),
+				msg:    setRetentionMsg{name: name, days: days},
+			}
+		},
+	})
+}
+
+// retentionPhrase describes how long events are kept, 0 for forever
+func retentionPhrase(days int32) string {
+	if days == 0 {
+		return "never expire"
+	}
+	return fmt.Sprintf("are kept for %d days", days)
+}
+
+// expiryPhrase describes what a new retention does to the events of a group
+func expiryPhrase(days int32) string {
+	if days == 0 {
+		return "Events will never expire."
+	}
+	return fmt.Sprintf("Events older than %d days will be deleted, which can't be undone.", days)
+}
+
+// changedPhrase describes how long events are kept from now on
+func changedPhrase(days int32) string {
+	if days == 0 {
+		return "now never expire"
+	}
+	return fmt.Sprintf("are now kept for %d days", days)
+}
+
+func (m Model) setRetention(msg setRetentionMsg) tea.Cmd {
+	ctx, cw := m.tabCtx, m.cw
+	return func() tea.Msg {
+		if err := group.SetRetention(ctx, cw, msg.name, msg.days); err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error setting retention of %s: %w", msg.name, err)}
+		}
+		return commands.NoticeMsg{
+			Text: fmt.Sprintf("events of %s %s", msg.name, changedPhrase(msg.days)),
+		}
+	}
+}
+
+func (m Model) loadTags(name string) tea.Cmd {
+	ctx, cw := m.tabCtx, m.cw
+	return func() tea.Msg {
+		g, err := group.Describe(ctx, cw, name)
+		if err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error loading tags of %s: %w", name, err)}
+		}
+		arn := group.ResourceARN(g)
+		tags, err := group.Tags(ctx, cw, arn)
+		if err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error loading tags of %s: %w", name, err)}
+		}
+		return tagsLoadedMsg{name: name, arn: arn, tags: tags}
+	}
+}
+
+// promptTags asks for the tags of a group to change, a read-only client only
+// shows them
+func (m Model) promptTags(msg tagsLoadedMsg) tea.Cmd {
+	if client.IsReadOnly(m.cw) {
+		if len(msg.tags) == 0 {
+			return commands.Notice(msg.name + " has no tags")
+		}
+		return commands.Notice(fmt.Sprintf("tags of %s: %s", msg.name, strings.Join(tagList(msg.tags, "="), ", ")))
+	}
+
+	current := "No tags."
+	if len(msg.tags) > 0 {
+		current = strings.Join(tagList(msg.tags, " = "), "\n")
+	}
+	return prompt.Open(prompt.Prompt{
+		Title:       "Tags of " + msg.name,
+		Body:        current + "\n\nEnter key=value to add or change a tag, -key to remove one.",
+		Placeholder: "key=value -key",
+		Validate: func(value string) error {
+			_, err := group.ParseTagEdit(value)
+			return err
+		},
+		Msg: func(value string) tea.Msg {
+			edit, _ := group.ParseTagEdit(value)
+			return confirmMsg{
+				name:   msg.name,
+				title:  "Change tags of " + msg.name,
+				change: tagEditPhrase(edit),
+				msg:    editTagsMsg{name: msg.name, arn: msg.arn, edit: edit},
+			}
+		},
+	})
+}
+
+// tagEditPhrase describes the tags an edit sets and removes
+func tagEditPhrase(edit group.TagEdit) string {
+	var phrases []string
+	if len(edit.Set) > 0 {
+		phrases = append(phrases, "Sets "+strings.Join(tagList(edit.Set, " = "), ", ")+".")
+	}
+	if len(edit.Remove) > 0 {
+		phrases = append(phrases, "Removes "+strings.Join(edit.Remove, ", ")+".")
+	}
+	return strings.Join(phrases, " ")
+}
+
+// tagList returns the tags as key, sep and value, sorted by key
+func tagList(tags map[string]string, sep string) []string {
+	list := make([]string, 0, len(tags))
+	for k, v := range tags {
+		list = append(list, k+sep+v)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func (m Model) editTags(msg editTagsMsg) tea.Cmd {
+	ctx, cw := m.tabCtx, m.cw
+	return func() tea.Msg {
+		if err := group.EditTags(ctx, cw, msg.arn, msg.edit); err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error changing tags of %s: %w", msg.name, err)}
+		}
+		return commands.NoticeMsg{Text: "changed the tags of " + msg.name}
+	}
+}
+
+// promptDelete asks for the name of the group to be typed to delete it
+func promptDelete(name string) tea.Cmd {
+	return promptConfirm(confirmMsg{
+		name:   name,
+		title:  "Delete " + name,
+		change: "This deletes the log group along with all of its streams and events, which can't be undone.",
+		msg:    deleteGroupMsg{name: name},
+	})
+}
+
+// promptConfirm asks for the name of the group to be typed to make a change
+func promptConfirm(msg confirmMsg) tea.Cmd {
+	return prompt.Open(prompt.Prompt{
+		Title:       msg.title,
+		Body:        msg.change + " Type the name of the group to confirm.",
+		Placeholder: msg.name,
+		Validate: func(value string) error {
+			if value != msg.name {
+				return errors.New("the name doesn't match")
+			}
+			return nil
+		},
+		Msg: func(string) tea.Msg {
+			return msg.msg
+		},
+	})
+}
+
+func (m Model) deleteGroup(msg deleteGroupMsg) tea.Cmd {
+	ctx, cw := m.tabCtx, m.cw
+	return func() tea.Msg {
+		if err := group.Delete(ctx, cw, msg.name); err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error deleting %s: %w", msg.name, err)}
+		}
+		return groupDeletedMsg(msg.name)
+	}
+}
+
+// removeGroup removes a deleted group from the list
+func (m Model) removeGroup(name string) (Model, tea.Cmd) {
+	for i, item := range m.List.Items() {
+		if item == Item(name) {
+			m.List.RemoveItem(i)
+			break
+		}
+	}
+	return m, commands.Notice("deleted " + name)
+}
@@ -1,13 +1,20 @@
 package loggroup
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"clviewer/internal/cloudwatch/client"
 	"clviewer/internal/commands"
+	"clviewer/internal/ui/mouse"
 )
 
 const listHeight = 14
@@ -31,33 +38,68 @@ type Model struct {
 	List          list.Model
 	SelectedGroup string
 	padding       int
+	groupPattern  string
+	cw            client.API
+	ctx           context.Context    // of the fetch of the groups
+	cancel        context.CancelFunc // cancels it
+	tabCtx        context.Context    // of the calls that manage groups, cancelled when the tab is closed
+	filters       filtersPanel
+	width         int
+	height        int
 }
 
+// groupsLoadedMsg is sent once the log groups have been fetched
+type groupsLoadedMsg []list.Item
+
 func New(
+	ctx context.Context,
+	cw client.API,
 	title string,
 	groupPattern string,
 	intialGroup string,
 ) Model {
-	itemList := GetLogGroupsAsItemList(groupPattern)
-
-	groupList := list.New(itemList, &ItemDelegate{}, 0, 0)
+	groupList := list.New([]list.Item{}, &ItemDelegate{}, 0, 0)
 
 	groupList.SetShowStatusBar(false)
 	groupList.SetFilteringEnabled(true)
 	groupList.SetShowHelp(false)
 
+	groupList.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{keys.Select}
+	}
+	groupList.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{keys.Select, keys.Retention, keys.Tags, keys.Filters, keys.Delete}
+	}
+
 	groupList.Title = title
 	groupList.Styles.Title = titleStyle
 	groupList.Styles.PaginationStyle = paginationStyle
 
+	fetchCtx, cancel := context.WithCancel(ctx)
 	return Model{
 		List:          groupList,
-		SelectedGroup: "initialGroup",
+		SelectedGroup: intialGroup,
+		groupPattern:  groupPattern,
+		cw:            cw,
+		ctx:           fetchCtx,
+		cancel:        cancel,
+		tabCtx:        ctx,
 	}
 }
 
+// Init fetches the log groups in the background
 func (m Model) Init() tea.Cmd {
-	return nil
+	ctx, cw, groupPattern := m.ctx, m.cw, m.groupPattern
+	return func() tea.Msg {
+		groups, err := GetLogGroupsAsItemList(ctx, cw, groupPattern)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		if err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error loading log groups: %w", err)}
+		}
+		return groupsLoadedMsg(groups)
+	}
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
@@ -66,10 +108,37 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.List.SetWidth(msg.Width)
-		m.List.SetHeight(msg.Height)
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
+		return m, nil
+	case groupsLoadedMsg:
+		return m, m.List.SetItems(msg)
+	case commands.CancelMsg:
+		m.cancel()
 		return m, nil
+	case runKeyMsg:
+		return m.handleManageKey(tea.KeyMsg(msg))
+	case retentionLoadedMsg:
+		return m, m.promptRetention(msg)
+	case tagsLoadedMsg:
+		return m, m.promptTags(msg)
+	case confirmMsg:
+		return m, promptConfirm(msg)
+	case setRetentionMsg:
+		return m, m.setRetention(msg)
+	case editTagsMsg:
+		return m, m.editTags(msg)
+	case deleteGroupMsg:
+		return m, m.deleteGroup(msg)
+	case groupDeletedMsg:
+		return m.removeGroup(string(msg))
+	case filtersLoadedMsg:
+		return m.setFilters(msg), nil
 	case tea.KeyMsg:
+		if m.filters.open && !m.List.SettingFilter() {
+			return m.handleFiltersKey(msg)
+		}
+
 		if isRedrawKey(msg) {
 			cmds = append(cmds, commands.RedrawWindows())
 		}
@@ -87,28 +156,84 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			// TODO update to not use key press, but checking to see if
 			// selected item changed to send the updateStreamListCommand?
 			// Could this logic be moved up to ui/model?
-			i, ok := m.List.SelectedItem().(Item)
-			if ok {
-				m.SelectedGroup = string(i)
-			}
-			log.Printf("loggroup: %+v", m.SelectedGroup)
+			m, cmd = m.openSelected()
+			return m, tea.Batch(append(cmds, cmd)...)
+		}
 
-			cmds := append(cmds, commands.UpdateStreamListItems(m.SelectedGroup))
-			return m, tea.Batch(cmds...)
+		if !m.List.SettingFilter() && key.Matches(msg, keys.Retention, keys.Tags, keys.Filters, keys.Delete) {
+			return m.handleManageKey(msg)
 		}
+	case mouse.Msg:
+		return m.handleMouse(msg)
 	}
 	m.List, cmd = m.List.Update(msg)
 	return m, cmd
 }
 
+// openSelected lists the streams of the selected group
+func (m Model) openSelected() (Model, tea.Cmd) {
+	i, ok := m.List.SelectedItem().(Item)
+	if ok {
+		m.SelectedGroup = string(i)
+	}
+	log.Printf("loggroup: %+v", m.SelectedGroup)
+
+	return m, commands.UpdateStreamListItems(m.SelectedGroup)
+}
+
+// handleMouse selects the group that was clicked on, double clicking opens it
+func (m Model) handleMouse(msg mouse.Msg) (Model, tea.Cmd) {
+	if msg.X >= m.List.Width() {
+		// on the filters panel
+		return m, nil
+	}
+	mouse.ScrollList(&m.List, msg)
+	if !msg.Click() {
+		return m, nil
+	}
+
+	d := ItemDelegate{}
+	index, ok := mouse.ListIndex(m.List, msg.Y, d.Height()+d.Spacing())
+	if !ok {
+		return m, nil
+	}
+	m.List.Select(index)
+
+	if msg.Double {
+		// the same as pressing enter, which redraws the windows too
+		m, cmd := m.openSelected()
+		return m, tea.Batch(commands.RedrawWindows(), cmd)
+	}
+	return m, nil
+}
+
 func (m Model) View() string {
-	return lipgloss.NewStyle().
+	list := lipgloss.NewStyle().
 		PaddingRight(m.List.Width() - lipgloss.Width(m.List.View())).
 		Render(m.List.View())
+	if !m.filters.open {
+		return list
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, m.filters.view(m.width-m.List.Width(), m.height))
 }
 
-func (m Model) HelpView() string {
-	return m.List.Styles.HelpStyle.Render(m.List.Help.View(m.List))
+// resize fits the list to the window, beside the filters panel when it's
+// open
+func (m *Model) resize() {
+	width := m.width
+	if m.filters.open {
+		width = m.width * 2 / 5
+	}
+	m.List.SetWidth(width)
+	m.List.SetHeight(m.height)
+}
+
+// HelpKeys returns the keys of the group list for the help overlay
+func (m Model) HelpKeys() help.KeyMap {
+	if m.filters.open {
+		return filtersHelp{}
+	}
+	return m.List
 }
 
 // isRedrawKey checks to see if the keypress should trigger a redraw of the ui
@@ -0,0 +1,38 @@
+package logstream
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/ui/palette"
+)
+
+// loadMoreMsg, reloadMsg and deleteMsg run the stream actions of the command
+// palette, whichever pane is focused
+type (
+	loadMoreMsg struct{}
+	reloadMsg   struct{}
+	deleteMsg   struct{}
+)
+
+func init() {
+	palette.Register(
+		palette.Action{
+			Name: "load more streams",
+			Help: "load the next page of log streams",
+			Key:  keys.LoadMore,
+			Msg:  func(string) tea.Msg { return loadMoreMsg{} },
+		},
+		palette.Action{
+			Name: "reload streams",
+			Help: "load the log streams again",
+			Key:  keys.Reload,
+			Msg:  func(string) tea.Msg { return reloadMsg{} },
+		},
+		palette.Action{
+			Name: "delete stream",
+			Help: "delete the selected log stream and its events",
+			Key:  keys.Delete,
+			Msg:  func(string) tea.Msg { return deleteMsg{} },
+		},
+	)
+}
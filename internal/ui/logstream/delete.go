@@ -0,0 +1,76 @@
+package logstream
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/cloudwatch/client"
+	"clviewer/internal/cloudwatch/stream"
+	"clviewer/internal/commands"
+	"clviewer/internal/ui/prompt"
+)
+
+// deleteStreamMsg is submitted by the prompt to delete a stream, and
+// streamDeletedMsg is sent once it's been deleted
+type (
+	deleteStreamMsg struct {
+		group  string
+		stream string
+	}
+	streamDeletedMsg deleteStreamMsg
+)
+
+// promptDelete asks for the name of the selected stream to be typed to
+// delete it
+func (m Model) promptDelete() tea.Cmd {
+	i, ok := m.List.SelectedItem().(Item)
+	if !ok {
+		return nil
+	}
+	if client.IsReadOnly(m.cw) {
+		return commands.Error(fmt.Errorf("can't delete %s: %w", i.name, client.ErrReadOnly))
+	}
+
+	group := m.currentGroup
+	return prompt.Open(prompt.Prompt{
+		Title: "Delete " + i.name,
+		Body: fmt.Sprintf("This deletes the log stream and its events from %s, "+
+			"which can't be undone. Type the name of the stream to delete it.", group),
+		Placeholder: i.name,
+		Validate: func(value string) error {
+			if value != i.name {
+				return errors.New("the name doesn't match")
+			}
+			return nil
+		},
+		Msg: func(string) tea.Msg {
+			return deleteStreamMsg{group: group, stream: i.name}
+		},
+	})
+}
+
+func (m Model) deleteStream(msg deleteStreamMsg) tea.Cmd {
+	ctx, cw := m.ctx, m.cw
+	return func() tea.Msg {
+		if err := stream.Delete(ctx, cw, msg.group, msg.stream); err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error deleting %s: %w", msg.stream, err)}
+		}
+		return streamDeletedMsg(msg)
+	}
+}
+
+// removeStream removes a deleted stream from the list, if the list is still
+// of its group
+func (m Model) removeStream(msg streamDeletedMsg) (Model, tea.Cmd) {
+	if msg.group == m.currentGroup {
+		for i, item := range m.List.Items() {
+			if item.(Item).name == msg.stream {
+				m.List.RemoveItem(i)
+				break
+			}
+		}
+	}
+	return m, commands.Notice("deleted " + msg.stream)
+}
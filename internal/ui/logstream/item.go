@@ -1,39 +1,55 @@
 package logstream
 
 import (
-	"time"
-
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	"github.com/charmbracelet/bubbles/list"
+
+	"clviewer/internal/ui/timeformat"
 )
 
 const maxDescriptionLength = 90
 
 // Item type
 type Item struct {
-	timestamp string
-	name      string
+	timestamp  string
+	firstEvent int64
+	name       string
 }
 
 func (i Item) FilterValue() string { return i.timestamp }
 
-func GetLogStreamsAsItemList(streams []types.LogStream) []list.Item {
+// Name returns the name of the log stream
+func (i Item) Name() string { return i.name }
+
+func GetLogStreamsAsItemList(streams []types.LogStream, format timeformat.Format) []list.Item {
 	var items []list.Item
 	for k := range streams {
 		name := aws.ToString(streams[k].LogStreamName)
-
-		timeInt := aws.ToInt64(streams[k].FirstEventTimestamp)
-		timestamp := time.Unix(0, timeInt*int64(time.Millisecond)).Format("2006-01-02 15:04 MDT")
+		firstEvent := aws.ToInt64(streams[k].FirstEventTimestamp)
 
 		items = append(items, Item{
-			timestamp: timestamp,
-			name:      name,
+			timestamp:  format.Format(firstEvent, -1),
+			firstEvent: firstEvent,
+			name:       name,
 		})
 	}
 	return items
 }
 
+// reformatItems formats the timestamp of each item in items with format
+func reformatItems(items []list.Item, format timeformat.Format) []list.Item {
+	formatted := make([]list.Item, 0, len(items))
+	for _, listItem := range items {
+		if item, ok := listItem.(Item); ok {
+			item.timestamp = format.Format(item.firstEvent, -1)
+			listItem = item
+		}
+		formatted = append(formatted, listItem)
+	}
+	return formatted
+}
+
 func (i Item) getTruncatedDescription(maxLength int) string {
 	if maxLength < 10 {
 		maxLength = 10
@@ -0,0 +1,29 @@
+package logstream
+
+import "github.com/charmbracelet/bubbles/key"
+
+type keyMap struct {
+	Select   key.Binding
+	LoadMore key.Binding
+	Reload   key.Binding
+	Delete   key.Binding
+}
+
+var keys = keyMap{
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "open stream"),
+	),
+	LoadMore: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "load more streams"),
+	),
+	Reload: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "reload streams"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "delete stream"),
+	),
+}
@@ -2,13 +2,22 @@ package logstream
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"clviewer/internal/cloudwatch/client"
 	"clviewer/internal/cloudwatch/stream"
 	"clviewer/internal/commands"
+	"clviewer/internal/paging"
+	"clviewer/internal/ui/mouse"
+	"clviewer/internal/ui/timeformat"
 )
 
 const listHeight = 14
@@ -33,9 +42,27 @@ type Model struct {
 	SelectedStream  string
 	currentGroup    string
 	streamPaginator *stream.Paginator
+	timeFormat      timeformat.Format
+	cw              client.API
+	ctx             context.Context    // cancelled when the tab is closed
+	cancel          context.CancelFunc // cancels the page being fetched
+	loading         bool
+	selectFirst     bool
+	prefetch        int // streams from the end of the list at which the next page is fetched
+	pageSize        int // streams fetched at a time
+}
+
+// streamsLoadedMsg is a page of streams fetched by paginator
+type streamsLoadedMsg struct {
+	paginator *stream.Paginator
+	streams   []types.LogStream
+	err       error
 }
 
 func New(
+	ctx context.Context,
+	cw client.API,
+	opts paging.Options,
 	title string,
 	initialGroup string,
 ) Model {
@@ -45,27 +72,43 @@ func New(
 	streamList.SetFilteringEnabled(true)
 	streamList.SetShowHelp(false)
 
+	streamList.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{keys.Select}
+	}
+	streamList.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{keys.Select, keys.LoadMore, keys.Reload, keys.Delete}
+	}
+
 	streamList.Title = title
 	streamList.Styles.Title = titleStyle
 	streamList.Styles.PaginationStyle = paginationStyle
 
-	model := Model{
+	return Model{
 		List:            streamList,
 		SelectedStream:  "",
 		currentGroup:    initialGroup,
-		streamPaginator: &stream.Paginator{},
+		streamPaginator: nil,
+		timeFormat:      timeformat.Default(),
+		cw:              cw,
+		ctx:             ctx,
+		prefetch:        opts.Prefetch,
+		pageSize:        opts.StreamPageSize,
 	}
+}
 
-	// initial group passed form cmd line arguments
-	if initialGroup != "" {
-		model.currentGroup = initialGroup
-		model, _ = model.UpdateStreamItems()
-	}
-	return model
+// SelectFirstStream makes the model open the most recent stream once the
+// first page of streams has loaded
+func (m Model) SelectFirstStream() Model {
+	m.selectFirst = true
+	return m
 }
 
+// Init loads the streams of the initial group passed from the cmd line
 func (m Model) Init() tea.Cmd {
-	return nil
+	if m.currentGroup == "" {
+		return nil
+	}
+	return commands.UpdateStreamListItems(m.currentGroup)
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
@@ -81,39 +124,90 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.List.SetHeight(msg.Height)
 		return m, nil
 	case tea.KeyMsg:
-		switch keypress := msg.String(); keypress {
-		case "ctrl+c":
+		switch {
+		case msg.String() == "ctrl+c":
 			return m, tea.Quit
-		case "L":
+		case key.Matches(msg, keys.LoadMore):
 			return m, m.loadMoreStreams()
-		case "R":
-			m, cmd := m.UpdateStreamItems()
-			return m, cmd
-		case "enter":
+		case key.Matches(msg, keys.Reload):
+			return m.UpdateStreamItems()
+		case key.Matches(msg, keys.Delete) && !m.List.SettingFilter():
+			return m, m.promptDelete()
+		case key.Matches(msg, keys.Select):
 			if m.List.SettingFilter() {
 				m.List, cmd = m.List.Update(msg)
 				return m, cmd
 			}
-
-			i, ok := m.List.SelectedItem().(Item)
-			if ok {
-				m.SelectedStream = i.name
-			}
-
-			return m, commands.UpdateEventListItems(m.currentGroup, m.SelectedStream)
+			return m.openSelected()
 		}
+	case mouse.Msg:
+		m, cmd = m.handleMouse(msg)
+		return m, tea.Batch(cmd, m.prefetchStreams())
+	case loadMoreMsg:
+		return m, m.loadMoreStreams()
+	case reloadMsg:
+		return m.UpdateStreamItems()
+	case deleteMsg:
+		return m, m.promptDelete()
+	case deleteStreamMsg:
+		return m, m.deleteStream(msg)
+	case streamDeletedMsg:
+		return m.removeStream(msg)
+	case streamsLoadedMsg:
+		return m.handleStreamsLoaded(msg)
+	case commands.CancelMsg:
+		m.cancelFetch()
+		return m, nil
 	case commands.UpdateStreamListItemsMsg:
 		m.currentGroup = msg.Group
 		m, cmd = m.UpdateStreamItems()
 		cmds = append(cmds, cmd)
+	case commands.SetTimeFormatMsg:
+		m.timeFormat = msg.Format
+		cmd = m.List.SetItems(reformatItems(m.List.Items(), m.timeFormat))
+		cmds = append(cmds, cmd)
 	}
 
 	m.List, cmd = m.List.Update(msg)
 	cmds = append(cmds, cmd)
 
+	if _, ok := msg.(tea.KeyMsg); ok {
+		cmds = append(cmds, m.prefetchStreams())
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
+// openSelected shows the events of the selected stream
+func (m Model) openSelected() (Model, tea.Cmd) {
+	i, ok := m.List.SelectedItem().(Item)
+	if ok {
+		m.SelectedStream = i.name
+	}
+
+	return m, commands.UpdateEventListItems(m.currentGroup, m.SelectedStream)
+}
+
+// handleMouse selects the stream that was clicked on, double clicking opens it
+func (m Model) handleMouse(msg mouse.Msg) (Model, tea.Cmd) {
+	mouse.ScrollList(&m.List, msg)
+	if !msg.Click() {
+		return m, nil
+	}
+
+	d := ItemDelegate{}
+	index, ok := mouse.ListIndex(m.List, msg.Y, d.Height()+d.Spacing())
+	if !ok {
+		return m, nil
+	}
+	m.List.Select(index)
+
+	if msg.Double {
+		return m.openSelected()
+	}
+	return m, nil
+}
+
 func (m Model) View() string {
 	return lipgloss.NewStyle().
 		PaddingRight(m.List.Width() - lipgloss.Width(m.List.View())).
@@ -121,37 +215,111 @@ func (m Model) View() string {
 }
 
 func (m Model) UpdateStreamItems() (Model, tea.Cmd) {
-	ctx := context.Background()
-
 	// reset list
 	m.SelectedStream = ""
 	m.List.ResetSelected()
 	m.List.SetItems(nil)
 
-	// get a new paginator for our log stream
-	paginator := stream.New(ctx, m.currentGroup)
+	// get a new paginator for our log stream, the page still loading from the
+	// previous one is cancelled
+	m.cancelFetch()
+	paginator := stream.New(m.ctx, m.cw, m.currentGroup, m.pageSize)
 	m.streamPaginator = &paginator
+	m.loading = false
 
 	// itemList := GetLogStreamsAsItemList(groupPattern)
 	// return m.List.SetItems(itemList)
 	return m, m.loadMoreStreams()
 }
 
+// loadMoreStreams fetches the next page of streams in the background, only
+// one page is fetched at a time
 func (m *Model) loadMoreStreams() tea.Cmd {
-	ctx := context.Background()
+	if m.streamPaginator == nil || m.loading {
+		return nil
+	}
+	m.loading = true
+
+	// the context of the last page is released once it arrives, so this only
+	// cancels one that was left behind
+	m.cancelFetch()
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancel = cancel
+
+	paginator := m.streamPaginator
+	return func() tea.Msg {
+		streams, err := paginator.NextPage(ctx)
+		return streamsLoadedMsg{
+			paginator: paginator,
+			streams:   streams,
+			err:       err,
+		}
+	}
+}
 
-	streams := m.streamPaginator.NextPage(ctx)
-	if streams == nil {
+// prefetchStreams fetches the next page of streams once the cursor is near
+// the end of the list
+func (m *Model) prefetchStreams() tea.Cmd {
+	if m.prefetch == 0 || m.streamPaginator == nil || !m.streamPaginator.HasMorePages() ||
+		m.List.FilterState() != list.Unfiltered {
+		return nil
+	}
+	if m.List.Index() < len(m.List.Items())-m.prefetch {
 		return nil
 	}
+	return m.loadMoreStreams()
+}
+
+// cancelFetch cancels the page being fetched, if any, and releases its
+// context
+func (m *Model) cancelFetch() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+}
+
+func (m Model) handleStreamsLoaded(msg streamsLoadedMsg) (Model, tea.Cmd) {
+	// the group was changed while the page was loading
+	if msg.paginator != m.streamPaginator {
+		return m, nil
+	}
+	m.loading = false
+	// the page has arrived, so its context is released from the tab's
+	m.cancelFetch()
+
+	if errors.Is(msg.err, context.Canceled) {
+		return m, nil
+	}
+	if msg.err != nil {
+		return m, commands.Error(fmt.Errorf("error loading log streams: %w", msg.err))
+	}
+	if msg.streams == nil {
+		return m, nil
+	}
 
 	// Get streams into a formatted item list
 	itemList := m.List.Items()
-	itemList = append(itemList, GetLogStreamsAsItemList(streams)...)
+	itemList = append(itemList, GetLogStreamsAsItemList(msg.streams, m.timeFormat)...)
+	cmd := m.List.SetItems(itemList)
 
-	return m.List.SetItems(itemList)
+	if !m.selectFirst {
+		return m, cmd
+	}
+	m.selectFirst = false
+
+	i, ok := m.List.SelectedItem().(Item)
+	if !ok {
+		return m, cmd
+	}
+	m.SelectedStream = i.name
+	return m, tea.Batch(
+		cmd,
+		commands.UpdateEventListItems(m.currentGroup, m.SelectedStream),
+	)
 }
 
-func (m Model) HelpView() string {
-	return m.List.Styles.HelpStyle.Render(m.List.Help.View(m.List))
+// HelpKeys returns the keys of the stream list for the help overlay
+func (m Model) HelpKeys() help.KeyMap {
+	return m.List
 }
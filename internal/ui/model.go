@@ -2,18 +2,29 @@ package ui
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"time"
 
-	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"clviewer/internal/cloudwatch/client"
 	"clviewer/internal/commands"
-	event "clviewer/internal/ui/logevent"
-	"clviewer/internal/ui/logevent/message"
-	"clviewer/internal/ui/logevent/timestamp"
-	group "clviewer/internal/ui/loggroup"
-	stream "clviewer/internal/ui/logstream"
-	"clviewer/internal/ui/pages"
+	"clviewer/internal/layout"
+	"clviewer/internal/locator"
+	"clviewer/internal/paging"
+	"clviewer/internal/session"
+	"clviewer/internal/ui/ansi"
+	"clviewer/internal/ui/clipboard"
+	"clviewer/internal/ui/mouse"
+	"clviewer/internal/ui/palette"
+	"clviewer/internal/ui/prompt"
+	"clviewer/internal/ui/timeformat"
 )
 
 var (
@@ -53,10 +64,37 @@ const (
 	eventPage
 )
 
+// saveInterval is how often the session is saved while the viewer runs, so
+// that little is lost if it's killed before it can save on quit
+const saveInterval = 5 * time.Second
+
 type Model struct {
-	paginator paginator.Model
-	eventPage pages.Event
-	groupPage pages.Group
+	tabs       []tab
+	active     int
+	nextTabID  int
+	ctx        context.Context // of every fetch, cancelled on quit
+	cancel     context.CancelFunc
+	cw         client.Client
+	output     io.Writer // the program's, OSC 52 sequences are written to it
+	timeFormat timeformat.Format
+	paging     paging.Options
+	layout     layout.Layout
+
+	palette   palette.Model
+	prompt    prompt.Model
+	promptTab int // id of the tab the prompt was opened by
+	helpMode  helpMode
+
+	sessionPath string
+	saved       session.State // last state written to sessionPath
+
+	refreshing bool // whether relative timestamps are being refreshed
+
+	lastError error          // shown in the status bar
+	notice    string         // as is the last thing done
+	retries   []client.Retry // as are the calls waiting to be retried
+
+	clicks mouse.Clicks
 
 	Width    int
 	Height   int
@@ -64,66 +102,106 @@ type Model struct {
 	selected int
 }
 
-func New(ctx context.Context, initialGroup string) *Model {
-	logGroup := group.New(
-		"Log Groups",
-		"/aws/lambda",
-		initialGroup,
-	)
-	logStream := stream.New(
-		"Log Streams",
-		initialGroup,
-	)
-
-	initialLogstream := ""
-	// initialize logstream only if logstream has been initalized with items
-	if len(logStream.List.Items()) > 0 {
-		initialLogstream = logStream.List.Items()[0].FilterValue()
-	}
-
-	logEvent := event.New(
-		timestamp.New("Timestamps"),
-		message.New("Log Messages", "..."),
-		initialGroup,
-		initialLogstream,
-	)
+// saveSessionMsg triggers a save of the session
+type saveSessionMsg struct{}
 
-	paginator := paginator.New()
-	paginator.SetTotalPages(2)
+// refreshTimesMsg formats the timestamps again so relative times stay current
+type refreshTimesMsg struct{}
 
+// New creates the ui with a tab open for each tab in state, or a single empty
+// tab if there are none. The session is saved to sessionPath periodically,
+// unless it's empty.
+func New(
+	ctx context.Context,
+	cw client.Client,
+	state session.State,
+	timeFormat timeformat.Format,
+	opts paging.Options,
+	sessionPath string,
+) *Model {
+	ctx, cancel := context.WithCancel(ctx)
 	model := Model{
-		eventPage: pages.Event{
-			LogEvents:  logEvent,
-			LogStreams: logStream,
-			Focused:    0,
-			Width:      0,
-			Height:     0,
-		},
-		groupPage: pages.Group{
-			Model: logGroup,
-		},
-		Width:     0,
-		Height:    0,
-		helpView:  "",
-		selected:  eventListSelected,
-		paginator: paginator,
+		ctx:         ctx,
+		cancel:      cancel,
+		cw:          cw,
+		output:      os.Stdout,
+		Width:       0,
+		Height:      0,
+		helpView:    "",
+		selected:    eventListSelected,
+		timeFormat:  timeFormat,
+		paging:      opts,
+		sessionPath: sessionPath,
+		palette:     palette.New(),
+		prompt:      prompt.New(),
+		layout:      state.Layout.Normalize(),
+	}
+
+	for i, t := range state.Tabs {
+		tabCW, err := tabClient(ctx, cw, t.Locator)
+		if err != nil {
+			// rather than showing another account's or region's events
+			model.lastError = fmt.Errorf("not restoring the tab of %s: %w", t.Locator.Group, err)
+			continue
+		}
+		if i == state.Active {
+			model.active = len(model.tabs)
+		}
+		model.addTab(restoreTab(ctx, model.nextTabID, tabCW, opts, t))
+	}
+	if len(model.tabs) == 0 {
+		model.addTab(restoreTab(ctx, model.nextTabID, cw, opts, session.Tab{}))
 	}
 	return &model
 }
 
+// addTab adds a tab restored by New
+func (m *Model) addTab(t tab) {
+	t, _ = t.Update(commands.SetLayoutMsg{Layout: m.layout})
+	m.tabs = append(m.tabs, t)
+	m.nextTabID++
+}
+
 func (m *Model) Init() tea.Cmd {
-	return nil
+	cmds := []tea.Cmd{
+		commands.SetTimeFormat(m.timeFormat),
+		m.scheduleSave(),
+	}
+	for _, t := range m.tabs {
+		cmds = append(cmds, t.Init())
+	}
+	if l := m.cw.Limiter(); l != nil {
+		cmds = append(cmds, waitForRetries(l))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *Model) View() string {
-	switch m.currentPage() {
-	case groupPage:
-		return m.groupPage.View()
-	case eventPage:
-		return m.eventPage.View()
-	default:
-		return ""
+	// keep the status bar at the bottom of the window
+	size := m.tabSize()
+	tabView := lipgloss.NewStyle().
+		Height(size.Height).
+		MaxHeight(size.Height).
+		MaxWidth(size.Width).
+		Render(m.tabs[m.active].View())
+
+	view := lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.tabBarView(),
+		tabView,
+		m.statusBarView(),
+	)
+
+	x := (m.Width - overlayWidth(m.Width)) / 2
+	switch {
+	case m.prompt.IsOpen():
+		view = ansi.Overlay(view, m.prompt.View(), x, tabBarHeight+1)
+	case m.palette.IsOpen():
+		view = ansi.Overlay(view, m.palette.View(), x, tabBarHeight+1)
+	case m.helpMode != helpClosed:
+		view = ansi.Overlay(view, m.helpOverlay(), x, tabBarHeight+1)
 	}
+	return view
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -131,66 +209,313 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "h", "l":
-			m.paginator, cmd = m.paginator.Update(msg)
+		if msg.String() == "ctrl+c" {
+			return m.quit()
+		}
+		if m.prompt.IsOpen() {
+			m.prompt, cmd = m.prompt.Update(msg)
+			return m, cmd
+		}
+		if m.palette.IsOpen() {
+			m.palette, cmd = m.palette.Update(msg)
+			return m, cmd
+		}
+		if m.tabs[m.active].settingFilter() {
+			return m.updateTab(m.active, msg)
+		}
+		if m.helpMode != helpClosed && msg.String() == "esc" {
+			m.helpMode = helpClosed
+			return m, nil
+		}
+
+		switch {
+		case key.Matches(msg, keys.Help):
+			m.helpMode = (m.helpMode + 1) % numHelpModes
+			return m, nil
+		case key.Matches(msg, keys.Quit):
+			return m.quit()
+		case key.Matches(msg, keys.Cancel):
+			return m.updateTab(m.active, commands.CancelMsg{})
+		case key.Matches(msg, keys.Palette):
+			m.palette, cmd = m.palette.Open()
 			return m, cmd
+		case key.Matches(msg, keys.NewTab):
+			return m.openTab(locator.Locator{})
+		case key.Matches(msg, keys.CloseTab):
+			return m.closeTab(m.active)
+		case key.Matches(msg, keys.NextTab):
+			return m.switchTab(1), nil
+		case key.Matches(msg, keys.PrevTab):
+			return m.switchTab(-1), nil
 		default:
-			return m.updateCurrentPage(msg)
+			return m.updateTab(m.active, msg)
 		}
 	case tea.WindowSizeMsg:
 		m.Width = msg.Width
 		m.Height = msg.Height
+		m.palette = m.palette.SetWidth(overlayWidth(m.Width))
+		m.prompt = m.prompt.SetWidth(overlayWidth(m.Width))
 		return m.updateWindowSizes()
 	case commands.RedrawWindowsMsg:
 		return m.updateWindowSizes()
-	case commands.UpdateViewPortContentMsg:
-		return m.updateCurrentPage(msg)
+	case commands.SetTimeFormatMsg:
+		// the time format is shared by every tab
+		m.timeFormat = msg.Format
+		m, cmd := m.updateTabs(msg)
+		return m, tea.Batch(cmd, m.scheduleRefresh())
+	case refreshTimesMsg:
+		m.refreshing = false
+		if m.timeFormat.RefreshInterval() == 0 {
+			return m, nil
+		}
+		return m.Update(commands.SetTimeFormatMsg{Format: m.timeFormat})
+	case commands.SetLayoutMsg:
+		// as is the layout
+		m.layout = msg.Layout
+		return m.updateTabs(msg)
+	case tea.MouseMsg:
+		if m.palette.IsOpen() || m.prompt.IsOpen() || m.helpMode != helpClosed {
+			return m, nil
+		}
+		return m.handleMouse(m.clicks.Msg(msg, time.Now()))
+	case commands.ErrorMsg:
+		log.Printf("%s", msg.Err)
+		m.lastError = msg.Err
+		m.notice = ""
+		return m, nil
+	case commands.NoticeMsg:
+		log.Printf("%s", msg.Text)
+		m.notice = msg.Text
+		m.lastError = nil
+		return m, nil
+	case retriesMsg:
+		m.retries = msg.retries
+		return m, waitForRetries(msg.limiter)
+	case clipboard.OSC52Msg:
+		return m, clipboard.Print(m.output, msg)
+	case saveSessionMsg:
+		return m, tea.Batch(m.saveSession(), m.scheduleSave())
+	case prompt.SubmitMsg:
+		// acted on by the tab that asked
+		return m.Update(tabMsg{id: m.promptTab, msg: msg.Msg})
+	case palette.RunMsg:
+		if action, ok := msg.Msg.(action); ok {
+			return action(m)
+		}
+		return m.updateTab(m.active, msg.Msg)
+	case clientMsg:
+		if msg.err != nil {
+			return m.Update(commands.ErrorMsg{
+				Err: fmt.Errorf("error creating client: %w", msg.err),
+			})
+		}
+		m.cw = msg.cw
+		return m.openTab(locator.Locator{Group: m.tabs[m.active].Locator().Group})
+	case tabMsg:
+		switch tabMsg := msg.msg.(type) {
+		case commands.RedrawWindowsMsg, commands.SetTimeFormatMsg, commands.SetLayoutMsg,
+			commands.ErrorMsg, commands.NoticeMsg, clipboard.OSC52Msg:
+			return m.Update(msg.msg)
+		case prompt.OpenMsg:
+			m.promptTab = msg.id
+			m.prompt, cmd = m.prompt.Open(tabMsg.Prompt)
+			return m, cmd
+		}
+		for i := range m.tabs {
+			if m.tabs[i].id == msg.id {
+				return m.updateTab(i, msg.msg)
+			}
+		}
+		// the tab was closed
+		return m, nil
 	default:
-		return m.updatePages(msg)
+		// e.g. the cursor blinking in the palette's input
+		if m.palette.IsOpen() {
+			m.palette, cmd = m.palette.Update(msg)
+		}
+		if m.prompt.IsOpen() {
+			var promptCmd tea.Cmd
+			m.prompt, promptCmd = m.prompt.Update(msg)
+			cmd = tea.Batch(cmd, promptCmd)
+		}
+		m, tabsCmd := m.updateTabs(msg)
+		return m, tea.Batch(cmd, tabsCmd)
 	}
 }
 
-func (m Model) currentPage() int {
-	return m.paginator.Page
+// quit cancels every fetch so nothing is left running as the viewer exits
+func (m *Model) quit() (*Model, tea.Cmd) {
+	m.cancel()
+	return m, tea.Quit
+}
+
+// switchTab moves to the tab offset places from the current tab
+func (m *Model) switchTab(offset int) *Model {
+	m.active = (m.active + offset + len(m.tabs)) % len(m.tabs)
+	return m
+}
+
+// overlayWidth returns the width of the command palette and help overlay in
+// a window width columns wide
+func overlayWidth(width int) int {
+	const maxWidth = 90
+	if width-4 < maxWidth {
+		return width - 4
+	}
+	return maxWidth
 }
 
-func (m *Model) updateCurrentPage(msg tea.Msg) (*Model, tea.Cmd) {
+// State returns the state of every tab to save in the session
+func (m *Model) State() session.State {
+	state := session.State{Active: m.active, Layout: m.layout}
+	for _, t := range m.tabs {
+		state.Tabs = append(state.Tabs, t.State())
+	}
+	return state
+}
+
+func (m *Model) scheduleSave() tea.Cmd {
+	if m.sessionPath == "" {
+		return nil
+	}
+	return tea.Tick(saveInterval, func(time.Time) tea.Msg {
+		return saveSessionMsg{}
+	})
+}
+
+// scheduleRefresh refreshes the timestamps once the time format's refresh
+// interval passes, unless a refresh is already scheduled
+func (m *Model) scheduleRefresh() tea.Cmd {
+	interval := m.timeFormat.RefreshInterval()
+	if interval == 0 || m.refreshing {
+		return nil
+	}
+	m.refreshing = true
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return refreshTimesMsg{}
+	})
+}
+
+// saveSession writes the session in the background if it has changed since
+// it was last saved
+func (m *Model) saveSession() tea.Cmd {
+	state := m.State()
+	if reflect.DeepEqual(state, m.saved) {
+		return nil
+	}
+	m.saved = state
+
+	path := m.sessionPath
+	return func() tea.Msg {
+		if err := session.Save(path, state); err != nil {
+			return commands.ErrorMsg{Err: fmt.Errorf("error saving session: %w", err)}
+		}
+		return nil
+	}
+}
+
+// openTab opens a new tab at initial and switches to it
+func (m *Model) openTab(initial locator.Locator) (*Model, tea.Cmd) {
+	t := newTab(m.ctx, m.nextTabID, m.cw, m.paging, initial)
+	m.nextTabID++
+
 	var cmd tea.Cmd
-	var cmds []tea.Cmd
-	switch m.currentPage() {
-	case groupPage:
-		m.groupPage, cmd = m.groupPage.Update(msg)
-		return m, cmd
-	case eventPage:
-		m.eventPage, cmd = m.eventPage.Update(msg)
-		return m, cmd
+	t, _ = t.Update(commands.SetLayoutMsg{Layout: m.layout})
+	t, cmd = t.Update(m.tabSize())
+
+	m.tabs = append(m.tabs, t)
+	m.active = len(m.tabs) - 1
+
+	return m, tea.Batch(
+		t.wrap(cmd),
+		t.Init(),
+		commands.SetTimeFormat(m.timeFormat),
+	)
+}
+
+// closeTab closes the tab at index, the last tab can't be closed
+func (m *Model) closeTab(index int) (*Model, tea.Cmd) {
+	if len(m.tabs) == 1 {
+		return m, nil
 	}
 
-	return m, tea.Batch(cmds...)
+	m.tabs[index].cancel()
+	m.tabs = append(m.tabs[:index], m.tabs[index+1:]...)
+	if m.active >= len(m.tabs) {
+		m.active = len(m.tabs) - 1
+	}
+	return m, nil
 }
 
-func (m *Model) updatePages(msg tea.Msg) (*Model, tea.Cmd) {
+func (m *Model) tabBarView() string {
+	var titles []string
+	for i := range m.tabs {
+		style := tabStyle
+		if i == m.active {
+			style = activeTabStyle
+		}
+		titles = append(titles, style.Render(m.tabTitle(i)))
+	}
+
+	return lipgloss.NewStyle().
+		MaxWidth(m.Width).
+		Render(lipgloss.JoinHorizontal(lipgloss.Top, titles...))
+}
+
+// tabTitle is the title of the tab at index in the tab bar
+func (m *Model) tabTitle(index int) string {
+	return fmt.Sprintf("%d %s", index+1, m.tabs[index].title())
+}
+
+// handleMouse switches to the tab clicked on in the tab bar, otherwise msg is
+// passed on to the active tab, which is positioned below the tab bar
+func (m *Model) handleMouse(msg mouse.Msg) (*Model, tea.Cmd) {
+	if msg.Y >= tabBarHeight {
+		return m.updateTab(m.active, msg.Translate(0, tabBarHeight))
+	}
+	if !msg.Click() {
+		return m, nil
+	}
+
+	x := 0
+	for i := range m.tabs {
+		// the active tab's style is the same width as the others
+		x += lipgloss.Width(tabStyle.Render(m.tabTitle(i)))
+		if msg.X < x {
+			m.active = i
+			break
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) updateTab(index int, msg tea.Msg) (*Model, tea.Cmd) {
 	var cmd tea.Cmd
-	var cmds []tea.Cmd
+	m.tabs[index], cmd = m.tabs[index].Update(msg)
+	return m, m.tabs[index].wrap(cmd)
+}
 
-	m.groupPage, cmd = m.groupPage.Update(msg)
-	cmds = append(cmds, cmd)
+func (m *Model) updateTabs(msg tea.Msg) (*Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
 
-	m.eventPage, cmd = m.eventPage.Update(msg)
-	cmds = append(cmds, cmd)
+	for i := range m.tabs {
+		m, cmd = m.updateTab(i, msg)
+		cmds = append(cmds, cmd)
+	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// tabSize is the size of the window left for a tab between the tab bar and
+// the status bar
+func (m *Model) tabSize() tea.WindowSizeMsg {
+	return tea.WindowSizeMsg{
+		Width:  m.Width,
+		Height: m.Height - tabBarHeight - statusBarHeight,
+	}
+}
+
 func (m *Model) updateWindowSizes() (*Model, tea.Cmd) {
-	m, cmd := m.updatePages(
-		tea.WindowSizeMsg{
-			Width:  m.Width,
-			Height: m.Height,
-		},
-	)
-	return m, cmd
+	return m.updateTabs(m.tabSize())
 }
@@ -0,0 +1,106 @@
+package mouse
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// doubleClickTime is the longest time between the clicks of a double click
+const doubleClickTime = 400 * time.Millisecond
+
+// WheelLines is how many lines the wheel scrolls the message viewport by
+const WheelLines = 3
+
+// Msg is a mouse event, its position is relative to the top left of the
+// model it's sent to. Models pass it on to the model under the mouse with
+// Translate.
+type Msg struct {
+	tea.MouseMsg
+	// Double is set on the second left click of a double click
+	Double bool
+}
+
+// Translate returns msg relative to a model drawn at column x of row y
+func (m Msg) Translate(x, y int) Msg {
+	m.X -= x
+	m.Y -= y
+	return m
+}
+
+// Click returns true if msg is a left click. Dragging is reported as left
+// clicks at each position the mouse moves to.
+func (m Msg) Click() bool {
+	return m.Type == tea.MouseLeft
+}
+
+// Within returns true if msg is inside a model width columns wide and height
+// rows tall
+func (m Msg) Within(width, height int) bool {
+	return m.X >= 0 && m.X < width && m.Y >= 0 && m.Y < height
+}
+
+// Clicks finds double clicks, two left clicks at the same place with the
+// button released in between
+type Clicks struct {
+	last     tea.MouseMsg
+	at       time.Time
+	released bool
+}
+
+// Msg converts a mouse event from the program into a Msg
+func (c *Clicks) Msg(msg tea.MouseMsg, now time.Time) Msg {
+	switch msg.Type {
+	case tea.MouseRelease:
+		c.released = true
+	case tea.MouseLeft:
+		double := c.released &&
+			msg.X == c.last.X && msg.Y == c.last.Y &&
+			now.Sub(c.at) < doubleClickTime
+
+		c.last, c.at, c.released = msg, now, false
+		if double {
+			// a third click starts a new double click
+			c.at = time.Time{}
+		}
+		return Msg{MouseMsg: msg, Double: double}
+	}
+	return Msg{MouseMsg: msg}
+}
+
+// ListIndex returns the index among the visible items of l of the item at row
+// y of the list. itemHeight is the height of an item including its spacing.
+func ListIndex(l list.Model, y, itemHeight int) (int, bool) {
+	top := 0
+	if l.ShowTitle() || (l.ShowFilter() && l.FilteringEnabled()) {
+		top += lipgloss.Height(l.Styles.TitleBar.Render(l.Title))
+	}
+	if l.ShowStatusBar() {
+		top += lipgloss.Height(l.Styles.StatusBar.Render(""))
+	}
+	if y < top || itemHeight <= 0 {
+		return 0, false
+	}
+
+	row := (y - top) / itemHeight
+	if row >= l.Paginator.PerPage {
+		return 0, false
+	}
+	index := l.Paginator.Page*l.Paginator.PerPage + row
+	if index >= len(l.VisibleItems()) {
+		return 0, false
+	}
+	return index, true
+}
+
+// ScrollList moves the cursor of l with the wheel
+func ScrollList(l *list.Model, msg Msg) {
+	switch msg.Type {
+	case tea.MouseWheelUp:
+		l.CursorUp()
+	case tea.MouseWheelDown:
+		l.CursorDown()
+	}
+}
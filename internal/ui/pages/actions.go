@@ -0,0 +1,35 @@
+package pages
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"clviewer/internal/ui/palette"
+)
+
+// runKeyMsg runs the layout action bound to a key when it's chosen from the
+// command palette
+type runKeyMsg tea.KeyMsg
+
+func init() {
+	palette.Register(
+		keyAction("zoom", "show only the focused pane", keys.Zoom),
+		keyAction("widen stream list", "give the stream list more of the page", keys.GrowStreams),
+		keyAction("narrow stream list", "give the stream list less of the page", keys.ShrinkStreams),
+		keyAction("widen timestamps", "give the timestamps more of the event pane", keys.GrowTimestamps),
+		keyAction("narrow timestamps", "give the timestamps less of the event pane", keys.ShrinkTimestamps),
+		keyAction("toggle stream list", "show or hide the stream list", keys.HideStreams),
+		keyAction("toggle timestamps", "show or hide the timestamp column", keys.HideTimestamps),
+	)
+}
+
+func keyAction(name, help string, binding key.Binding) palette.Action {
+	return palette.Action{
+		Name: name,
+		Help: help,
+		Key:  binding,
+		Msg: func(string) tea.Msg {
+			return runKeyMsg(palette.KeyMsg(binding))
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package pages
+
+import "github.com/charmbracelet/bubbles/key"
+
+type keyMap struct {
+	NextPane key.Binding
+	PrevPane key.Binding
+
+	GrowStreams      key.Binding
+	ShrinkStreams    key.Binding
+	GrowTimestamps   key.Binding
+	ShrinkTimestamps key.Binding
+	HideStreams      key.Binding
+	HideTimestamps   key.Binding
+	Zoom             key.Binding
+}
+
+var keys = keyMap{
+	NextPane: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "next pane"),
+	),
+	PrevPane: key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("shift+tab", "prev pane"),
+	),
+	GrowStreams: key.NewBinding(
+		key.WithKeys("=", "+"),
+		key.WithHelp("+", "widen stream list"),
+	),
+	ShrinkStreams: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "narrow stream list"),
+	),
+	GrowTimestamps: key.NewBinding(
+		key.WithKeys("}"),
+		key.WithHelp("}", "widen timestamps"),
+	),
+	ShrinkTimestamps: key.NewBinding(
+		key.WithKeys("{"),
+		key.WithHelp("{", "narrow timestamps"),
+	),
+	HideStreams: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "show/hide stream list"),
+	),
+	HideTimestamps: key.NewBinding(
+		key.WithKeys("H"),
+		key.WithHelp("H", "show/hide timestamps"),
+	),
+	Zoom: key.NewBinding(
+		key.WithKeys("Z"),
+		key.WithHelp("Z", "zoom focused pane"),
+	),
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.NextPane, k.Zoom}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.NextPane, k.PrevPane, k.Zoom},
+		{k.GrowStreams, k.ShrinkStreams, k.HideStreams},
+		{k.GrowTimestamps, k.ShrinkTimestamps, k.HideTimestamps},
+	}
+}
@@ -3,12 +3,16 @@ package pages
 import (
 	"math"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"clviewer/internal/commands"
+	"clviewer/internal/layout"
 	"clviewer/internal/ui/logevent"
 	"clviewer/internal/ui/logstream"
+	"clviewer/internal/ui/mouse"
 )
 
 const (
@@ -20,6 +24,16 @@ const (
 	numWindows = 2
 )
 
+// borderSize is the width of the border either side of a pane
+var borderSize = modelStyle.GetHorizontalBorderSize() / 2
+
+// splits that can be dragged with the mouse
+const (
+	noSplit = iota
+	streamsSplit
+	timestampsSplit
+)
+
 var (
 	modelStyle = lipgloss.NewStyle().
 			BorderStyle(lipgloss.RoundedBorder()).
@@ -51,25 +65,52 @@ type Event struct {
 	Focused    int
 	Width      int
 	Height     int
+	Layout     layout.Layout
+	dragging   int // the split being dragged
+}
+
+// size is the outer size of a pane including its border, zero if the pane
+// isn't shown
+type size struct {
+	width  int
+	height int
+}
+
+func (e Event) Init() tea.Cmd {
+	return tea.Batch(e.LogStreams.Init(), e.LogEvents.Init())
 }
 
-func (m Event) Init() tea.Cmd {
-	return nil
+// SettingFilter returns true if the focused list is being filtered, so
+// keys should be typed into the filter rather than handled as shortcuts
+func (e Event) SettingFilter() bool {
+	switch e.Focused {
+	case logStreamsSelected:
+		return e.LogStreams.List.SettingFilter()
+	case logEventsSelected:
+		return e.LogEvents.Timestamp.List.SettingFilter()
+	}
+	return false
 }
 
 func (e Event) Update(msg tea.Msg) (Event, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "tab":
-			e = e.focusNext()
-			return e, nil
-		case "shift+tab":
-			e = e.focusPrevious()
-			return e, nil
-		default:
+		if e.SettingFilter() {
 			return e.updateKeyMsg(msg)
 		}
+		return e.handleKey(msg)
+	case runKeyMsg:
+		return e.handleKey(tea.KeyMsg(msg))
+	case mouse.Msg:
+		return e.handleMouse(msg)
+	case commands.SetLayoutMsg:
+		e.Layout = msg.Layout
+		if e.Layout.HideStreams && !e.Layout.Zoom {
+			e.Focused = logEventsSelected
+		}
+		// the event pane lays out its columns when it's resized
+		e.LogEvents, _ = e.LogEvents.Update(msg)
+		return e.updateWindowSizes()
 	case tea.WindowSizeMsg:
 		e.Width = msg.Width
 		e.Height = msg.Height
@@ -83,24 +124,194 @@ func (e Event) Update(msg tea.Msg) (Event, tea.Cmd) {
 	return e.updateSubModels(msg)
 }
 
+// Help returns the name of the focused pane and the keys to show in the help
+// overlay
+func (e Event) Help() (string, []help.KeyMap) {
+	if e.Focused == logStreamsSelected {
+		return "log streams", []help.KeyMap{keys, e.LogStreams.HelpKeys()}
+	}
+	return "log events", []help.KeyMap{keys, e.LogEvents.HelpKeys()}
+}
+
 func (e Event) View() string {
-	logStreamList := e.LogStreams.View()
-	logEventView := e.LogEvents.View()
+	streams, events := e.panes()
 
-	switch e.Focused {
-	case logStreamsSelected:
-		logStreamList = selectedModelStyle.Render(logStreamList)
-		logEventView = modelStyle.Render(logEventView)
-	case logEventsSelected:
-		logEventView = selectedModelStyle.Render(logEventView)
-		logStreamList = modelStyle.Render(logStreamList)
+	var views []string
+	if streams.width > 0 {
+		views = append(views, e.style(logStreamsSelected).Render(e.LogStreams.View()))
+	}
+	if events.width > 0 {
+		views = append(views, e.style(logEventsSelected).Render(e.LogEvents.View()))
+	}
+
+	if layout.Stacked(e.Width) {
+		return lipgloss.JoinVertical(lipgloss.Left, views...)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, views...)
+}
+
+// style returns the border style of a pane, the focused pane is highlighted
+func (e Event) style(pane int) lipgloss.Style {
+	if e.Focused == pane {
+		return selectedModelStyle
+	}
+	return modelStyle
+}
+
+// panes returns the size of the stream and event panes
+func (e Event) panes() (streams size, events size) {
+	page := size{width: e.Width, height: e.Height}
+
+	switch {
+	case e.Layout.Zoom && e.Focused == logStreamsSelected:
+		return page, size{}
+	case e.Layout.Zoom, e.Layout.HideStreams:
+		return size{}, page
+	case layout.Stacked(e.Width):
+		top, bottom := layout.Split(e.Height, e.Layout.Streams)
+		return size{width: e.Width, height: top}, size{width: e.Width, height: bottom}
+	default:
+		left, right := layout.Split(e.Width, e.Layout.Streams)
+		return size{width: left, height: e.Height}, size{width: right, height: e.Height}
+	}
+}
+
+func (e Event) handleKey(msg tea.KeyMsg) (Event, tea.Cmd) {
+	l := e.Layout
+	switch {
+	case key.Matches(msg, keys.NextPane):
+		return e.focusNext(), nil
+	case key.Matches(msg, keys.PrevPane):
+		return e.focusPrevious(), nil
+	case key.Matches(msg, keys.GrowStreams):
+		l = l.ResizeStreams(1)
+	case key.Matches(msg, keys.ShrinkStreams):
+		l = l.ResizeStreams(-1)
+	case key.Matches(msg, keys.GrowTimestamps):
+		l = l.ResizeTimestamps(1)
+	case key.Matches(msg, keys.ShrinkTimestamps):
+		l = l.ResizeTimestamps(-1)
+	case key.Matches(msg, keys.HideStreams):
+		l.HideStreams = !l.HideStreams
+	case key.Matches(msg, keys.HideTimestamps):
+		l.HideTimestamps = !l.HideTimestamps
+	case key.Matches(msg, keys.Zoom):
+		l.Zoom = !l.Zoom
+	default:
+		return e.updateKeyMsg(msg)
+	}
+	// the layout is shared by every tab
+	return e, commands.SetLayout(l)
+}
+
+// handleMouse drags the splits between panes, other clicks focus the pane
+// under the mouse and are passed on to it. A drag is reported as left presses
+// at each position the mouse moves to, then a release.
+func (e Event) handleMouse(msg mouse.Msg) (Event, tea.Cmd) {
+	switch msg.Type {
+	case tea.MouseRelease:
+		e.dragging = noSplit
+		return e, nil
+	case tea.MouseLeft:
+		if e.dragging != noSplit {
+			return e.drag(msg)
+		}
+		if e.dragging = e.splitAt(msg.X, msg.Y); e.dragging != noSplit {
+			return e, nil
+		}
 	}
+	return e.handlePaneMouse(msg)
+}
+
+// handlePaneMouse passes msg on to the pane under the mouse, relative to the
+// inside of its border. Clicks focus the pane, the wheel scrolls it without
+// moving focus.
+func (e Event) handlePaneMouse(msg mouse.Msg) (Event, tea.Cmd) {
+	var cmd tea.Cmd
+
+	streams, events := e.panes()
+	if streams.width > 0 && msg.Within(streams.width, streams.height) {
+		if msg.Click() {
+			e.Focused = logStreamsSelected
+		}
+		e.LogStreams, cmd = e.LogStreams.Update(msg.Translate(borderSize, borderSize))
+		return e, cmd
+	}
+
+	msg = msg.Translate(e.eventsX(streams), e.eventsY(streams))
+	if events.width > 0 && msg.Within(events.width, events.height) {
+		if msg.Click() {
+			e.Focused = logEventsSelected
+		}
+		e.LogEvents, cmd = e.LogEvents.Update(msg.Translate(borderSize, borderSize))
+		return e, cmd
+	}
+	return e, nil
+}
+
+// drag moves the split being dragged to the mouse
+func (e Event) drag(msg mouse.Msg) (Event, tea.Cmd) {
+	streams, events := e.panes()
+	l := e.Layout
+	switch e.dragging {
+	case streamsSplit:
+		if layout.Stacked(e.Width) {
+			l.Streams = layout.Ratio(msg.Y+1, e.Height)
+		} else {
+			l.Streams = layout.Ratio(msg.X+1, e.Width)
+		}
+	case timestampsSplit:
+		x := msg.X - e.eventsX(streams) - borderSize
+		l.Timestamps = layout.Ratio(x, events.width-modelStyle.GetHorizontalFrameSize())
+	}
+	if l == e.Layout {
+		return e, nil
+	}
+	return e, commands.SetLayout(l)
+}
+
+// splitAt returns the split at column x of row y of the page, the borders
+// either side of a split can be dragged
+func (e Event) splitAt(x, y int) int {
+	streams, events := e.panes()
+	if streams.width > 0 && events.width > 0 {
+		if layout.Stacked(e.Width) && (y == streams.height-1 || y == streams.height) {
+			return streamsSplit
+		}
+		if !layout.Stacked(e.Width) && (x == streams.width-1 || x == streams.width) {
+			return streamsSplit
+		}
+	}
+
+	if events.width == 0 || e.Layout.HideTimestamps {
+		return noSplit
+	}
+	eventsY := e.eventsY(streams)
+	if y <= eventsY || y >= eventsY+events.height-1 {
+		return noSplit
+	}
+	timestamps, _ := layout.Split(events.width-modelStyle.GetHorizontalFrameSize(), e.Layout.Timestamps)
+	split := e.eventsX(streams) + borderSize + timestamps
+	if x == split-1 || x == split {
+		return timestampsSplit
+	}
+	return noSplit
+}
+
+// eventsX is the column the event pane starts at
+func (e Event) eventsX(streams size) int {
+	if layout.Stacked(e.Width) {
+		return 0
+	}
+	return streams.width
+}
 
-	return lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		logStreamList,
-		logEventView,
-	)
+// eventsY is the row the event pane starts at
+func (e Event) eventsY(streams size) int {
+	if layout.Stacked(e.Width) && streams.width > 0 {
+		return streams.height
+	}
+	return 0
 }
 
 func (e Event) updateKeyMsg(msg tea.Msg) (Event, tea.Cmd) {
@@ -119,24 +330,27 @@ func (e Event) updateWindowSizes() (Event, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
-	const borderMarginSize = 4 // subtract 4 for border
-	const tuiBorder = 1
-
-	height := e.Height - tuiBorder
-	width := e.Width - tuiBorder
-
-	logStreamListWidth := int(float64(width) / 3.0)
-	e.LogStreams, cmd = e.LogStreams.Update(tea.WindowSizeMsg{
-		Width:  logStreamListWidth,
-		Height: height - borderMarginSize/2,
-	})
-	cmds = append(cmds, cmd)
+	// both panes have a border, the selected one is a different colour
+	frameWidth := modelStyle.GetHorizontalFrameSize()
+	frameHeight := modelStyle.GetVerticalFrameSize()
+	streams, events := e.panes()
+
+	// hidden panes keep their size so they're ready to be shown again
+	if streams.width > 0 {
+		e.LogStreams, cmd = e.LogStreams.Update(tea.WindowSizeMsg{
+			Width:  streams.width - frameWidth,
+			Height: streams.height - frameHeight,
+		})
+		cmds = append(cmds, cmd)
+	}
 
-	e.LogEvents, cmd = e.LogEvents.Update(tea.WindowSizeMsg{
-		Width:  width - logStreamListWidth - borderMarginSize,
-		Height: height - borderMarginSize,
-	})
-	cmds = append(cmds, cmd)
+	if events.width > 0 {
+		e.LogEvents, cmd = e.LogEvents.Update(tea.WindowSizeMsg{
+			Width:  events.width - frameWidth,
+			Height: events.height - frameHeight,
+		})
+		cmds = append(cmds, cmd)
+	}
 
 	return e, tea.Batch(cmds...)
 }
@@ -155,11 +369,27 @@ func (e Event) updateSubModels(msg tea.Msg) (Event, tea.Cmd) {
 }
 
 func (e Event) focusNext() Event {
+	// the stream list can't be focused while it's hidden
+	if e.Layout.HideStreams && !e.Layout.Zoom {
+		return e
+	}
 	e.Focused = (e.Focused + 1) % numWindows
-	return e
+	return e.updateZoom()
 }
 
 func (e Event) focusPrevious() Event {
+	if e.Layout.HideStreams && !e.Layout.Zoom {
+		return e
+	}
 	e.Focused = int(math.Abs(float64((e.Focused - 1) % numWindows)))
+	return e.updateZoom()
+}
+
+// updateZoom resizes the panes when focus moves while zoomed, so the newly
+// focused pane fills the page
+func (e Event) updateZoom() Event {
+	if e.Layout.Zoom {
+		e, _ = e.updateWindowSizes()
+	}
 	return e
 }
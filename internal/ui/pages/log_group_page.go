@@ -3,6 +3,7 @@ package pages
 import (
 	group "clviewer/internal/ui/loggroup"
 
+	"github.com/charmbracelet/bubbles/help"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -14,12 +15,22 @@ func (g Group) Init() tea.Cmd {
 	return g.Model.Init()
 }
 
+// SettingFilter returns true if the group list is being filtered
+func (g Group) SettingFilter() bool {
+	return g.Model.List.SettingFilter()
+}
+
 func (g Group) Update(msg tea.Msg) (Group, tea.Cmd) {
 	var cmd tea.Cmd
 	g.Model, cmd = g.Model.Update(msg)
 	return g, cmd
 }
 
+// Help returns the name of the page and the keys to show in the help overlay
+func (g Group) Help() (string, []help.KeyMap) {
+	return "log groups", []help.KeyMap{g.Model.HelpKeys()}
+}
+
 func (g Group) View() string {
 	return g.Model.View()
 }
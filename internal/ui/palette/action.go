@@ -0,0 +1,69 @@
+package palette
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Action is something that can be run from the command palette
+type Action struct {
+	Name string
+	Help string
+	// Key is the binding that also runs the action, shown next to it in the
+	// palette
+	Key key.Binding
+	// Args describes the arguments typed after the name, <required> or
+	// [optional], empty if the action takes none
+	Args string
+	// Msg returns the message that runs the action with the typed arguments,
+	// it's delivered to the current tab
+	Msg func(args string) tea.Msg
+}
+
+// requiresArgs returns true if the action can't run without arguments
+func (a Action) requiresArgs() bool {
+	return strings.HasPrefix(a.Args, "<")
+}
+
+// registry holds every action, packages add theirs with Register
+var registry []Action
+
+// Register adds actions to the command palette
+func Register(actions ...Action) {
+	registry = append(registry, actions...)
+}
+
+// Actions returns every registered action sorted by name
+func Actions() []Action {
+	actions := append([]Action(nil), registry...)
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].Name < actions[j].Name
+	})
+	return actions
+}
+
+// KeyMsg returns the key press of the first key of binding, so actions that
+// have a key can be run by the same code as the key press
+func KeyMsg(binding key.Binding) tea.KeyMsg {
+	keys := binding.Keys()
+	if len(keys) == 0 {
+		return tea.KeyMsg{}
+	}
+
+	name := keys[0]
+	alt := strings.HasPrefix(name, "alt+")
+	if alt {
+		name = strings.TrimPrefix(name, "alt+")
+	}
+
+	// KeyType covers the control characters and the negative special keys
+	for t := tea.KeyType(-128); t < 128; t++ {
+		if t != tea.KeyRunes && t.String() == name {
+			return tea.KeyMsg{Type: t, Alt: alt}
+		}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name), Alt: alt}
+}
@@ -0,0 +1,263 @@
+package palette
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// maxRows is the number of actions shown below the input
+const maxRows = 10
+
+var (
+	boxStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("69")).
+			PaddingLeft(1).
+			PaddingRight(1)
+
+	selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
+	argsStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("98"))
+	helpStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	keyStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("69"))
+)
+
+// RunMsg is sent when an action is chosen, Msg is the message that runs it
+type RunMsg struct {
+	Msg tea.Msg
+}
+
+// Model is the command palette, a fuzzy searchable list of every registered
+// action
+type Model struct {
+	input    textinput.Model
+	matches  []Action
+	selected int
+	open     bool
+	width    int
+}
+
+func New() Model {
+	input := textinput.New()
+	input.Prompt = ": "
+	input.Placeholder = "search actions"
+
+	return Model{
+		input: input,
+	}
+}
+
+// Open shows the palette with an empty search
+func (m Model) Open() (Model, tea.Cmd) {
+	m.open = true
+	m.selected = 0
+	m.input.Reset()
+	m.filter()
+	return m, m.input.Focus()
+}
+
+func (m Model) Close() Model {
+	m.open = false
+	m.input.Blur()
+	return m
+}
+
+func (m Model) IsOpen() bool {
+	return m.open
+}
+
+// SetWidth sets the width of the palette including its border
+func (m Model) SetWidth(width int) Model {
+	m.width = width
+	m.input.Width = width - 8
+	return m
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			return m.Close(), nil
+		case "up", "ctrl+p":
+			if m.selected > 0 {
+				m.selected--
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.selected < len(m.matches)-1 {
+				m.selected++
+			}
+			return m, nil
+		case "tab":
+			if len(m.matches) > 0 {
+				m.complete(m.matches[m.selected])
+			}
+			return m, nil
+		case "enter":
+			return m.run()
+		}
+	}
+
+	m.input, cmd = m.input.Update(msg)
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.filter()
+	}
+	return m, cmd
+}
+
+func (m Model) View() string {
+	rows := []string{m.input.View(), ""}
+
+	// keep the selected action in view
+	start := 0
+	if m.selected >= maxRows {
+		start = m.selected - maxRows + 1
+	}
+	end := start + maxRows
+	if end > len(m.matches) {
+		end = len(m.matches)
+	}
+
+	width := m.width - 4 // border and padding
+	for i := start; i < end; i++ {
+		rows = append(rows, m.rowView(m.matches[i], i == m.selected, width))
+	}
+	if len(m.matches) == 0 {
+		rows = append(rows, helpStyle.Render("no matching actions"))
+	}
+
+	return boxStyle.Width(m.width - 2).Render(strings.Join(rows, "\n"))
+}
+
+func (m Model) rowView(a Action, selected bool, width int) string {
+	name := a.Name
+	cursor := "  "
+	if selected {
+		name = selectedStyle.Render(name)
+		cursor = selectedStyle.Render("> ")
+	}
+	if a.Args != "" {
+		name += " " + argsStyle.Render(a.Args)
+	}
+
+	var binding string
+	if a.Key.Enabled() {
+		binding = keyStyle.Render(a.Key.Help().Key)
+	}
+
+	left := cursor + name + "  "
+	help := truncate(a.Help, width-lipgloss.Width(left)-lipgloss.Width(binding)-1)
+
+	padding := width - lipgloss.Width(left) - len(help) - lipgloss.Width(binding)
+	if padding < 1 {
+		padding = 1
+	}
+	return left + helpStyle.Render(help) + strings.Repeat(" ", padding) + binding
+}
+
+// filter updates the matching actions from the search. Once the full name of
+// an action that takes arguments has been typed, only it matches.
+func (m *Model) filter() {
+	defer func() {
+		if m.selected >= len(m.matches) {
+			m.selected = len(m.matches) - 1
+		}
+		if m.selected < 0 {
+			m.selected = 0
+		}
+	}()
+
+	query := m.input.Value()
+	if a, _, ok := withArgs(query); ok {
+		m.matches = []Action{a}
+		return
+	}
+
+	actions := Actions()
+	if strings.TrimSpace(query) == "" {
+		m.matches = actions
+		return
+	}
+
+	names := make([]string, len(actions))
+	for i, a := range actions {
+		names[i] = a.Name
+	}
+
+	m.matches = nil
+	for _, match := range fuzzy.Find(query, names) {
+		m.matches = append(m.matches, actions[match.Index])
+	}
+}
+
+// complete fills the search with the name of a
+func (m *Model) complete(a Action) {
+	value := a.Name
+	if a.Args != "" {
+		value += " "
+	}
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	m.filter()
+}
+
+// run runs the selected action, actions that need arguments have their name
+// completed so the arguments can be typed
+func (m Model) run() (Model, tea.Cmd) {
+	if len(m.matches) == 0 {
+		return m, nil
+	}
+	a := m.matches[m.selected]
+
+	_, args, ok := withArgs(m.input.Value())
+	if !ok {
+		args = ""
+	}
+	if a.requiresArgs() && args == "" {
+		m.complete(a)
+		return m, nil
+	}
+
+	msg := a.Msg(args)
+	return m.Close(), func() tea.Msg {
+		return RunMsg{Msg: msg}
+	}
+}
+
+// withArgs returns the action that takes arguments whose name starts query,
+// along with the arguments typed after it
+func withArgs(query string) (Action, string, bool) {
+	var (
+		found Action
+		ok    bool
+	)
+	for _, a := range Actions() {
+		prefix := strings.ToLower(a.Name) + " "
+		if a.Args == "" || !strings.HasPrefix(strings.ToLower(query), prefix) {
+			continue
+		}
+		// prefer the longest name when one name starts another
+		if !ok || len(a.Name) > len(found.Name) {
+			found, ok = a, true
+		}
+	}
+	if !ok {
+		return found, "", false
+	}
+	return found, strings.TrimSpace(query[len(found.Name)+1:]), true
+}
+
+func truncate(s string, n int) string {
+	if n <= 3 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
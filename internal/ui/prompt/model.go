@@ -0,0 +1,152 @@
+// Package prompt asks for a line of input in a box over the current tab, such
+// as a new value for a setting or the name of something to confirm deleting
+// it.
+package prompt
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	boxStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("69")).
+			PaddingLeft(1).
+			PaddingRight(1)
+
+	titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
+	bodyStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// Prompt describes what is asked for
+type Prompt struct {
+	Title string
+	// Body is shown between the title and the input, e.g. the current value
+	Body        string
+	Placeholder string
+	// Value is the input the prompt opens with
+	Value string
+	// Validate returns why value can't be submitted, which is shown below the
+	// input. Nil accepts any value.
+	Validate func(value string) error
+	// Msg returns the message that acts on the submitted value, it's
+	// delivered to the tab that opened the prompt
+	Msg func(value string) tea.Msg
+}
+
+// OpenMsg asks the ui to show a prompt over the tab it came from
+type OpenMsg struct {
+	Prompt Prompt
+}
+
+func Open(p Prompt) tea.Cmd {
+	return func() tea.Msg {
+		return OpenMsg{Prompt: p}
+	}
+}
+
+// SubmitMsg is sent when a prompt is submitted, Msg is the message returned
+// by the prompt for the value
+type SubmitMsg struct {
+	Msg tea.Msg
+}
+
+// Model shows a prompt until it's submitted or escaped
+type Model struct {
+	prompt Prompt
+	input  textinput.Model
+	err    error
+	open   bool
+	width  int
+}
+
+func New() Model {
+	input := textinput.New()
+	input.Prompt = "> "
+	return Model{input: input}
+}
+
+// Open shows p, replacing the prompt already open if there is one
+func (m Model) Open(p Prompt) (Model, tea.Cmd) {
+	m.prompt = p
+	m.err = nil
+	m.open = true
+	m.input.Reset()
+	m.input.Placeholder = p.Placeholder
+	m.input.SetValue(p.Value)
+	m.input.CursorEnd()
+	return m, m.input.Focus()
+}
+
+func (m Model) Close() Model {
+	m.open = false
+	m.input.Blur()
+	return m
+}
+
+func (m Model) IsOpen() bool {
+	return m.open
+}
+
+// SetWidth sets the width of the prompt including its border
+func (m Model) SetWidth(width int) Model {
+	m.width = width
+	m.input.Width = width - 8
+	return m
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			return m.Close(), nil
+		case "enter":
+			return m.submit()
+		}
+	}
+
+	m.input, cmd = m.input.Update(msg)
+	if _, ok := msg.(tea.KeyMsg); ok {
+		// the error was about the value before it was edited
+		m.err = nil
+	}
+	return m, cmd
+}
+
+// submit closes the prompt with the value typed, unless it isn't valid
+func (m Model) submit() (Model, tea.Cmd) {
+	value := strings.TrimSpace(m.input.Value())
+	if m.prompt.Validate != nil {
+		if m.err = m.prompt.Validate(value); m.err != nil {
+			return m, nil
+		}
+	}
+
+	msg := m.prompt.Msg(value)
+	return m.Close(), func() tea.Msg {
+		return SubmitMsg{Msg: msg}
+	}
+}
+
+func (m Model) View() string {
+	width := m.width - 4 // border and padding
+	style := lipgloss.NewStyle().Width(width)
+
+	rows := []string{style.Render(titleStyle.Render(m.prompt.Title))}
+	if m.prompt.Body != "" {
+		rows = append(rows, "", style.Render(bodyStyle.Render(m.prompt.Body)))
+	}
+	rows = append(rows, "", m.input.View())
+	if m.err != nil {
+		rows = append(rows, style.Render(errorStyle.Render(m.err.Error())))
+	}
+
+	return boxStyle.Width(m.width - 2).Render(strings.Join(rows, "\n"))
+}
@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"clviewer/internal/cloudwatch/client"
+	"clviewer/internal/ui/ansi"
+)
+
+const statusBarHeight = 1
+
+var (
+	statusStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241"))
+
+	statusErrorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("9"))
+
+	statusRetryStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("214"))
+
+	statusNoticeStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("42"))
+
+	statusSeparator = statusStyle.Render(" │ ")
+)
+
+// statusBarView is the line below the active tab with where it's connected,
+// what is open, how much has loaded and the last error
+func (m *Model) statusBarView() string {
+	t := m.tabs[m.active]
+	loc := t.Locator()
+	status := t.eventPage.LogEvents.Status()
+
+	profile := loc.Profile
+	if profile == "" {
+		profile = "default"
+	}
+	connection := fmt.Sprintf("%s@%s", profile, loc.Region)
+	if client.IsReadOnly(m.cw) {
+		connection += " (read-only)"
+	}
+	fields := []string{
+		connection,
+		fmt.Sprintf("page %d/%d", t.paginator.Page+1, t.paginator.TotalPages),
+	}
+
+	if loc.Group != "" {
+		stream := loc.Stream
+		if stream == "" {
+			stream = "all streams"
+		}
+		fields = append(fields, loc.Group+" / "+stream)
+
+		events := fmt.Sprintf("%d events", status.Events)
+		switch {
+		case status.Loading:
+			events += ", loading"
+		case status.Full && (status.MorePages || status.OlderPages):
+			events += ", limit reached"
+		case status.MorePages && status.OlderPages:
+			events += ", older and newer pages"
+		case status.OlderPages:
+			events += ", older pages"
+		case status.MorePages:
+			events += ", more pages"
+		default:
+			events += ", all loaded"
+		}
+		fields = append(fields, events)
+	}
+
+	if status.FilterPattern != "" {
+		fields = append(fields, "filter: "+status.FilterPattern)
+	}
+	if status.Start != 0 || status.End != 0 {
+		fields = append(fields, m.timeRangeView(status.Start, status.End))
+	}
+	if status.Invocations != "" {
+		fields = append(fields, "invocations: "+status.Invocations)
+	}
+	if status.Following {
+		fields = append(fields, "following")
+	}
+
+	for i, field := range fields {
+		fields[i] = statusStyle.Render(field)
+	}
+	view := strings.Join(fields, statusSeparator)
+
+	if len(m.retries) > 0 {
+		view += statusSeparator + statusRetryStyle.Render(retriesView(m.retries))
+	}
+	if m.notice != "" {
+		view += statusSeparator + statusNoticeStyle.Render(m.notice)
+	}
+	if m.lastError != nil {
+		view += statusSeparator + statusErrorStyle.Render(m.lastError.Error())
+	}
+	return ansi.Truncate(view, m.Width)
+}
+
+// retriesMsg is sent when calls start or stop waiting to be retried
+type retriesMsg struct {
+	limiter *client.Limiter
+	retries []client.Retry
+}
+
+// waitForRetries waits for the calls being retried by l to change
+func waitForRetries(l *client.Limiter) tea.Cmd {
+	return func() tea.Msg {
+		<-l.Changed()
+		return retriesMsg{limiter: l, retries: l.Retrying()}
+	}
+}
+
+// retriesView describes the oldest call being retried and how many others are
+func retriesView(retries []client.Retry) string {
+	r := retries[0]
+	reason := "failed"
+	if r.Throttled() {
+		reason = "throttled"
+	}
+	view := fmt.Sprintf("retrying %s (%s, attempt %d)", r.Operation, reason, r.Attempt+1)
+	if len(retries) > 1 {
+		view += fmt.Sprintf(" +%d more", len(retries)-1)
+	}
+	return view
+}
+
+// timeRangeView formats the start and end of a search, either can be zero
+func (m *Model) timeRangeView(start, end int64) string {
+	from, to := "start", "now"
+	if start != 0 {
+		from = m.timeFormat.Format(start, -1)
+	}
+	if end != 0 {
+		to = m.timeFormat.Format(end, -1)
+	}
+	return fmt.Sprintf("%s to %s", from, to)
+}
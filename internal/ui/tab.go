@@ -0,0 +1,284 @@
+package ui
+
+import (
+	"context"
+	"path"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/paginator"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"clviewer/internal/cloudwatch/client"
+	"clviewer/internal/commands"
+	"clviewer/internal/locator"
+	"clviewer/internal/paging"
+	"clviewer/internal/session"
+	event "clviewer/internal/ui/logevent"
+	"clviewer/internal/ui/logevent/message"
+	"clviewer/internal/ui/logevent/timestamp"
+	group "clviewer/internal/ui/loggroup"
+	stream "clviewer/internal/ui/logstream"
+	"clviewer/internal/ui/mouse"
+	"clviewer/internal/ui/pages"
+)
+
+const tabBarHeight = 1
+
+var (
+	tabStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("08")).
+			PaddingLeft(1).
+			PaddingRight(1)
+
+	activeTabStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("98")).
+			Foreground(lipgloss.Color("230")).
+			PaddingLeft(1).
+			PaddingRight(1)
+)
+
+// tab is a workspace with its own group, stream and search state
+type tab struct {
+	id        int
+	cancel    context.CancelFunc // cancels every fetch of the tab when it's closed
+	paginator paginator.Model
+	eventPage pages.Event
+	groupPage pages.Group
+}
+
+// tabMsg is a message produced by a command of the tab with the given id, so
+// results of a tab's fetches are only delivered to that tab
+type tabMsg struct {
+	id  int
+	msg tea.Msg
+}
+
+func newTab(
+	ctx context.Context,
+	id int,
+	cw client.Client,
+	opts paging.Options,
+	initial locator.Locator,
+) tab {
+	ctx, cancel := context.WithCancel(ctx)
+
+	logGroup := group.New(
+		ctx,
+		cw,
+		"Log Groups",
+		"/aws/lambda",
+		initial.Group,
+	)
+	logStream := stream.New(
+		ctx,
+		cw,
+		opts,
+		"Log Streams",
+		initial.Group,
+	)
+
+	// default to the most recent logstream, unless filtering which
+	// searches every stream in the group
+	if initial.Group != "" && initial.Stream == "" && initial.FilterPattern == "" {
+		logStream = logStream.SelectFirstStream()
+	}
+
+	logEvent := event.New(
+		ctx,
+		cw,
+		opts,
+		timestamp.New("Timestamps"),
+		message.New("Log Messages", "..."),
+		initial,
+	)
+
+	paginator := paginator.New()
+	paginator.SetTotalPages(2)
+
+	return tab{
+		id:        id,
+		cancel:    cancel,
+		paginator: paginator,
+		eventPage: pages.Event{
+			LogEvents:  logEvent,
+			LogStreams: logStream,
+			Focused:    0,
+			Width:      0,
+			Height:     0,
+		},
+		groupPage: pages.Group{
+			Model: logGroup,
+		},
+	}
+}
+
+func (t tab) Init() tea.Cmd {
+	return t.wrap(tea.Batch(t.groupPage.Init(), t.eventPage.Init()))
+}
+
+func (t tab) Update(msg tea.Msg) (tab, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.PrevPage, keys.NextPage) {
+			t.paginator, cmd = t.paginator.Update(msg)
+			return t, cmd
+		}
+		return t.updateCurrentPage(msg)
+	case commands.UpdateViewPortContentMsg, mouse.Msg:
+		return t.updateCurrentPage(msg)
+	case commands.TestPatternMsg:
+		// to see the events it highlights
+		t.paginator.Page = eventPage
+		return t.updatePages(msg)
+	default:
+		return t.updatePages(msg)
+	}
+}
+
+func (t tab) View() string {
+	switch t.currentPage() {
+	case groupPage:
+		return t.groupPage.View()
+	case eventPage:
+		return t.eventPage.View()
+	default:
+		return ""
+	}
+}
+
+// title is the name of the log group open in the tab
+func (t tab) title() string {
+	if g := t.Locator().Group; g != "" {
+		return path.Base(g)
+	}
+	return "new tab"
+}
+
+// tabClient returns the client for the profile and region of a saved tab,
+// which is cw unless the tab was opened with another profile or region. A
+// locator without a region is in the default region of its profile.
+func tabClient(ctx context.Context, cw client.Client, l locator.Locator) (client.Client, error) {
+	if l.Profile == cw.Profile && (l.Region == "" || l.Region == cw.Region) {
+		return cw, nil
+	}
+	return cw.Switch(ctx, l.Profile, l.Region)
+}
+
+// restoreTab opens a tab as it was when state was saved
+func restoreTab(
+	ctx context.Context,
+	id int,
+	cw client.Client,
+	opts paging.Options,
+	state session.Tab,
+) tab {
+	t := newTab(ctx, id, cw, opts, state.Locator)
+	t.paginator.Page = state.Page
+	t.eventPage.Focused = state.Focused
+	t.eventPage.LogEvents = t.eventPage.LogEvents.Restore(event.State{
+		LambdaMode: state.LambdaMode,
+		Offset:     state.Offset,
+		Messages: message.State{
+			Expanded: state.Expanded,
+			YOffset:  state.YOffset,
+			XOffset:  state.XOffset,
+			Wrap:     state.Wrap,
+		},
+	})
+	return t
+}
+
+// State returns the state of the tab to save in the session
+func (t tab) State() session.Tab {
+	events := t.eventPage.LogEvents.State()
+	return session.Tab{
+		Locator:    t.Locator(),
+		Page:       t.currentPage(),
+		Focused:    t.eventPage.Focused,
+		LambdaMode: events.LambdaMode,
+		Offset:     events.Offset,
+		Expanded:   events.Messages.Expanded,
+		YOffset:    events.Messages.YOffset,
+		XOffset:    events.Messages.XOffset,
+		Wrap:       events.Messages.Wrap,
+	}
+}
+
+// Locator returns the location the tab is open at
+func (t tab) Locator() locator.Locator {
+	return t.eventPage.LogEvents.Locator()
+}
+
+// help returns the name of the focused pane and its keys
+func (t tab) help() (string, []help.KeyMap) {
+	if t.currentPage() == groupPage {
+		return t.groupPage.Help()
+	}
+	return t.eventPage.Help()
+}
+
+// settingFilter returns true if keys should be typed into a list filter
+func (t tab) settingFilter() bool {
+	switch t.currentPage() {
+	case groupPage:
+		return t.groupPage.SettingFilter()
+	case eventPage:
+		return t.eventPage.SettingFilter()
+	}
+	return false
+}
+
+func (t tab) currentPage() int {
+	return t.paginator.Page
+}
+
+func (t tab) updateCurrentPage(msg tea.Msg) (tab, tea.Cmd) {
+	var cmd tea.Cmd
+	switch t.currentPage() {
+	case groupPage:
+		t.groupPage, cmd = t.groupPage.Update(msg)
+	case eventPage:
+		t.eventPage, cmd = t.eventPage.Update(msg)
+	}
+	return t, cmd
+}
+
+func (t tab) updatePages(msg tea.Msg) (tab, tea.Cmd) {
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
+
+	t.groupPage, cmd = t.groupPage.Update(msg)
+	cmds = append(cmds, cmd)
+
+	t.eventPage, cmd = t.eventPage.Update(msg)
+	cmds = append(cmds, cmd)
+
+	return t, tea.Batch(cmds...)
+}
+
+// wrap tags the messages produced by cmd with the id of the tab
+func (t tab) wrap(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		// quitting is handled by the program rather than a model
+		if msg == nil || msg == tea.Quit() {
+			return msg
+		}
+
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			cmds := make(tea.BatchMsg, 0, len(batch))
+			for _, cmd := range batch {
+				cmds = append(cmds, t.wrap(cmd))
+			}
+			return cmds
+		}
+		return tabMsg{id: t.id, msg: msg}
+	}
+}
@@ -0,0 +1,219 @@
+package timeformat
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Mode is how timestamps are displayed
+type Mode int
+
+const (
+	// Absolute displays the time in Location using Layout
+	Absolute Mode = iota
+	// Relative displays how long ago the time was, e.g. "3m ago"
+	Relative
+	// Delta displays the time since the previous event, e.g. "+1.204s"
+	Delta
+)
+
+// Layout presets that can be selected by name
+const (
+	Minutes      = "2006-01-02 15:04 MST"
+	Seconds      = "2006-01-02 15:04:05 MST"
+	Milliseconds = "2006-01-02 15:04:05.000 MST"
+)
+
+var presets = map[string]string{
+	"minutes": Minutes,
+	"seconds": Seconds,
+	"millis":  Milliseconds,
+}
+
+// Format describes how timestamps are displayed and which layouts and time
+// zones can be cycled through at runtime
+type Format struct {
+	Mode     Mode
+	Layout   string
+	Location *time.Location
+
+	layouts   []string
+	locations []*time.Location
+}
+
+// New creates a Format for layout and zone.
+//
+// layout is either a preset name ("minutes", "seconds", "millis") or a go
+// time layout, an empty layout defaults to "minutes". zone is "local",
+// "utc" or an IANA time zone name such as "America/Denver", an empty zone
+// defaults to local time.
+func New(layout, zone string) (Format, error) {
+	f := Format{
+		Mode:      Absolute,
+		layouts:   []string{Minutes, Seconds, Milliseconds},
+		locations: []*time.Location{time.Local, time.UTC},
+	}
+
+	switch preset, ok := presets[strings.ToLower(layout)]; {
+	case layout == "":
+		f.Layout = Minutes
+	case ok:
+		f.Layout = preset
+	default:
+		f.Layout = layout
+		f.layouts = append([]string{layout}, f.layouts...)
+	}
+
+	switch strings.ToLower(zone) {
+	case "", "local":
+		f.Location = time.Local
+	case "utc":
+		f.Location = time.UTC
+	default:
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return Format{}, fmt.Errorf("unknown time zone %q: %w", zone, err)
+		}
+		f.Location = loc
+		f.locations = append(f.locations, loc)
+	}
+
+	return f, nil
+}
+
+// Default displays local time to the minute
+func Default() Format {
+	f, _ := New("", "")
+	return f
+}
+
+// Format formats the millisecond timestamp ms. prev is the timestamp of the
+// previous event and is only used in Delta mode, pass a negative prev if
+// there is no previous event.
+func (f Format) Format(ms int64, prev int64) string {
+	t := time.UnixMilli(ms)
+
+	switch f.Mode {
+	case Relative:
+		return relative(time.Since(t))
+	case Delta:
+		if prev >= 0 {
+			return delta(time.Duration(ms-prev) * time.Millisecond)
+		}
+	}
+
+	return t.In(f.location()).Format(f.layout())
+}
+
+// RefreshInterval returns how often timestamps need to be formatted again to
+// stay current, zero if they don't change over time
+func (f Format) RefreshInterval() time.Duration {
+	if f.Mode == Relative {
+		return time.Second
+	}
+	return 0
+}
+
+// Clock formats the millisecond timestamp ms as just the time of day
+func (f Format) Clock(ms int64) string {
+	return time.UnixMilli(ms).In(f.location()).Format("15:04:05")
+}
+
+// NextMode cycles through each absolute layout, then relative and delta mode
+func (f Format) NextMode() Format {
+	if f.Mode != Absolute {
+		f.Mode = (f.Mode + 1) % (Delta + 1)
+		if f.Mode == Absolute {
+			f.Layout = f.layouts[0]
+		}
+		return f
+	}
+
+	for i, layout := range f.layouts {
+		if layout == f.Layout && i < len(f.layouts)-1 {
+			f.Layout = f.layouts[i+1]
+			return f
+		}
+	}
+	f.Mode = Relative
+	return f
+}
+
+// NextLocation cycles between local time, UTC and the zone the Format was
+// created with
+func (f Format) NextLocation() Format {
+	for i, loc := range f.locations {
+		if loc == f.location() {
+			f.Location = f.locations[(i+1)%len(f.locations)]
+			return f
+		}
+	}
+	f.Location = time.Local
+	return f
+}
+
+// String describes the format, e.g. "seconds UTC"
+func (f Format) String() string {
+	var mode string
+	switch f.Mode {
+	case Relative:
+		mode = "relative"
+	case Delta:
+		mode = "delta"
+	default:
+		mode = f.Layout
+		for name, layout := range presets {
+			if layout == f.Layout {
+				mode = name
+			}
+		}
+	}
+	return fmt.Sprintf("%s %s", mode, f.location())
+}
+
+func (f Format) location() *time.Location {
+	if f.Location == nil {
+		return time.Local
+	}
+	return f.Location
+}
+
+func (f Format) layout() string {
+	if f.Layout == "" {
+		return Minutes
+	}
+	return f.Layout
+}
+
+func relative(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "in the future"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+func delta(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%s%dms", sign, d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%s%.3fs", sign, d.Seconds())
+	default:
+		return sign + d.Truncate(time.Millisecond).String()
+	}
+}
@@ -0,0 +1,152 @@
+package timeformat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+
+	tests := []struct {
+		name     string
+		layout   string
+		zone     string
+		want     Format
+		wantErr  bool
+		location *time.Location
+	}{
+		{name: "defaults", want: Format{Layout: Minutes, Location: time.Local}},
+		{name: "preset", layout: "millis", zone: "utc", want: Format{Layout: Milliseconds, Location: time.UTC}},
+		{name: "preset case", layout: "Seconds", zone: "LOCAL", want: Format{Layout: Seconds, Location: time.Local}},
+		{name: "custom layout", layout: "15:04", want: Format{Layout: "15:04", Location: time.Local}},
+		{name: "zone", zone: "America/Denver", want: Format{Layout: Minutes, Location: denver}},
+		{name: "unknown zone", zone: "Mars/Olympus_Mons", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.layout, tt.zone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if f.Mode != Absolute || f.Layout != tt.want.Layout || f.Location.String() != tt.want.Location.String() {
+				t.Errorf("got %v, want %v", f, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		want   []string
+	}{
+		{
+			"preset",
+			"seconds",
+			[]string{"millis UTC", "relative UTC", "delta UTC", "minutes UTC", "seconds UTC"},
+		},
+		{
+			"custom layout",
+			"15:04",
+			[]string{"minutes UTC", "seconds UTC", "millis UTC", "relative UTC", "delta UTC", "15:04 UTC"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.layout, "utc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				f = f.NextMode()
+				if f.String() != want {
+					t.Fatalf("got %q, want %q", f, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNextLocation(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+
+	tests := []struct {
+		name string
+		zone string
+		want []*time.Location
+	}{
+		{"local", "local", []*time.Location{time.UTC, time.Local, time.UTC}},
+		{"utc", "utc", []*time.Location{time.Local, time.UTC}},
+		{"zone", "America/Denver", []*time.Location{time.Local, time.UTC, denver}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New("", tt.zone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				f = f.NextLocation()
+				if f.Location.String() != want.String() {
+					t.Fatalf("got %s, want %s", f.Location, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	ms := time.Date(2023, 3, 1, 12, 30, 45, 123e6, time.UTC).UnixMilli()
+	utc := func(mode Mode, layout string) Format {
+		return Format{Mode: mode, Layout: layout, Location: time.UTC}
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+		ms     int64
+		prev   int64
+		want   string
+	}{
+		{"minutes", utc(Absolute, Minutes), ms, -1, "2023-03-01 12:30 UTC"},
+		{"millis", utc(Absolute, Milliseconds), ms, -1, "2023-03-01 12:30:45.123 UTC"},
+		{"delta", utc(Delta, Minutes), ms, ms - 1204, "+1.204s"},
+		{"delta millis", utc(Delta, Minutes), ms, ms - 20, "+20ms"},
+		{"delta backwards", utc(Delta, Minutes), ms, ms + 90_000, "-1m30s"},
+		{"delta first event", utc(Delta, Minutes), ms, -1, "2023-03-01 12:30 UTC"},
+		{"relative", utc(Relative, Minutes), time.Now().Add(-3 * time.Minute).UnixMilli(), -1, "3m ago"},
+		{"relative days", utc(Relative, Minutes), time.Now().Add(-50 * time.Hour).UnixMilli(), -1, "2d ago"},
+		{"relative future", utc(Relative, Minutes), time.Now().Add(time.Hour).UnixMilli(), -1, "in the future"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.Format(tt.ms, tt.prev); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefreshInterval(t *testing.T) {
+	for _, mode := range []Mode{Absolute, Relative, Delta} {
+		f := Format{Mode: mode}
+		if got := f.RefreshInterval(); (got > 0) != (mode == Relative) {
+			t.Errorf("mode %d refreshes every %s, want only relative times refreshed", mode, got)
+		}
+	}
+}
@@ -0,0 +1,570 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+
+	"clviewer/internal/cloudwatch/client"
+	"clviewer/internal/cloudwatch/fake"
+	"clviewer/internal/locator"
+	"clviewer/internal/paging"
+	"clviewer/internal/session"
+	"clviewer/internal/ui/logevent"
+	"clviewer/internal/ui/timeformat"
+)
+
+// start is the time of the first event of every stream
+var start = time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC).UnixMilli()
+
+// backend returns a fake with a couple of log groups, the api stream has n
+// events a second apart alternating between json and text messages
+func backend(n int) *fake.Backend {
+	var events []fake.Event
+	for i := 0; i < n; i++ {
+		message := fmt.Sprintf(`{"level":"info","request":%d,"path":"/orders"}`, i)
+		if i%2 == 1 {
+			message = fmt.Sprintf("handled request %d\n  in %dms", i, 10+i)
+		}
+		events = append(events, fake.Event{
+			Timestamp: start + int64(i)*1000,
+			Message:   message,
+		})
+	}
+
+	return fake.New(
+		fake.Group{
+			Name: "/aws/lambda/orders",
+			Streams: []fake.Stream{
+				{Name: "api", Events: events},
+				{Name: "worker", Events: []fake.Event{{Timestamp: start - 60000, Message: "started"}}},
+			},
+			MetricFilters: []types.MetricFilter{
+				{
+					FilterName:    aws.String("info"),
+					FilterPattern: aws.String("info"),
+					MetricTransformations: []types.MetricTransformation{{
+						MetricNamespace: aws.String("Orders"),
+						MetricName:      aws.String("Info"),
+						MetricValue:     aws.String("1"),
+					}},
+				},
+				{
+					FilterName:    aws.String("handled"),
+					FilterPattern: aws.String("handled request"),
+					MetricTransformations: []types.MetricTransformation{{
+						MetricNamespace: aws.String("Orders"),
+						MetricName:      aws.String("Handled"),
+						MetricValue:     aws.String("1"),
+					}},
+				},
+			},
+			SubscriptionFilters: []types.SubscriptionFilter{{
+				FilterName:     aws.String("archive"),
+				DestinationArn: aws.String("arn:aws:firehose:fake:000000000000:deliverystream/orders"),
+			}},
+		},
+		fake.Group{Name: "/aws/lambda/payments"},
+	)
+}
+
+// openStream opens the api stream of /aws/lambda/orders and focuses the events
+func openStream(h *harness) {
+	h.keys("enter", "l", "enter", "tab")
+}
+
+func TestGroupList(t *testing.T) {
+	h := newHarness(t, backend(0), session.State{}, 100, 20)
+	h.golden("groups")
+
+	h.keys("j")
+	h.golden("second-group")
+}
+
+func TestOpenStream(t *testing.T) {
+	h := newHarness(t, backend(8), session.State{}, 120, 30)
+
+	h.keys("enter", "l")
+	h.golden("streams")
+
+	h.keys("enter", "tab")
+	h.golden("events")
+}
+
+func TestCursorSync(t *testing.T) {
+	h := newHarness(t, backend(8), session.State{}, 120, 30)
+	openStream(h)
+
+	steps := []struct {
+		key  string
+		want int
+	}{
+		{"j", 1},
+		{"j", 2},
+		{"j", 3},
+		{"k", 2},
+		{"down", 3},
+		{"up", 2},
+	}
+	for _, step := range steps {
+		h.keys(step.key)
+
+		events := h.m.tabs[0].eventPage.LogEvents
+		if got := events.Timestamp.List.Index(); got != step.want {
+			t.Errorf("after %q the timestamp list is at %d, want %d", step.key, got, step.want)
+		}
+		if got := events.Messages.Selected(); got != step.want {
+			t.Errorf("after %q the message viewport is at %d, want %d", step.key, got, step.want)
+		}
+	}
+	h.golden("third-event")
+}
+
+func TestCollapseAll(t *testing.T) {
+	h := newHarness(t, backend(4), session.State{}, 120, 40)
+	openStream(h)
+
+	h.keys("C")
+	h.golden("expanded")
+
+	h.keys("C")
+	h.golden("collapsed")
+
+	// toggling all expands every event while any are collapsed
+	h.keys("j", " ")
+	h.golden("one-expanded")
+	h.keys("C")
+	h.golden("collapsed-again")
+}
+
+func TestPagination(t *testing.T) {
+	cw := backend(25)
+	cw.PageSize = 10
+	h := newHarness(t, cw, session.State{}, 120, 30)
+	openStream(h)
+
+	steps := []struct {
+		name      string
+		events    int
+		morePages bool
+	}{
+		{"first page", 10, true},
+		{"second page", 20, true},
+		{"last page", 25, true},
+		// the end of a stream is only found by reading an empty page
+		{"end of stream", 25, false},
+	}
+	for i, step := range steps {
+		if i > 0 {
+			h.keys("L")
+		}
+		status := h.m.tabs[0].eventPage.LogEvents.Status()
+		if status.Events != step.events || status.MorePages != step.morePages {
+			t.Errorf(
+				"%s: %d events, more pages %t, want %d events, more pages %t",
+				step.name, status.Events, status.MorePages, step.events, step.morePages,
+			)
+		}
+	}
+	h.golden("all-loaded")
+
+	if got := cw.Calls(fake.GetLogEvents); got != len(steps) {
+		t.Errorf("GetLogEvents called %d times, want %d", got, len(steps))
+	}
+}
+
+func TestLoading(t *testing.T) {
+	cw := backend(4)
+	h := newHarness(t, cw, session.State{}, 120, 30)
+	h.keys("enter", "l")
+
+	// the model settles while the events are still being fetched
+	cw.Latency = 4 * h.idle
+	h.keys("enter")
+	h.golden("loading")
+
+	h.idle = 8 * h.idle
+	h.settle()
+	h.golden("loaded")
+}
+
+// TestReplay opens a redacted recording of the fake backend, which is made
+// again when run with -update
+func TestReplay(t *testing.T) {
+	path := filepath.Join("testdata", t.Name(), "recording.jsonl")
+	if *update {
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorded := client.Client{API: backend(6)}.Record(client.NewRecorder(f, true))
+		openStream(newHarness(t, recorded, session.State{}, 120, 30))
+		f.Close()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	replayed, err := client.NewReplay(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := newHarness(t, replayed, session.State{}, 120, 30)
+	openStream(h)
+	h.golden("events")
+}
+
+func TestThrottling(t *testing.T) {
+	cw := backend(4)
+	cw.Throttle(fake.GetLogEvents, 3)
+	limits := client.Limits{MaxAttempts: 2, BaseDelay: 4 * defaultIdle, MaxDelay: time.Minute}
+	h := newHarness(t, client.Client{API: cw}.Limit(client.NewLimiter(limits)), session.State{}, 120, 30)
+	status := func() logevent.Status { return h.m.tabs[0].eventPage.LogEvents.Status() }
+
+	// the retry waits at least twice as long as the harness
+	openStream(h)
+	h.golden("retrying")
+
+	h.until(10*limits.BaseDelay, func() bool { return h.m.lastError != nil })
+	h.golden("gave-up")
+
+	// the last throttled attempt is retried, and the one after succeeds
+	h.keys("L")
+	h.until(10*limits.BaseDelay, func() bool { return status().Events > 0 })
+	h.golden("loaded")
+
+	if got := cw.Calls(fake.GetLogEvents); got != 4 {
+		t.Errorf("GetLogEvents called %d times, want 4", got)
+	}
+}
+
+// inflight counts the calls to GetLogEvents that haven't returned
+type inflight struct {
+	client.API
+	calls int32
+}
+
+func (f *inflight) GetLogEvents(
+	ctx context.Context,
+	in *cloudwatchlogs.GetLogEventsInput,
+	optFns ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	atomic.AddInt32(&f.calls, 1)
+	defer atomic.AddInt32(&f.calls, -1)
+	return f.API.GetLogEvents(ctx, in, optFns...)
+}
+
+func (f *inflight) is(n int32) func() bool {
+	return func() bool { return atomic.LoadInt32(&f.calls) == n }
+}
+
+func TestCancel(t *testing.T) {
+	cw := backend(4)
+	api := &inflight{API: cw}
+	h := newHarness(t, api, session.State{}, 120, 30)
+	h.keys("enter", "l")
+
+	// every page takes longer than the test
+	cw.Latency = time.Minute
+	h.keys("enter")
+	h.until(time.Second, api.is(1))
+
+	// opening another stream cancels the page of the first
+	h.keys("down", "enter")
+	h.until(time.Second, api.is(1))
+	if got := cw.Calls(fake.GetLogEvents); got != 2 {
+		t.Errorf("GetLogEvents called %d times, want 2", got)
+	}
+
+	h.keys("ctrl+x")
+	h.until(time.Second, api.is(0))
+	h.golden("cancelled")
+
+	// quitting cancels the page being fetched
+	h.keys("tab", "L")
+	h.until(time.Second, api.is(1))
+	h.keys("q")
+	h.until(time.Second, api.is(0))
+}
+
+func TestRestoreOtherRegion(t *testing.T) {
+	// a replay can't switch region, so the tab can't be restored
+	replayed, err := client.NewReplay(strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := session.State{
+		Tabs: []session.Tab{
+			{Locator: locator.Locator{Group: "elsewhere", Region: "eu-west-1"}},
+			{Locator: locator.Locator{Group: "here", Region: client.ReplayRegion}},
+		},
+		Active: 1,
+	}
+
+	m := New(context.Background(), replayed, state, timeformat.Default(), paging.Options{}, "")
+	if len(m.tabs) != 1 || m.tabs[0].Locator().Group != "here" || m.active != 0 {
+		t.Fatalf("got %d tabs with %d active, want only the tab in the replay's region", len(m.tabs), m.active)
+	}
+	if m.lastError == nil || !strings.Contains(m.lastError.Error(), "elsewhere") {
+		t.Errorf("got error %v, want the tab that wasn't restored named", m.lastError)
+	}
+}
+
+func TestTail(t *testing.T) {
+	cw := backend(25)
+	cw.PageSize = 10
+	state := session.State{Tabs: []session.Tab{{
+		Locator: locator.Locator{Group: "/aws/lambda/orders", Stream: "api", Tail: true},
+		Page:    1,
+		Focused: 1,
+	}}}
+	h := newHarness(t, cw, state, 120, 30)
+	status := func() logevent.Status { return h.m.tabs[0].eventPage.LogEvents.Status() }
+
+	// the newest page is selected at its last event
+	h.golden("tail")
+
+	// moving above the first event loads the page before it
+	for i := 0; i < 10; i++ {
+		h.keys("up")
+	}
+	if got := status().Events; got != 20 {
+		t.Errorf("got %d events after loading older, want 20", got)
+	}
+	h.golden("older")
+
+	// gg reads the stream again from its start
+	h.keys("g", "g")
+	if s := status(); s.OlderPages || !s.MorePages || s.Events != 10 {
+		t.Errorf("got status %+v after gg, want the first page", s)
+	}
+	h.golden("head")
+
+	// G reads it again from its end
+	h.keys("G")
+	if s := status(); !s.OlderPages || s.MorePages || s.Events != 10 {
+		t.Errorf("got status %+v after G, want the last page", s)
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	cw := backend(25)
+	cw.PageSize = 10
+	opts := paging.Options{PageSize: 10, Prefetch: 3, MaxEvents: 20}
+	h := newPagingHarness(t, cw, session.State{}, opts, 120, 30)
+	status := func() logevent.Status { return h.m.tabs[0].eventPage.LogEvents.Status() }
+
+	openStream(h)
+	if got := status().Events; got != 10 {
+		t.Fatalf("got %d events once opened, want 10", got)
+	}
+
+	// the next page is fetched as the cursor nears the last event
+	for i := 0; i < 7; i++ {
+		h.keys("down")
+	}
+	if got := status().Events; got != 20 {
+		t.Fatalf("got %d events near the end of the first page, want 20", got)
+	}
+
+	// but not once the tab holds the most events allowed
+	for i := 0; i < 10; i++ {
+		h.keys("down")
+	}
+	if s := status(); s.Events != 20 || !s.Full {
+		t.Errorf("got status %+v past the limit, want 20 events and full", s)
+	}
+	h.golden("full")
+}
+
+func TestEndOfStream(t *testing.T) {
+	h := newPagingHarness(t, backend(4), session.State{}, paging.Default(), 120, 30)
+
+	// the page after the last is fetched straight away, as the cursor is
+	// near the end, which finds the end of the stream
+	openStream(h)
+	if s := h.m.tabs[0].eventPage.LogEvents.Status(); s.MorePages {
+		t.Errorf("got status %+v, want every page loaded", s)
+	}
+	h.golden("end")
+}
+
+func TestFollowBuffer(t *testing.T) {
+	cw := backend(25)
+	cw.PageSize = 10
+	opts := paging.Options{PageSize: 10, FollowBuffer: 12}
+	h := newPagingHarness(t, cw, session.State{}, opts, 120, 30)
+	status := func() logevent.Status { return h.m.tabs[0].eventPage.LogEvents.Status() }
+
+	// following reads the stream again from its end
+	openStream(h)
+	h.keys("T")
+	if s := status(); s.Events != 10 || !s.OlderPages {
+		t.Fatalf("got status %+v once following, want the newest 10 events", s)
+	}
+
+	// the oldest events are evicted to make room for new ones
+	for i := 25; i < 30; i++ {
+		cw.Append("/aws/lambda/orders", "api", fake.Event{
+			Timestamp: start + int64(i)*1000,
+			Message:   fmt.Sprintf("handled request %d", i),
+		})
+	}
+	h.until(10*time.Second, func() bool { return status().Events == 12 })
+	if s := status(); s.OlderPages {
+		t.Errorf("got status %+v, want no older pages once events are evicted", s)
+	}
+	h.golden("evicted")
+}
+
+func TestRetention(t *testing.T) {
+	cw := backend(0)
+	h := newHarness(t, cw, session.State{}, 100, 20)
+
+	h.keys("r")
+	h.golden("prompt")
+
+	// the prompt starts with the current retention
+	h.keys("backspace", "backspace", "backspace", "backspace", "backspace", "31", "enter")
+	h.golden("invalid")
+
+	h.keys("backspace", "0", "enter")
+	h.golden("confirm")
+	if cw.Calls(fake.PutRetentionPolicy) != 0 {
+		t.Fatal("retention changed before the group's name was typed")
+	}
+
+	h.keys("/aws/lambda/orders", "enter")
+	if g, _ := cw.Group("/aws/lambda/orders"); g.Retention != 30 {
+		t.Fatalf("got retention %d, want 30 days", g.Retention)
+	}
+	h.golden("changed")
+}
+
+func TestTags(t *testing.T) {
+	cw := backend(0)
+	h := newHarness(t, cw, session.State{}, 100, 20)
+
+	h.keys("t", "env=prod team=orders", "enter")
+	if cw.Calls(fake.TagResource) != 0 {
+		t.Fatal("tags changed before the group's name was typed")
+	}
+	h.keys("/aws/lambda/orders", "enter")
+	h.keys("t")
+	h.golden("tags")
+
+	h.keys("-team", "enter")
+	h.golden("confirm")
+	h.keys("/aws/lambda/orders", "enter")
+	if g, _ := cw.Group("/aws/lambda/orders"); len(g.Tags) != 1 || g.Tags["env"] != "prod" {
+		t.Fatalf("got tags %v, want env=prod", g.Tags)
+	}
+}
+
+func TestDeleteGroup(t *testing.T) {
+	cw := backend(0)
+	h := newHarness(t, cw, session.State{}, 100, 20)
+
+	h.keys("j", "D", "/aws/lambda/pay", "enter")
+	h.golden("mismatch")
+	if cw.Calls(fake.DeleteLogGroup) != 0 {
+		t.Fatal("group deleted before its name was typed")
+	}
+
+	h.keys("ments", "enter")
+	if _, ok := cw.Group("/aws/lambda/payments"); ok {
+		t.Fatal("group wasn't deleted")
+	}
+	h.golden("deleted")
+}
+
+func TestDeleteStream(t *testing.T) {
+	cw := backend(4)
+	h := newHarness(t, cw, session.State{}, 120, 30)
+
+	// escaping the prompt leaves the stream alone
+	h.keys("enter", "l", "j", "D", "esc")
+	h.keys("D", "worker", "enter")
+	if g, _ := cw.Group("/aws/lambda/orders"); len(g.Streams) != 1 || cw.Calls(fake.DeleteLogStream) != 1 {
+		t.Fatalf("got streams %v, want only api", g.Streams)
+	}
+	h.golden("deleted")
+}
+
+func TestReadOnly(t *testing.T) {
+	cw := backend(0)
+	h := newHarness(t, client.Client{API: cw, Region: "test"}.ReadOnly(), session.State{}, 100, 20)
+
+	h.keys("D")
+	h.golden("refused")
+
+	// the retention is shown rather than changed
+	h.keys("r")
+	h.golden("retention")
+	if cw.Calls(fake.DeleteLogGroup) != 0 || cw.Calls(fake.PutRetentionPolicy) != 0 {
+		t.Error("read-only client changed a group")
+	}
+}
+
+func TestFilters(t *testing.T) {
+	h := newHarness(t, backend(8), session.State{}, 120, 30)
+	openStream(h)
+
+	h.keys("h", "f")
+	h.golden("panel")
+
+	h.keys("j", "enter")
+	h.golden("highlighted")
+
+	h.keys("h", "j", "enter")
+	if !strings.Contains(h.view(), "8 of 8 loaded events match") {
+		t.Error("the empty pattern of the subscription filter doesn't match every event")
+	}
+}
+
+func TestFilterUnparsed(t *testing.T) {
+	b := backend(8)
+	h := newHarness(t, b, session.State{}, 120, 30)
+	openStream(h)
+
+	h.keys(":", "filter { $.request >= }", "enter")
+	if calls := b.Calls(fake.FilterLogEvents); calls == 0 {
+		t.Error("the pattern the viewer couldn't parse wasn't sent to CloudWatch")
+	}
+	if h.m.lastError == nil || !strings.Contains(h.m.lastError.Error(), "column 16") {
+		t.Errorf("got error %v, want CloudWatch's error shown", h.m.lastError)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	b := backend(8)
+	h := newHarness(t, b, session.State{}, 120, 30)
+	openStream(h)
+
+	h.keys(":", "highlight { $.request >= 4 }", "enter")
+	h.golden("json")
+	if calls := b.Calls(fake.TestMetricFilter); calls != 0 {
+		t.Errorf("called TestMetricFilter %d times, want the pattern matched without calling aws", calls)
+	}
+
+	// a pattern the viewer can't parse is left to CloudWatch to judge
+	h.keys(":", "highlight { $.request >= }", "enter")
+	if calls := b.Calls(fake.TestMetricFilter); calls == 0 {
+		t.Error("the pattern the viewer couldn't parse wasn't tested with CloudWatch")
+	}
+	if h.m.lastError == nil || !strings.Contains(h.m.lastError.Error(), "column 16") {
+		t.Errorf("got error %v, want the invalid pattern rejected", h.m.lastError)
+	}
+}
@@ -2,37 +2,313 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"clviewer/internal/cloudwatch/client"
+	"clviewer/internal/locator"
+	"clviewer/internal/paging"
+	"clviewer/internal/session"
 	"clviewer/internal/ui"
+	"clviewer/internal/ui/timeformat"
 )
 
 func main() {
 	ctx := context.Background()
 
-	f, err := tea.LogToFile("debug.log", "debug")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [log group | %s://locator]\n", os.Args[0], locator.Scheme)
+		flag.PrintDefaults()
+	}
+
+	timeLayout := flag.String(
+		"time-format",
+		"minutes",
+		"timestamp layout: minutes, seconds, millis or a go time layout",
+	)
+	timeZone := flag.String(
+		"tz",
+		"local",
+		"time zone timestamps are displayed in: local, utc or an IANA zone name",
+	)
+
+	restoreTabs := flag.Bool(
+		"restore-tabs",
+		false,
+		"reopen the tabs that were open when the viewer was last closed",
+	)
+	resume := flag.Bool(
+		"resume",
+		false,
+		"resume the last session, restoring the tabs along with the selected, expanded and scrolled to events",
+	)
+	sessionPath := flag.String(
+		"session",
+		defaultSessionPath(),
+		"file the session is saved to, empty to not save it",
+	)
+
+	record := flag.String(
+		"record",
+		"",
+		"record every request to cloudwatch and its response to this file, for -replay",
+	)
+	replay := flag.String(
+		"replay",
+		"",
+		"serve the responses recorded by -record from this file instead of calling cloudwatch",
+	)
+	redact := flag.Bool(
+		"redact",
+		false,
+		"mask the contents of log messages written by -record",
+	)
+	readOnly := flag.Bool(
+		"read-only",
+		false,
+		"refuse to change retention or tags, or delete log groups and streams, e.g. for production profiles",
+	)
+
+	limits := client.DefaultLimits()
+	flag.Var(
+		limits.Rates,
+		"rate",
+		"requests per second allowed for each operation, e.g. GetLogEvents=50,FilterLogEvents=10 for raised quotas",
+	)
+	flag.IntVar(
+		&limits.MaxAttempts,
+		"attempts",
+		limits.MaxAttempts,
+		"attempts made at each request that's throttled or fails with a transient error",
+	)
+
+	pages := paging.Default()
+	flag.IntVar(&pages.PageSize, "page-size", pages.PageSize, "events fetched at a time")
+	flag.IntVar(&pages.StreamPageSize, "stream-page-size", pages.StreamPageSize, "log streams fetched at a time")
+	flag.IntVar(
+		&pages.Prefetch,
+		"prefetch",
+		pages.Prefetch,
+		"fetch the next page when the cursor is this close to the end of a list, 0 to only fetch pages with L",
+	)
+	flag.IntVar(
+		&pages.MaxEvents,
+		"max-events",
+		pages.MaxEvents,
+		"most events held by each tab, 0 for no limit",
+	)
+	flag.IntVar(
+		&pages.FollowBuffer,
+		"follow-buffer",
+		pages.FollowBuffer,
+		"most events held by each tab while following, the oldest are dropped as new ones arrive",
+	)
+
+	var flags struct {
+		locator.Locator
+		at, start, end string
+	}
+	flag.StringVar(&flags.Profile, "profile", "", "aws profile")
+	flag.StringVar(&flags.Region, "region", "", "aws region")
+	flag.StringVar(&flags.Group, "group", "", "log group")
+	flag.StringVar(&flags.Stream, "stream", "", "log stream")
+	flag.StringVar(&flags.FilterPattern, "filter", "", "filter pattern, searches every stream when no stream is given")
+	flag.StringVar(&flags.at, "at", "", "select the event at this time")
+	flag.StringVar(&flags.start, "start", "", "only show events after this time")
+	flag.StringVar(&flags.end, "end", "", "only show events before this time")
+	flag.BoolVar(&flags.Tail, "tail", false, "start at the newest events of the stream")
+	flag.Parse()
+
+	if *record != "" && *replay != "" {
+		fmt.Println("fatal: -record and -replay can't be used together")
+		os.Exit(1)
+	}
+	if *replay != "" {
+		// a replay shouldn't replace the session of the real log groups
+		*sessionPath = ""
+	}
+
+	timeFormat, err := timeformat.New(*timeLayout, *timeZone)
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+	if err := pages.Validate(); err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+
+	initial, err := initialLocator(flag.Arg(0), flags.Locator, flags.at, flags.start, flags.end)
 	if err != nil {
 		fmt.Println("fatal:", err)
 		os.Exit(1)
 	}
-	defer f.Close()
 
-	group := ""
-	if len(os.Args) > 1 {
-		group = os.Args[1]
+	state, err := initialState(initial, *sessionPath, *restoreTabs, *resume)
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
 	}
 
+	cw, closeClient, err := newClient(ctx, initial, *record, *replay, *redact)
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+	defer closeClient()
+	// limited outside of any recording, so retries are recorded and replayed
+	cw = cw.Limit(client.NewLimiter(limits))
+	if *readOnly {
+		cw = cw.ReadOnly()
+	}
+
+	f, err := tea.LogToFile("debug.log", "debug")
+	if err != nil {
+		fmt.Println("fatal:", err)
+		// os.Exit skips the deferred close, which ends the recording
+		closeClient()
+		os.Exit(1)
+	}
+	defer f.Close()
+
 	p := tea.NewProgram(
-		ui.New(ctx, group),
+		ui.New(ctx, cw, state, timeFormat, pages, *sessionPath),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
 
-	if _, err := p.Run(); err != nil {
+	model, err := p.Run()
+	if err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
+		f.Close()
+		closeClient()
 		os.Exit(1)
 	}
+
+	if *sessionPath != "" {
+		if err := session.Save(*sessionPath, model.(*ui.Model).State()); err != nil {
+			fmt.Println("failed to save session:", err)
+		}
+	}
+}
+
+// initialLocator combines the locator or log group given as an argument with
+// the locator flags, flags take precedence
+func initialLocator(arg string, flags locator.Locator, at, start, end string) (locator.Locator, error) {
+	var initial locator.Locator
+	switch {
+	case strings.HasPrefix(arg, locator.Scheme+"://"):
+		var err error
+		if initial, err = locator.Parse(arg); err != nil {
+			return initial, err
+		}
+	default:
+		initial.Group = arg
+	}
+
+	times := []struct {
+		value string
+		dst   *int64
+	}{
+		{at, &flags.Timestamp},
+		{start, &flags.Start},
+		{end, &flags.End},
+	}
+	for _, t := range times {
+		if t.value == "" {
+			continue
+		}
+		ms, err := locator.ParseTime(t.value, time.Now())
+		if err != nil {
+			return initial, err
+		}
+		*t.dst = ms
+	}
+
+	return initial.Merge(flags), nil
+}
+
+// initialState returns the tabs to open, the initial locator is opened first
+// followed by the tabs of the last session when restoring or resuming. The
+// layout of the panes is always kept from the last session.
+func initialState(initial locator.Locator, path string, restoreTabs, resume bool) (session.State, error) {
+	var state session.State
+
+	last, err := session.Load(path)
+	if !restoreTabs && !resume {
+		// a session that can't be read only loses its layout
+		state.Layout = last.Layout
+		state.Tabs = []session.Tab{{Locator: initial}}
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("loading session: %w", err)
+	}
+	state.Layout = last.Layout
+
+	if initial.Group != "" {
+		state.Tabs = append(state.Tabs, session.Tab{Locator: initial})
+	}
+
+	for i, t := range last.Tabs {
+		// tabs that never had a log group open aren't worth restoring
+		if t.Locator.Group == "" {
+			continue
+		}
+		if resume && i == last.Active && initial.Group == "" {
+			state.Active = len(state.Tabs)
+		}
+		if !resume {
+			t = session.Tab{Locator: t.Locator}
+		}
+		state.Tabs = append(state.Tabs, t)
+	}
+	return state, nil
+}
+
+// newClient creates the client for the initial profile and region, recording
+// its calls to record, or replaying the calls recorded in replay instead. The
+// returned func closes the recording.
+func newClient(
+	ctx context.Context,
+	initial locator.Locator,
+	record, replay string,
+	redact bool,
+) (client.Client, func(), error) {
+	noop := func() {}
+
+	if replay != "" {
+		f, err := os.Open(replay)
+		if err != nil {
+			return client.Client{}, noop, err
+		}
+		defer f.Close()
+
+		cw, err := client.NewReplay(f)
+		return cw, noop, err
+	}
+
+	cw, err := client.New(ctx, initial.Profile, initial.Region)
+	if err != nil || record == "" {
+		return cw, noop, err
+	}
+
+	f, err := os.Create(record)
+	if err != nil {
+		return cw, noop, err
+	}
+	return cw.Record(client.NewRecorder(f, redact)), func() { f.Close() }, nil
+}
+
+func defaultSessionPath() string {
+	path, err := session.DefaultPath()
+	if err != nil {
+		return ""
+	}
+	return path
 }